@@ -0,0 +1,244 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package ast
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// Clone 复制节点 n，复制结果不挂在任何树上。
+//
+// 所有字段都会被复制（切片、映射和内联属性列表都是新建的副本），deep 为 true 时递归复制所有子节点，为 false 时仅复制 n 本身。
+// 脚注引用 FootnotesRefs 和子节点列表 Children 中引用的节点如果也被复制则指向副本，否则仍然指向原节点。
+func (n *Node) Clone(deep bool) (ret *Node) {
+	clones := map[*Node]*Node{}
+	ret = n.clone(deep, clones)
+	for _, clone := range clones {
+		for i, ref := range clone.FootnotesRefs {
+			if c := clones[ref]; nil != c {
+				clone.FootnotesRefs[i] = c
+			}
+		}
+		for i, child := range clone.Children {
+			if c := clones[child]; nil != c {
+				clone.Children[i] = c
+			}
+		}
+	}
+	return
+}
+
+// CloneWithNewIDs 复制节点 n，并为复制结果中所有带有 ID 的节点生成新的 ID，内联属性列表中的 id 属性也会随之更新。
+func (n *Node) CloneWithNewIDs(deep bool) (ret *Node) {
+	ret = n.Clone(deep)
+	ids := map[string]string{}
+	Walk(ret, func(c *Node, entering bool) WalkStatus {
+		if !entering || "" == c.ID {
+			return WalkContinue
+		}
+		id := ids[c.ID]
+		if "" == id {
+			id = NewNodeID()
+			ids[c.ID] = id
+		}
+		c.ID = id
+		for _, kv := range c.KramdownIAL {
+			if "id" == kv[0] {
+				kv[1] = id
+			}
+		}
+		return WalkContinue
+	})
+	Walk(ret, func(c *Node, entering bool) WalkStatus {
+		if entering && (NodeKramdownBlockIAL == c.Type || NodeKramdownSpanIAL == c.Type) {
+			for old, id := range ids {
+				c.Tokens = bytes.ReplaceAll(c.Tokens, []byte("id=\""+old+"\""), []byte("id=\""+id+"\""))
+			}
+		}
+		return WalkContinue
+	})
+	return
+}
+
+func (n *Node) clone(deep bool, clones map[*Node]*Node) (ret *Node) {
+	ret = &Node{}
+	*ret = *n
+	clones[n] = ret
+	ret.Parent, ret.Previous, ret.Next, ret.FirstChild, ret.LastChild = nil, nil, nil, nil, nil
+	ret.Tokens = cloneBytes(n.Tokens)
+	ret.CodeBlockOpenFence = cloneBytes(n.CodeBlockOpenFence)
+	ret.CodeBlockInfo = cloneBytes(n.CodeBlockInfo)
+	ret.CodeBlockCloseFence = cloneBytes(n.CodeBlockCloseFence)
+	ret.LinkRefLabel = cloneBytes(n.LinkRefLabel)
+	ret.FootnotesRefLabel = cloneBytes(n.FootnotesRefLabel)
+	ret.HtmlEntityTokens = cloneBytes(n.HtmlEntityTokens)
+	if nil != n.ListData {
+		listData := *n.ListData
+		listData.Marker = cloneBytes(n.ListData.Marker)
+		ret.ListData = &listData
+	}
+	if nil != n.TableAligns {
+		ret.TableAligns = append([]int{}, n.TableAligns...)
+	}
+	if nil != n.FootnotesRefs {
+		ret.FootnotesRefs = append([]*Node{}, n.FootnotesRefs...)
+	}
+	if nil != n.Children {
+		ret.Children = append([]*Node{}, n.Children...)
+	}
+	if nil != n.KramdownIAL {
+		ret.KramdownIAL = make([][]string, len(n.KramdownIAL))
+		for i, kv := range n.KramdownIAL {
+			ret.KramdownIAL[i] = append([]string{}, kv...)
+		}
+	}
+	if nil != n.Properties {
+		ret.Properties = make(map[string]string, len(n.Properties))
+		for k, v := range n.Properties {
+			ret.Properties[k] = v
+		}
+	}
+
+	if deep {
+		for c := n.FirstChild; nil != c; c = c.Next {
+			ret.AppendChild(c.clone(true, clones))
+		}
+	}
+	return
+}
+
+func cloneBytes(b []byte) []byte {
+	if nil == b {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// EqualOptions 描述了节点比较选项。
+type EqualOptions struct {
+	IgnoreMarkers bool // 是否忽略标记符，忽略时标记符节点（参考 IsMarker）和列表标识符不参与比较，比如 *foo* 和 _foo_ 相等
+	IgnoreIDs     bool // 是否忽略 ID，忽略时节点 ID 和内联属性列表中的 id 属性不参与比较
+}
+
+// Equal 判断以 n 和 other 为根的两棵子树在语义上是否相等，opts 为 nil 时不忽略任何内容。
+//
+// 比较的内容包括节点类型、Tokens、内联属性列表、属性以及标题级别、列表类型、任务列表项勾选状态、代码块信息、链接类型和表格对齐方式等语义相关的字段，
+// 不比较解析过程标识、宽度等和语义无关的字段。
+func (n *Node) Equal(other *Node, opts *EqualOptions) bool {
+	if nil == opts {
+		opts = &EqualOptions{}
+	}
+	if nil == n || nil == other {
+		return n == other
+	}
+	if !n.equal(other, opts) {
+		return false
+	}
+
+	a, b := equalSibling(n.FirstChild, opts), equalSibling(other.FirstChild, opts)
+	for nil != a && nil != b {
+		if !a.Equal(b, opts) {
+			return false
+		}
+		a, b = equalSibling(a.Next, opts), equalSibling(b.Next, opts)
+	}
+	return nil == a && nil == b
+}
+
+// equalSibling 返回从 c 开始（包括 c）第一个参与比较的兄弟节点。
+func equalSibling(c *Node, opts *EqualOptions) *Node {
+	for nil != c && opts.IgnoreMarkers && c.IsMarker() {
+		c = c.Next
+	}
+	return c
+}
+
+var ialIDAttr = regexp.MustCompile(`\s*\bid="[^"]*"`)
+
+// equal 比较节点 n 和 other 本身（不包括子节点）。
+func (n *Node) equal(other *Node, opts *EqualOptions) bool {
+	if n.Type != other.Type {
+		return false
+	}
+	if !opts.IgnoreIDs && n.ID != other.ID {
+		return false
+	}
+
+	tokens, otherTokens := n.Tokens, other.Tokens
+	if opts.IgnoreIDs && (NodeKramdownBlockIAL == n.Type || NodeKramdownSpanIAL == n.Type) {
+		tokens, otherTokens = ialIDAttr.ReplaceAll(tokens, nil), ialIDAttr.ReplaceAll(otherTokens, nil)
+	}
+	if opts.IgnoreMarkers && (NodeList == n.Type || NodeListItem == n.Type) {
+		// 列表和列表项的 Tokens 为列表标识符
+		tokens, otherTokens = nil, nil
+	}
+	if !bytes.Equal(tokens, otherTokens) {
+		return false
+	}
+
+	if n.HeadingLevel != other.HeadingLevel || n.TaskListItemChecked != other.TaskListItemChecked || n.LinkType != other.LinkType ||
+		!bytes.Equal(n.CodeBlockInfo, other.CodeBlockInfo) || !bytes.Equal(n.LinkRefLabel, other.LinkRefLabel) || !bytes.Equal(n.FootnotesRefLabel, other.FootnotesRefLabel) {
+		return false
+	}
+	if len(n.TableAligns) != len(other.TableAligns) {
+		return false
+	}
+	for i, align := range n.TableAligns {
+		if align != other.TableAligns[i] {
+			return false
+		}
+	}
+
+	if (nil == n.ListData) != (nil == other.ListData) {
+		return false
+	}
+	if nil != n.ListData {
+		a, b := n.ListData, other.ListData
+		if a.Typ != b.Typ || a.Start != b.Start || a.Checked != b.Checked {
+			return false
+		}
+		if !opts.IgnoreMarkers && (a.BulletChar != b.BulletChar || a.Delimiter != b.Delimiter || !bytes.Equal(a.Marker, b.Marker)) {
+			return false
+		}
+	}
+
+	if !equalIAL(n.KramdownIAL, other.KramdownIAL, opts) || len(n.Properties) != len(other.Properties) {
+		return false
+	}
+	for k, v := range n.Properties {
+		if otherV, ok := other.Properties[k]; !ok || v != otherV {
+			return false
+		}
+	}
+	return true
+}
+
+func equalIAL(a, b [][]string, opts *EqualOptions) bool {
+	filter := func(ial [][]string) (ret [][]string) {
+		for _, kv := range ial {
+			if !opts.IgnoreIDs || "id" != kv[0] {
+				ret = append(ret, kv)
+			}
+		}
+		return
+	}
+	a, b = filter(a), filter(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i, kv := range a {
+		if kv[0] != b[i][0] || kv[1] != b[i][1] {
+			return false
+		}
+	}
+	return true
+}
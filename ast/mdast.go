@@ -0,0 +1,41 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package ast
+
+// MdastLutePrefix 是 Lute 特有节点在 mdast 中的类型前缀，比如 NodeMark 对应的 mdast 类型为 luteMark。
+const MdastLutePrefix = "lute"
+
+// MdastNode 描述了 mdast（https://github.com/syntax-tree/mdast）节点结构，包括 GFM、数学公式、脚注和 Front Matter 扩展。
+//
+// Lute 特有的节点使用 MdastLutePrefix 前缀的自定义类型表示，节点的 Tokens 保存在 Value 中，所有子节点（包括标记符节点）都会保留。
+type MdastNode struct {
+	Type     string       `json:"type"`
+	Children []*MdastNode `json:"children,omitempty"`
+	Value    *string      `json:"value,omitempty"` // 字面量节点（text、code、html 等）的值
+
+	Depth   int     `json:"depth,omitempty"`   // heading 级别
+	Ordered *bool   `json:"ordered,omitempty"` // list 是否有序
+	Start   *int    `json:"start,omitempty"`   // 有序 list 起始序号
+	Spread  *bool   `json:"spread,omitempty"`  // list、listItem 是否松散
+	Checked *bool   `json:"checked,omitempty"` // 任务 listItem 是否勾选
+	Lang    *string `json:"lang,omitempty"`    // code 语言
+	Meta    *string `json:"meta,omitempty"`    // code 信息字符串中语言之后的部分
+
+	URL           *string   `json:"url,omitempty"`           // link、image、definition 地址
+	Title         *string   `json:"title,omitempty"`         // link、image、definition 标题
+	Alt           *string   `json:"alt,omitempty"`           // image 替代文本
+	Identifier    string    `json:"identifier,omitempty"`    // 定义和引用的规范化标识
+	Label         string    `json:"label,omitempty"`         // 定义和引用的原始标识
+	ReferenceType string    `json:"referenceType,omitempty"` // linkReference、imageReference 的引用类型
+	Align         []*string `json:"align,omitempty"`         // table 列对齐方式，取值 left、center、right 或者 null
+
+	Data map[string]interface{} `json:"data,omitempty"` // 扩展数据，id 为标题 ID，kramdownIAL 为内联属性列表
+}
@@ -375,6 +375,57 @@ func (n *Node) IsChildBlockOf(parent *Node, depth int) bool {
 	return true
 }
 
+// PlainText 返回 n 及其文本子节点的纯文本。和 Text 不同的是软换行会被处理：位于两个中日韩字符之间的软换行直接去掉，其他软换行使用空格连接。
+func (n *Node) PlainText() (ret string) {
+	buf := &bytes.Buffer{}
+	Walk(n, func(n *Node, entering bool) WalkStatus {
+		if !entering {
+			return WalkContinue
+		}
+		switch n.Type {
+		case NodeText, NodeLinkText, NodeBlockRefText, NodeFileAnnotationRefText, NodeBlockEmbedText, NodeFootnotesRef:
+			// 从 DOM 转换得到的文本节点中可能直接包含换行符
+			buf.WriteString(joinSoftBreaks(util.BytesToStr(n.Tokens)))
+		case NodeSoftBreak:
+			if !n.IsCJKSoftBreak() {
+				buf.WriteByte(' ')
+			}
+		}
+		return WalkContinue
+	})
+	return buf.String()
+}
+
+// IsCJKSoftBreak 判断软换行节点 n 是否位于两个中日韩字符之间。
+func (n *Node) IsCJKSoftBreak() bool {
+	if NodeSoftBreak != n.Type {
+		return false
+	}
+
+	before, _ := utf8.DecodeLastRuneInString(n.PreviousNodeText())
+	after, _ := utf8.DecodeRuneInString(n.NextNodeText())
+	return lex.IsCJK(before) && lex.IsCJK(after)
+}
+
+func joinSoftBreaks(text string) string {
+	if !strings.Contains(text, "\n") {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	buf := &bytes.Buffer{}
+	buf.WriteString(lines[0])
+	for i := 1; i < len(lines); i++ {
+		before, _ := utf8.DecodeLastRuneInString(lines[i-1])
+		after, _ := utf8.DecodeRuneInString(lines[i])
+		if !lex.IsCJK(before) || !lex.IsCJK(after) {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(lines[i])
+	}
+	return buf.String()
+}
+
 func (n *Node) NextNodeText() string {
 	if nil == n.Next {
 		return ""
@@ -0,0 +1,26 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package ast
+
+// PandocAPIVersion 是生成的 Pandoc JSON AST（pandoc -t json）的 API 版本号，导入时要求主版本号相同且次版本号不低于该版本。
+var PandocAPIVersion = []int{1, 22, 2, 1}
+
+// Lute 特有的节点在 Pandoc JSON AST 中使用带有 PandocLuteClass 类名的 Div（块级节点）或者 Span（行级节点）表示，
+// 节点类型和 Tokens 分别保存在 PandocLuteTypeKey 和 PandocLuteTokensKey 属性中。
+// 带有 Kramdown 块级内联属性列表的块级节点使用带有 PandocLuteIALClass 类名的 Div 包裹，属性列表保存在 Div 的属性中。
+// 列表下的 Lute 特有节点放到前一个列表项的末尾，并添加 PandocLuteListClass 类名。
+const (
+	PandocLuteClass     = "lute"
+	PandocLuteIALClass  = "lute-ial"
+	PandocLuteListClass = "lute-list"
+	PandocLuteTypeKey   = "lute-type"
+	PandocLuteTokensKey = "lute-tokens"
+)
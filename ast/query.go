@@ -0,0 +1,477 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query 使用类似 CSS 选择器的语法查找 root 下（不包括 root）所有匹配 selector 的节点，结果按照文档顺序排列，selector 非法时返回 nil。
+//
+// 选择器语法说明见 ParseSelector。
+func Query(root *Node, selector string) (ret []*Node) {
+	s, err := ParseSelector(selector)
+	if nil != err {
+		return
+	}
+	return s.Query(root)
+}
+
+// Selector 描述了节点选择器。
+type Selector struct {
+	alternatives []*complexSelector // 逗号分隔的选择器，匹配任意一个即可
+}
+
+// complexSelector 描述了由组合符连接的多个复合选择器，比如 list > listItem paragraph。
+type complexSelector struct {
+	compounds   []*compoundSelector
+	combinators []byte // combinators[i] 是 compounds[i] 和 compounds[i+1] 之间的组合符，' ' 为后代，'>' 为子节点
+	relative    bool   // 是否以 > 开头，仅在 :has() 中使用，表示第一个复合选择器匹配的是子节点
+}
+
+// compoundSelector 描述了复合选择器，比如 listItem[custom-priority=high]:first-child。
+type compoundSelector struct {
+	typ     NodeType // 节点类型，-1 表示任意类型
+	attrs   []*attrSelector
+	pseudos []*pseudoSelector
+}
+
+// attrSelector 描述了属性选择器，比如 [checked]、[custom-priority=high]。
+type attrSelector struct {
+	name, op, value string
+}
+
+// pseudoSelector 描述了伪类选择器，比如 :first-child、:has(taskListItemMarker)、:contains(foo)。
+type pseudoSelector struct {
+	name     string
+	arg      string
+	selector *Selector
+}
+
+// ParseSelector 解析选择器 selector。支持的语法如下：
+//
+//   - 节点类型：节点类型名去掉 Node 前缀，不区分大小写，比如 paragraph、listItem，* 匹配任意类型
+//   - 属性：[name] 判断属性是否存在，[name=value]、[name!=value]、[name^=value]、[name$=value]、[name*=value] 比较属性值，
+//     属性取自块级内联属性列表，另外 id、checked（已勾选的任务列表项）、level（标题级别）、lang（代码块语言）和 dest（链接地址）为内置属性
+//   - 伪类：:first-child、:last-child、:has(selector)、:not(selector)、:contains(text)，
+//     计算子节点位置时忽略标记符节点和块级内联属性列表节点，:contains 判断节点文本是否包含 text
+//   - 组合符：空白表示后代，> 表示子节点，逗号分隔多个选择器
+func ParseSelector(selector string) (ret *Selector, err error) {
+	p := &selectorParser{input: selector}
+	if ret, err = p.parseSelector(false); nil != err {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		return nil, p.error("unexpected character")
+	}
+	return
+}
+
+// Query 查找 root 下（不包括 root）所有匹配 s 的节点，结果按照文档顺序排列。
+func (s *Selector) Query(root *Node) (ret []*Node) {
+	Walk(root, func(n *Node, entering bool) WalkStatus {
+		if entering && n != root && s.match(n, nil) {
+			ret = append(ret, n)
+		}
+		return WalkContinue
+	})
+	return
+}
+
+// Match 判断节点 n 是否匹配 s。
+func (s *Selector) Match(n *Node) bool {
+	return s.match(n, nil)
+}
+
+// match 判断节点 n 是否匹配 s，scope 不为 nil 时祖先节点的查找限制在 scope 之内。
+func (s *Selector) match(n *Node, scope *Node) bool {
+	for _, alternative := range s.alternatives {
+		if alternative.match(n, len(alternative.compounds)-1, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *complexSelector) match(n *Node, i int, scope *Node) bool {
+	if !c.compounds[i].match(n) {
+		return false
+	}
+	if 0 == i {
+		return !c.relative || n.Parent == scope
+	}
+
+	if '>' == c.combinators[i-1] {
+		parent := n.Parent
+		return nil != parent && parent != scope && c.match(parent, i-1, scope)
+	}
+	for p := n.Parent; nil != p && p != scope; p = p.Parent {
+		if c.match(p, i-1, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *compoundSelector) match(n *Node) bool {
+	if -1 != c.typ && c.typ != n.Type {
+		return false
+	}
+	for _, attr := range c.attrs {
+		if !attr.match(n) {
+			return false
+		}
+	}
+	for _, pseudo := range c.pseudos {
+		if !pseudo.match(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *attrSelector) match(n *Node) bool {
+	value, ok := selectorAttr(n, a.name)
+	switch a.op {
+	case "":
+		return ok
+	case "=":
+		return ok && a.value == value
+	case "!=":
+		return !ok || a.value != value
+	case "^=":
+		return ok && strings.HasPrefix(value, a.value)
+	case "$=":
+		return ok && strings.HasSuffix(value, a.value)
+	case "*=":
+		return ok && strings.Contains(value, a.value)
+	}
+	return false
+}
+
+func (p *pseudoSelector) match(n *Node) bool {
+	switch p.name {
+	case "first-child":
+		return nil != n.Parent && n == selectorChild(n.Parent.FirstChild, true)
+	case "last-child":
+		return nil != n.Parent && n == selectorChild(n.Parent.LastChild, false)
+	case "has":
+		found := false
+		Walk(n, func(d *Node, entering bool) WalkStatus {
+			if entering && d != n && p.selector.match(d, n) {
+				found = true
+				return WalkStop
+			}
+			return WalkContinue
+		})
+		return found
+	case "not":
+		return !p.selector.match(n, nil)
+	case "contains":
+		return strings.Contains(n.Text(), p.arg)
+	}
+	return false
+}
+
+// selectorChild 从 n 开始查找第一个（forward 为 false 时向前查找）不是标记符和块级内联属性列表的节点。
+func selectorChild(n *Node, forward bool) *Node {
+	for nil != n {
+		if !n.IsMarker() && NodeKramdownBlockIAL != n.Type && NodeKramdownSpanIAL != n.Type {
+			return n
+		}
+		if forward {
+			n = n.Next
+		} else {
+			n = n.Previous
+		}
+	}
+	return nil
+}
+
+// selectorAttr 返回节点 n 的属性 name 的值，ok 表示属性是否存在。
+func selectorAttr(n *Node, name string) (value string, ok bool) {
+	for _, kv := range n.KramdownIAL {
+		if name == kv[0] {
+			return n.IALAttr(name), true
+		}
+	}
+
+	switch name {
+	case "id":
+		return n.ID, "" != n.ID
+	case "checked":
+		switch n.Type {
+		case NodeTaskListItemMarker:
+			return "true", n.TaskListItemChecked
+		case NodeListItem:
+			return "true", nil != n.ListData && 3 == n.ListData.Typ && n.ListData.Checked
+		}
+	case "level":
+		if NodeHeading == n.Type {
+			return strconv.Itoa(n.HeadingLevel), true
+		}
+	case "lang":
+		if NodeCodeBlock == n.Type {
+			if fields := strings.Fields(string(n.CodeBlockInfo)); 0 < len(fields) {
+				return fields[0], true
+			}
+		}
+	case "dest":
+		if NodeLink == n.Type || NodeImage == n.Type {
+			if dest := n.ChildByType(NodeLinkDest); nil != dest {
+				return string(dest.Tokens), true
+			}
+		}
+	}
+	return
+}
+
+// selectorParser 用于解析选择器。
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *selectorParser) parseSelector(nested bool) (ret *Selector, err error) {
+	ret = &Selector{}
+	for {
+		var complex *complexSelector
+		if complex, err = p.parseComplex(nested); nil != err {
+			return
+		}
+		ret.alternatives = append(ret.alternatives, complex)
+		p.skipSpaces()
+		if !p.consume(',') {
+			return
+		}
+	}
+}
+
+func (p *selectorParser) parseComplex(nested bool) (ret *complexSelector, err error) {
+	ret = &complexSelector{}
+	p.skipSpaces()
+	if nested && p.consume('>') {
+		ret.relative = true
+		p.skipSpaces()
+	}
+
+	for {
+		var compound *compoundSelector
+		if compound, err = p.parseCompound(); nil != err {
+			return
+		}
+		ret.compounds = append(ret.compounds, compound)
+
+		spaces := p.skipSpaces()
+		if p.pos >= len(p.input) {
+			return
+		}
+		switch c := p.input[p.pos]; c {
+		case ',', ')':
+			return
+		case '>':
+			p.pos++
+			p.skipSpaces()
+			ret.combinators = append(ret.combinators, '>')
+		default:
+			if !spaces {
+				return nil, p.error("unexpected character")
+			}
+			ret.combinators = append(ret.combinators, ' ')
+		}
+	}
+}
+
+func (p *selectorParser) parseCompound() (ret *compoundSelector, err error) {
+	ret = &compoundSelector{typ: -1}
+	start := p.pos
+	if !p.consume('*') {
+		if name := p.parseIdent(); "" != name {
+			if ret.typ = selectorNodeType(name); -1 == ret.typ {
+				return nil, fmt.Errorf("unknown node type [%s] in selector [%s]", name, p.input)
+			}
+		}
+	}
+
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case '[':
+			p.pos++
+			var attr *attrSelector
+			if attr, err = p.parseAttr(); nil != err {
+				return
+			}
+			ret.attrs = append(ret.attrs, attr)
+			continue
+		case ':':
+			p.pos++
+			var pseudo *pseudoSelector
+			if pseudo, err = p.parsePseudo(); nil != err {
+				return
+			}
+			ret.pseudos = append(ret.pseudos, pseudo)
+			continue
+		}
+		break
+	}
+
+	if start == p.pos {
+		return nil, p.error("expected selector")
+	}
+	return
+}
+
+func (p *selectorParser) parseAttr() (ret *attrSelector, err error) {
+	p.skipSpaces()
+	ret = &attrSelector{name: p.parseIdent()}
+	if "" == ret.name {
+		return nil, p.error("expected attribute name")
+	}
+	p.skipSpaces()
+	if p.consume(']') {
+		return
+	}
+
+	for _, op := range []string{"=", "!=", "^=", "$=", "*="} {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			ret.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if "" == ret.op {
+		return nil, p.error("expected attribute operator")
+	}
+	p.skipSpaces()
+	if ret.value, err = p.parseValue(); nil != err {
+		return
+	}
+	p.skipSpaces()
+	if !p.consume(']') {
+		return nil, p.error("expected ]")
+	}
+	return
+}
+
+func (p *selectorParser) parsePseudo() (ret *pseudoSelector, err error) {
+	ret = &pseudoSelector{name: strings.ToLower(p.parseIdent())}
+	switch ret.name {
+	case "first-child", "last-child":
+		return
+	case "has", "not":
+		if !p.consume('(') {
+			return nil, p.error("expected (")
+		}
+		if ret.selector, err = p.parseSelector("has" == ret.name); nil != err {
+			return
+		}
+	case "contains":
+		if !p.consume('(') {
+			return nil, p.error("expected (")
+		}
+		p.skipSpaces()
+		if ret.arg, err = p.parseValue(); nil != err {
+			return
+		}
+	default:
+		return nil, fmt.Errorf("unknown pseudo-class [:%s] in selector [%s]", ret.name, p.input)
+	}
+	p.skipSpaces()
+	if !p.consume(')') {
+		return nil, p.error("expected )")
+	}
+	return
+}
+
+// parseValue 解析属性值或者伪类参数，可以使用单引号或者双引号包裹。
+func (p *selectorParser) parseValue() (string, error) {
+	if p.pos >= len(p.input) {
+		return "", p.error("expected value")
+	}
+	quote := p.input[p.pos]
+	if '"' != quote && '\'' != quote {
+		start := p.pos
+		for p.pos < len(p.input) && !strings.ContainsRune(" ])", rune(p.input[p.pos])) {
+			p.pos++
+		}
+		if start == p.pos {
+			return "", p.error("expected value")
+		}
+		return p.input[start:p.pos], nil
+	}
+
+	p.pos++
+	buf := &strings.Builder{}
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		switch c {
+		case quote:
+			return buf.String(), nil
+		case '\\':
+			if p.pos < len(p.input) {
+				c = p.input[p.pos]
+				p.pos++
+			}
+		}
+		buf.WriteByte(c)
+	}
+	return "", p.error("unterminated string")
+}
+
+func (p *selectorParser) parseIdent() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if ('a' <= c && 'z' >= c) || ('A' <= c && 'Z' >= c) || ('0' <= c && '9' >= c) || '-' == c || '_' == c {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *selectorParser) skipSpaces() (skipped bool) {
+	for p.pos < len(p.input) && strings.ContainsRune(" \t\n\r", rune(p.input[p.pos])) {
+		p.pos++
+		skipped = true
+	}
+	return
+}
+
+func (p *selectorParser) consume(c byte) bool {
+	if p.pos < len(p.input) && c == p.input[p.pos] {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *selectorParser) error(msg string) error {
+	return errors.New(msg + " at position " + strconv.Itoa(p.pos) + " in selector [" + p.input + "]")
+}
+
+// selectorNodeType 返回选择器中的节点类型名 name 对应的节点类型，name 不区分大小写并且可以省略 Node 前缀，未找到时返回 -1。
+func selectorNodeType(name string) NodeType {
+	if !strings.HasPrefix(name, "Node") {
+		name = "Node" + name
+	}
+	for t := NodeDocument; t < NodeTypeMaxVal; t++ {
+		if strings.EqualFold(name, t.String()) {
+			return t
+		}
+	}
+	return -1
+}
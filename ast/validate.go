@@ -0,0 +1,182 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package ast
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Violation 描述了语法树中违反结构约束的问题。
+type Violation struct {
+	Node *Node  // 出现问题的节点
+	Msg  string // 问题描述
+}
+
+func (v *Violation) String() string {
+	if "" != v.Node.ID {
+		return v.Node.Type.String() + " [" + v.Node.ID + "]: " + v.Msg
+	}
+	return v.Node.Type.String() + ": " + v.Msg
+}
+
+// Violations 描述了语法树校验发现的所有问题。
+type Violations []*Violation
+
+func (violations Violations) Error() string {
+	var msgs []string
+	for _, v := range violations {
+		msgs = append(msgs, v.String())
+	}
+	return "invalid tree: " + strings.Join(msgs, "; ")
+}
+
+// markerPairs 定义了开始标记符和结束标记符的对应关系。
+var markerPairs = map[NodeType]NodeType{
+	NodeCodeBlockFenceOpenMarker:  NodeCodeBlockFenceCloseMarker,
+	NodeEmA6kOpenMarker:           NodeEmA6kCloseMarker,
+	NodeEmU8eOpenMarker:           NodeEmU8eCloseMarker,
+	NodeStrongA6kOpenMarker:       NodeStrongA6kCloseMarker,
+	NodeStrongU8eOpenMarker:       NodeStrongU8eCloseMarker,
+	NodeCodeSpanOpenMarker:        NodeCodeSpanCloseMarker,
+	NodeStrikethrough1OpenMarker:  NodeStrikethrough1CloseMarker,
+	NodeStrikethrough2OpenMarker:  NodeStrikethrough2CloseMarker,
+	NodeMathBlockOpenMarker:       NodeMathBlockCloseMarker,
+	NodeInlineMathOpenMarker:      NodeInlineMathCloseMarker,
+	NodeYamlFrontMatterOpenMarker: NodeYamlFrontMatterCloseMarker,
+	NodeMark1OpenMarker:           NodeMark1CloseMarker,
+	NodeMark2OpenMarker:           NodeMark2CloseMarker,
+	NodeTagOpenMarker:             NodeTagCloseMarker,
+	NodeSuperBlockOpenMarker:      NodeSuperBlockCloseMarker,
+	NodeSupOpenMarker:             NodeSupCloseMarker,
+	NodeSubOpenMarker:             NodeSubCloseMarker,
+	NodeGitConflictOpenMarker:     NodeGitConflictCloseMarker,
+	NodeKbdOpenMarker:             NodeKbdCloseMarker,
+	NodeUnderlineOpenMarker:       NodeUnderlineCloseMarker,
+	NodeTextMarkOpenMarker:        NodeTextMarkCloseMarker,
+}
+
+// Validate 校验以 root 为根的语法树的结构，返回发现的所有问题，没有问题时返回 nil。
+//
+// 校验的规则包括：
+//   - 包含关系：块级节点的父节点必须是能够包含它的块级节点（参考 CanContain），容器块下不能直接出现行级节点
+//   - 标记符配对：开始标记符必须是父节点的第一个子节点，并且父节点的最后一个子节点是对应的结束标记符，反之亦然
+//   - 表格形状：表格的每一行的单元格数必须和对齐方式数一致
+//   - ID 唯一：节点 ID 不能重复，并且和内联属性列表中的 id 属性一致
+func Validate(root *Node) (ret Violations) {
+	ids := map[string]*Node{}
+	Walk(root, func(n *Node, entering bool) WalkStatus {
+		if !entering {
+			return WalkContinue
+		}
+
+		report := func(msg string) {
+			ret = append(ret, &Violation{Node: n, Msg: msg})
+		}
+		validateContainment(n, report)
+		validateMarkers(n, report)
+		if NodeTable == n.Type {
+			validateTable(n, report)
+		}
+
+		if "" != n.ID {
+			if prev := ids[n.ID]; nil != prev {
+				report("duplicated id with " + prev.Type.String())
+			}
+			ids[n.ID] = n
+			if id := n.IALAttr("id"); "" != id && id != n.ID {
+				report("id is inconsistent with IAL id [" + id + "]")
+			}
+		}
+		return WalkContinue
+	})
+	return
+}
+
+func validateContainment(n *Node, report func(msg string)) {
+	parent := n.Parent
+	if nil == parent {
+		return
+	}
+
+	if isValidateBlock(n) {
+		switch {
+		case !isValidateBlock(parent):
+			report("block is under " + parent.Type.String())
+		case NodeKramdownBlockIAL == n.Type:
+			// 块级内联属性列表跟随在块后或者位于列表项开头
+			if !parent.IsContainerBlock() {
+				report("IAL is under " + parent.Type.String())
+			}
+		case NodeSuperBlock == parent.Type:
+			// 闭合后的超级块 CanContain 返回 false，这里仅检查列表项
+			if NodeListItem == n.Type {
+				report("list item is under " + parent.Type.String())
+			}
+		case !parent.CanContain(n.Type):
+			report("block is under " + parent.Type.String())
+		}
+		return
+	}
+
+	if parent.IsContainerBlock() && !n.IsMarker() {
+		report("inline is under " + parent.Type.String())
+	}
+}
+
+// isValidateBlock 判断 n 在校验包含关系时是否作为块级节点，链接引用定义块和链接引用定义也是块级节点。
+func isValidateBlock(n *Node) bool {
+	return n.IsBlock() || NodeLinkRefDefBlock == n.Type || NodeLinkRefDef == n.Type
+}
+
+func validateMarkers(n *Node, report func(msg string)) {
+	if nil == n.Parent {
+		return
+	}
+
+	if closeMarker, ok := markerPairs[n.Type]; ok {
+		if n != n.Parent.FirstChild {
+			report("open marker is not the first child")
+		} else if closeMarker != n.Parent.LastChild.Type {
+			report("open marker is unpaired")
+		}
+		return
+	}
+
+	for open, closeMarker := range markerPairs {
+		if closeMarker != n.Type {
+			continue
+		}
+		if n != n.Parent.LastChild {
+			report("close marker is not the last child")
+		} else if open != n.Parent.FirstChild.Type {
+			report("close marker is unpaired")
+		}
+		return
+	}
+}
+
+func validateTable(table *Node, report func(msg string)) {
+	for c := table.FirstChild; nil != c; c = c.Next {
+		rows := []*Node{c}
+		if NodeTableHead == c.Type {
+			rows = c.ChildrenByType(NodeTableRow)
+		}
+		for _, row := range rows {
+			if NodeTableRow != row.Type {
+				continue
+			}
+			if cells := len(row.ChildrenByType(NodeTableCell)); cells != len(table.TableAligns) {
+				report("table row has " + strconv.Itoa(cells) + " cells, expected " + strconv.Itoa(len(table.TableAligns)))
+			}
+		}
+	}
+}
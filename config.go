@@ -0,0 +1,144 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+// Config 描述了 Lute 引擎配置的不可变快照，可以安全地在多个 goroutine 之间共享。
+//
+// Config 创建后不能再修改，需要调整时通过 Builder 获取构建器，构建器在写入时复制，不会影响已有的快照。
+type Config struct {
+	parseOptions  parse.Options
+	renderOptions render.Options
+}
+
+// NewConfig 创建一个使用默认选项的配置快照。
+func NewConfig() *Config {
+	return newConfig(parse.NewOptions(), render.NewOptions())
+}
+
+// Config 返回引擎当前选项的配置快照，之后对引擎选项的修改不会影响该快照。
+func (lute *Lute) Config() *Config {
+	return newConfig(lute.ParseOptions, lute.RenderOptions)
+}
+
+// NewWithConfig 使用配置快照 config 创建一个新的 Lute 引擎。
+//
+// 引擎和快照共享 Emoji 和术语字典，引擎的 PutEmojis 和 PutTerms 会在写入时复制，所以修改引擎不会影响快照。
+func NewWithConfig(config *Config) (ret *Lute) {
+	ret = New()
+	ret.ParseOptions = config.ParseOptions()
+	ret.RenderOptions = config.RenderOptions()
+	return
+}
+
+// ParseOptions 返回解析选项的副本，其中的 Emoji 字典是只读的，不能直接修改。
+func (config *Config) ParseOptions() *parse.Options {
+	ret := config.parseOptions
+	return &ret
+}
+
+// RenderOptions 返回渲染选项的副本，其中的术语字典是只读的，不能直接修改。
+func (config *Config) RenderOptions() *render.Options {
+	ret := config.renderOptions
+	return &ret
+}
+
+// Builder 返回基于该快照的配置构建器。
+func (config *Config) Builder() *ConfigBuilder {
+	return &ConfigBuilder{config: *config}
+}
+
+func newConfig(parseOptions *parse.Options, renderOptions *render.Options) (ret *Config) {
+	ret = &Config{parseOptions: *parseOptions, renderOptions: *renderOptions}
+	// 快照持有字典的副本，避免引擎或者其他调用方直接修改字典时影响快照
+	ret.parseOptions.AliasEmoji = copyStrMap(parseOptions.AliasEmoji, 0)
+	ret.parseOptions.EmojiAlias = copyStrMap(parseOptions.EmojiAlias, 0)
+	ret.renderOptions.Terms = copyStrMap(renderOptions.Terms, 0)
+	return
+}
+
+// ConfigBuilder 描述了配置构建器，用于在已有快照的基础上构建新的快照。
+//
+// 构建器和原快照共享 Emoji 和术语字典，直到第一次写入字典时才进行复制。构建器不是并发安全的。
+type ConfigBuilder struct {
+	config       Config // 构建中的配置
+	emojisCopied bool   // Emoji 字典是否已经复制
+	termsCopied  bool   // 术语字典是否已经复制
+}
+
+// ParseOptions 使用 f 修改解析选项。f 中不能直接修改 Emoji 字典，请使用 PutEmojis。
+func (builder *ConfigBuilder) ParseOptions(f func(options *parse.Options)) *ConfigBuilder {
+	f(&builder.config.parseOptions)
+	return builder
+}
+
+// RenderOptions 使用 f 修改渲染选项。f 中不能直接修改术语字典，请使用 PutTerms。
+func (builder *ConfigBuilder) RenderOptions(f func(options *render.Options)) *ConfigBuilder {
+	f(&builder.config.renderOptions)
+	return builder
+}
+
+// PutEmojis 将指定的 emojiMap 合并覆盖已有的 Emoji 字典。
+func (builder *ConfigBuilder) PutEmojis(emojiMap map[string]string) *ConfigBuilder {
+	options := &builder.config.parseOptions
+	if !builder.emojisCopied {
+		options.AliasEmoji = copyStrMap(options.AliasEmoji, len(emojiMap))
+		options.EmojiAlias = copyStrMap(options.EmojiAlias, len(emojiMap))
+		builder.emojisCopied = true
+	}
+	for k, v := range emojiMap {
+		options.AliasEmoji[k] = v
+		options.EmojiAlias[v] = k
+	}
+	return builder
+}
+
+// PutTerms 将指定的 termMap 合并覆盖已有的术语字典。
+func (builder *ConfigBuilder) PutTerms(termMap map[string]string) *ConfigBuilder {
+	options := &builder.config.renderOptions
+	if !builder.termsCopied {
+		if nil == options.Terms {
+			options.Terms = render.NewTerms()
+		} else {
+			options.Terms = copyStrMap(options.Terms, len(termMap))
+		}
+		builder.termsCopied = true
+	}
+	for k, v := range termMap {
+		options.Terms[k] = v
+	}
+	return builder
+}
+
+// Build 构建配置快照，之后对构建器的修改不会影响该快照。
+func (builder *ConfigBuilder) Build() *Config {
+	ret := builder.config
+	// 快照和构建器共享字典，构建器再次写入字典时需要重新复制
+	builder.emojisCopied, builder.termsCopied = false, false
+	return &ret
+}
+
+// copyStrMap 复制字典 m，grow 指定了预留的额外容量。
+func copyStrMap(m map[string]string, grow int) (ret map[string]string) {
+	if nil == m {
+		return
+	}
+
+	ret = make(map[string]string, len(m)+grow)
+	for k, v := range m {
+		ret[k] = v
+	}
+	return
+}
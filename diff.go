@@ -0,0 +1,29 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
+)
+
+// Diff 比较 oldMarkdown 和 newMarkdown 的块级差异。
+func (lute *Lute) Diff(oldMarkdown, newMarkdown string) *parse.TreeDiff {
+	return parse.Diff(lute.parse("", []byte(oldMarkdown)), lute.parse("", []byte(newMarkdown)))
+}
+
+// DiffHTML 比较 oldMarkdown 和 newMarkdown，并将差异合并渲染为 HTML，新增和删除的内容分别使用 <ins> 和 <del> 标记。
+func (lute *Lute) DiffHTML(oldMarkdown, newMarkdown string) (html string) {
+	renderer := render.NewHtmlDiffRenderer(lute.Diff(oldMarkdown, newMarkdown), lute.RenderOptions)
+	html = util.BytesToStr(renderer.Render())
+	return
+}
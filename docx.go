@@ -0,0 +1,25 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// +build !javascript
+
+package lute
+
+import (
+	"github.com/88250/lute/render"
+)
+
+// Md2Docx 将 markdown 渲染为 .docx 压缩包，本地图片会嵌入到文档中。
+func (lute *Lute) Md2Docx(markdown string) (docx []byte) {
+	tree := lute.parse("", []byte(markdown))
+	renderer := render.NewDocxRenderer(tree, lute.RenderOptions)
+	docx = renderer.Render()
+	return
+}
@@ -0,0 +1,50 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// +build !javascript
+
+package lute
+
+import (
+	"io/ioutil"
+
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+// Md2EPUB 将 markdowns 打包为 EPUB 3 电子书，每篇 markdown 至少生成一个章节，本地图片的相对路径基于当前工作目录解析。
+func (lute *Lute) Md2EPUB(markdowns ...string) (epub []byte) {
+	var trees []*parse.Tree
+	for _, markdown := range markdowns {
+		trees = append(trees, lute.parse("", []byte(markdown)))
+	}
+	renderer := render.NewEPUBRenderer(trees, lute.RenderOptions)
+	epub = renderer.Render()
+	return
+}
+
+// MdFiles2EPUB 读取 markdown 文件 paths 打包为 EPUB 3 电子书。
+//
+// 文件中本地图片的相对路径基于该文件所在目录解析，指向其他文件的相对链接会改写为对应的章节。
+func (lute *Lute) MdFiles2EPUB(paths ...string) (epub []byte, err error) {
+	var trees []*parse.Tree
+	for _, path := range paths {
+		var markdown []byte
+		if markdown, err = ioutil.ReadFile(path); nil != err {
+			return
+		}
+		tree := lute.parse(path, markdown)
+		tree.Path = path
+		trees = append(trees, tree)
+	}
+	renderer := render.NewEPUBRenderer(trees, lute.RenderOptions)
+	epub = renderer.Render()
+	return
+}
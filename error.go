@@ -0,0 +1,197 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"context"
+	"errors"
+
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
+)
+
+var (
+	// ErrInvalidDOM 描述了输入的 DOM 不合法，无法转换。
+	ErrInvalidDOM = errors.New("invalid DOM")
+	// ErrLimitExceeded 描述了解析时超出了解析选项中设置的限制，超出部分已经作为文本处理。
+	ErrLimitExceeded = errors.New("parse limit exceeded")
+	// ErrCanceled 描述了处理过程被调用方取消或者超时。
+	ErrCanceled = errors.New("canceled")
+	// ErrUnknownFormat 描述了输出格式没有通过 render.Register 注册。
+	ErrUnknownFormat = errors.New("unknown format")
+)
+
+// Error 描述了 Lute 引擎处理时出现的错误，可以通过 errors.Is 判断错误类型。
+type Error struct {
+	Kind error // 错误类型，ErrInvalidDOM、ErrLimitExceeded、ErrCanceled 或 ErrUnknownFormat
+	Err  error // 引起错误的原始错误，可能为 nil
+}
+
+func (e *Error) Error() string {
+	if nil == e.Err {
+		return e.Kind.Error()
+	}
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap 返回引起错误的原始错误。
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is 判断错误类型是否为 target。
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// 以下 ...E 方法和同名方法功能一致，区别在于处理过程中出现的问题会通过 error 返回，而不是 panic。
+//
+// 如果超出解析限制，返回的 error 类型为 ErrLimitExceeded，此时输出仍然可用（超出部分作为文本处理）；
+// 如果 ctx 被取消或者超时，返回的 error 类型为 ErrCanceled，此时输出为空。
+
+// MarkdownE 将 markdown 文本字节数组处理为相应的 html 字节数组。
+func (lute *Lute) MarkdownE(ctx context.Context, name string, markdown []byte) (html []byte, err error) {
+	tree, err := lute.parseE(ctx, name, markdown, false)
+	if nil != tree {
+		if e := try(nil, func() {
+			html = lute.renderHTML(tree)
+		}); nil != e {
+			err = e
+		}
+	}
+	return
+}
+
+// FormatE 将 markdown 文本字节数组进行格式化。
+func (lute *Lute) FormatE(ctx context.Context, name string, markdown []byte) (formatted []byte, err error) {
+	tree, err := lute.parseE(ctx, name, markdown, true)
+	if nil != tree {
+		if e := try(nil, func() {
+			formatted = lute.renderFormat(tree)
+		}); nil != e {
+			err = e
+		}
+	}
+	return
+}
+
+// Md2BlockDOME 将 markdown 转换为 Protyle DOM。
+func (lute *Lute) Md2BlockDOME(ctx context.Context, markdown string) (vHTML string, err error) {
+	tree, err := lute.parseE(ctx, "", []byte(markdown), true)
+	if nil != tree {
+		if e := try(nil, func() {
+			vHTML = lute.renderBlockDOM(tree)
+		}); nil != e {
+			err = e
+		}
+	}
+	return
+}
+
+// RenderE 将 markdown 渲染为 format 格式，格式未注册时返回的 error 类型为 ErrUnknownFormat。
+func (lute *Lute) RenderE(ctx context.Context, format, markdown string) (output string, err error) {
+	if nil == render.Lookup(format) {
+		return "", &Error{Kind: ErrUnknownFormat, Err: errors.New(format)}
+	}
+
+	tree, err := lute.parseE(ctx, "", []byte(markdown), false)
+	if nil != tree {
+		if e := try(nil, func() {
+			output = lute.RenderTree(format, tree)
+		}); nil != e {
+			err = e
+		}
+	}
+	return
+}
+
+// BlockDOM2MdE 将 Protyle DOM 转换为 markdown。
+func (lute *Lute) BlockDOM2MdE(ctx context.Context, htmlStr string) (markdown string, err error) {
+	err = lute.domE(ctx, func() {
+		markdown = lute.BlockDOM2Md(htmlStr)
+	})
+	return
+}
+
+// BlockDOM2TreeE 将 Protyle DOM 转换为语法树。
+func (lute *Lute) BlockDOM2TreeE(ctx context.Context, htmlStr string) (tree *parse.Tree, err error) {
+	err = lute.domE(ctx, func() {
+		if tree = lute.BlockDOM2Tree(htmlStr); nil == tree {
+			panic("parse HTML failed")
+		}
+	})
+	if nil != err {
+		tree = nil
+	}
+	return
+}
+
+// VditorDOM2MdE 将 Vditor DOM 转换为 markdown。
+func (lute *Lute) VditorDOM2MdE(ctx context.Context, htmlStr string) (markdown string, err error) {
+	err = lute.domE(ctx, func() {
+		markdown = lute.VditorDOM2Md(htmlStr)
+	})
+	return
+}
+
+// VditorIRDOM2MdE 将 Vditor Instant-Rendering DOM 转换为 markdown。
+func (lute *Lute) VditorIRDOM2MdE(ctx context.Context, htmlStr string) (markdown string, err error) {
+	err = lute.domE(ctx, func() {
+		markdown = lute.VditorIRDOM2Md(htmlStr)
+	})
+	return
+}
+
+// parseE 使用 ctx 解析 markdown，解析被取消时返回的 tree 为 nil。editing 为 true 时跳过 ExportOnly 转换器。
+func (lute *Lute) parseE(ctx context.Context, name string, markdown []byte, editing bool) (tree *parse.Tree, err error) {
+	if e := try(nil, func() {
+		if tree, err = parse.ParseWithContext(ctx, name, markdown, lute.ParseOptions); nil == err {
+			lute.transform(tree, editing)
+		}
+	}); nil != e {
+		return nil, e
+	}
+	if nil != err {
+		return nil, &Error{Kind: ErrCanceled, Err: err}
+	}
+	if tree.Context.LimitExceeded() {
+		err = &Error{Kind: ErrLimitExceeded}
+	}
+	return
+}
+
+// domE 执行 DOM 转换函数 f，转换前后检查 ctx 是否已经取消，转换过程中出现的 panic 作为 ErrInvalidDOM 返回。
+func (lute *Lute) domE(ctx context.Context, f func()) (err error) {
+	if err = ctx.Err(); nil != err {
+		return &Error{Kind: ErrCanceled, Err: err}
+	}
+	if err = try(ErrInvalidDOM, f); nil != err {
+		return
+	}
+	if err = ctx.Err(); nil != err {
+		return &Error{Kind: ErrCanceled, Err: err}
+	}
+	return
+}
+
+// try 执行 f 并恢复其中出现的 panic，kind 不为 nil 时使用 kind 作为返回错误的类型。
+func try(kind error, f func()) (err error) {
+	defer func() {
+		if nil != err && nil != kind {
+			err = &Error{Kind: kind, Err: err}
+		}
+	}()
+	defer util.RecoverPanic(&err)
+
+	f()
+	return
+}
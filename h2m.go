@@ -73,6 +73,8 @@ func (lute *Lute) HTML2Tree(dom string) (ret *parse.Tree) {
 		}
 		return ast.WalkContinue
 	})
+
+	lute.validateTree(ret)
 	return
 }
 
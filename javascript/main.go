@@ -39,5 +39,6 @@ func main() {
 		"UnEscapeHTMLStr":   html.UnescapeHTMLStr,
 		"EChartsMindmapStr": render.EChartsMindmapStr,
 		"Sanitize":          render.Sanitize,
+		"Formats":           render.Formats,
 	})
 }
@@ -0,0 +1,52 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
+)
+
+// Md2JiraWiki 将 markdown 渲染为 Jira 文本格式（wiki markup）。
+func (lute *Lute) Md2JiraWiki(markdown string) (wiki string) {
+	tree := lute.parse("", []byte(markdown))
+	wiki = lute.Tree2JiraWiki(tree)
+	return
+}
+
+// Tree2JiraWiki 将 tree 渲染为 Jira 文本格式。
+func (lute *Lute) Tree2JiraWiki(tree *parse.Tree) (wiki string) {
+	renderer := render.NewJiraRenderer(tree, lute.RenderOptions)
+	wiki = util.BytesToStr(renderer.Render())
+	return
+}
+
+// JiraWiki2Tree 将 Jira 文本格式转换为语法树，比如 Jira 问题的描述转换为语法树后可以使用 Lute 的渲染器进行渲染。
+func (lute *Lute) JiraWiki2Tree(wiki string) (tree *parse.Tree) {
+	return parse.ParseJiraWiki("", []byte(wiki), lute.ParseOptions)
+}
+
+// JiraWiki2HTML 将 Jira 文本格式渲染为 HTML。
+func (lute *Lute) JiraWiki2HTML(wiki string) (html string) {
+	tree := lute.JiraWiki2Tree(wiki)
+	renderer := render.NewHtmlRenderer(tree, lute.RenderOptions)
+	html = util.BytesToStr(renderer.Render())
+	return
+}
+
+// JiraWiki2Md 将 Jira 文本格式格式化为 markdown。
+func (lute *Lute) JiraWiki2Md(wiki string) (markdown string) {
+	tree := lute.JiraWiki2Tree(wiki)
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
@@ -31,6 +31,11 @@ func NewLexer(input []byte) (ret *Lexer) {
 	return
 }
 
+// Remains 返回剩余还未读取的输入。
+func (l *Lexer) Remains() []byte {
+	return l.input[l.offset:l.length:l.length]
+}
+
 // NextLine 返回下一行。
 func (l *Lexer) NextLine() (ret []byte) {
 	if l.offset >= l.length {
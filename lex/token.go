@@ -60,6 +60,16 @@ func IsUnicodeWhitespace(r rune) bool {
 	return unicode.IsSpace(r) || unicode.Is(unicode.Zs, r)
 }
 
+// IsCJK 判断 r 是否是中日韩字符，包括中日韩标点符号和全角符号。
+func IsCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r) || unicode.Is(unicode.Bopomofo, r) ||
+		(0x3000 <= r && 0x303F >= r) || // CJK Symbols and Punctuation
+		(0xFE10 <= r && 0xFE1F >= r) || // Vertical Forms
+		(0xFE30 <= r && 0xFE4F >= r) || // CJK Compatibility Forms
+		(0xFF00 <= r && 0xFFEF >= r) // Halfwidth and Fullwidth Forms
+}
+
 // IsDigit 判断 token 是否为数字 0-9。
 func IsDigit(token byte) bool {
 	return '0' <= token && '9' >= token
@@ -39,6 +39,17 @@ type Lute struct {
 	Md2VditorIRDOMRendererFuncs   map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Md2VditorIRDOM 渲染器函数
 	Md2BlockDOMRendererFuncs      map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Md2BlockDOM 渲染器函数
 	Md2VditorSVDOMRendererFuncs   map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Md2VditorSVDOM 渲染器函数
+
+	ExtRendererFuncs map[string]map[ast.NodeType]render.ExtRendererFunc // 用户自定义的 Render 渲染器函数，键为输出格式名
+
+	Transformers []Transformer // 语法树转换器，解析 Markdown 后按照添加顺序依次执行
+	ValidateTree bool          // 是否在 DOM 转换为语法树后校验语法树，校验不通过时 panic，仅用于调试
+
+	strictBools   []bool   // 启用严格模式前 strictBoolOptions 中各选项的值，关闭严格模式时恢复
+	strictStrings []string // 启用严格模式前 strictStringOptions 中各选项的值，关闭严格模式时恢复
+
+	emojisOwner *parse.Options  // 已经复制了 Emoji 字典的解析选项，写入该选项的字典时不需要再复制
+	termsOwner  *render.Options // 已经复制了术语字典的渲染选项，写入该选项的字典时不需要再复制
 }
 
 // New 创建一个新的 Lute 引擎。
@@ -72,12 +83,25 @@ func New(opts ...ParseOption) (ret *Lute) {
 	ret.Md2VditorIRDOMRendererFuncs = map[ast.NodeType]render.ExtRendererFunc{}
 	ret.Md2BlockDOMRendererFuncs = map[ast.NodeType]render.ExtRendererFunc{}
 	ret.Md2VditorSVDOMRendererFuncs = map[ast.NodeType]render.ExtRendererFunc{}
+	ret.ExtRendererFuncs = map[string]map[ast.NodeType]render.ExtRendererFunc{
+		"html":           ret.Md2HTMLRendererFuncs,
+		"vditor-wysiwyg": ret.Md2VditorDOMRendererFuncs,
+		"vditor-ir":      ret.Md2VditorIRDOMRendererFuncs,
+		"vditor-sv":      ret.Md2VditorSVDOMRendererFuncs,
+		"protyle":        ret.Md2BlockDOMRendererFuncs,
+	}
 	return ret
 }
 
 // Markdown 将 markdown 文本字节数组处理为相应的 html 字节数组。name 参数仅用于标识文本，比如可传入 id 或者标题，也可以传入 ""。
 func (lute *Lute) Markdown(name string, markdown []byte) (html []byte) {
-	tree := parse.Parse(name, markdown, lute.ParseOptions)
+	tree := lute.parse(name, markdown)
+	html = lute.renderHTML(tree)
+	return
+}
+
+// renderHTML 将 tree 渲染为 html，Markdown 和 MarkdownE 共用。
+func (lute *Lute) renderHTML(tree *parse.Tree) (html []byte) {
 	renderer := render.NewHtmlRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2HTMLRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -95,7 +119,13 @@ func (lute *Lute) MarkdownStr(name, markdown string) (html string) {
 
 // Format 将 markdown 文本字节数组进行格式化。
 func (lute *Lute) Format(name string, markdown []byte) (formatted []byte) {
-	tree := parse.Parse(name, markdown, lute.ParseOptions)
+	tree := lute.parseEditing(name, markdown)
+	formatted = lute.renderFormat(tree)
+	return
+}
+
+// renderFormat 将 tree 渲染为格式化后的 markdown，Format 和 FormatE 共用。
+func (lute *Lute) renderFormat(tree *parse.Tree) (formatted []byte) {
 	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
 	formatted = renderer.Render()
 	return
@@ -110,7 +140,7 @@ func (lute *Lute) FormatStr(name, markdown string) (formatted string) {
 
 // TextBundle 将 markdown 文本字节数组进行 TextBundle 处理。
 func (lute *Lute) TextBundle(name string, markdown []byte, linkPrefixes []string) (textbundle []byte, originalLinks []string) {
-	tree := parse.Parse(name, markdown, lute.ParseOptions)
+	tree := lute.parseEditing(name, markdown)
 	renderer := render.NewTextBundleRenderer(tree, linkPrefixes, lute.RenderOptions)
 	textbundle, originalLinks = renderer.Render()
 	return
@@ -129,12 +159,73 @@ func (lute *Lute) HTML2Text(dom string) string {
 	if nil == tree {
 		return ""
 	}
+	if lute.RenderOptions.JoinCJKSoftBreak {
+		return tree.Root.PlainText()
+	}
 	return tree.Root.Text()
 }
 
+// Md2Text 将 markdown 渲染为保留段落、列表和表格等结构的纯文本，链接地址作为脚注附加在文末。
+func (lute *Lute) Md2Text(markdown string) (text string) {
+	tree := lute.parse("", []byte(markdown))
+	renderer := render.NewTextRenderer(tree, lute.RenderOptions)
+	text = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Md2ANSI 将 markdown 渲染为使用 ANSI 转义序列着色的终端文本，用于命令行工具输出。
+func (lute *Lute) Md2ANSI(markdown string) (text string) {
+	tree := lute.parse("", []byte(markdown))
+	renderer := render.NewANSIRenderer(tree, lute.RenderOptions)
+	text = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Md2LaTeX 将 markdown 渲染为可以直接编译的 LaTeX 文档。
+func (lute *Lute) Md2LaTeX(markdown string) (latex string) {
+	tree := lute.parse("", []byte(markdown))
+	renderer := render.NewLaTeXRenderer(tree, lute.RenderOptions)
+	latex = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Md2Man 将 markdown 渲染为 man(7) roff 手册页。
+func (lute *Lute) Md2Man(markdown string) (man string) {
+	tree := lute.parse("", []byte(markdown))
+	renderer := render.NewManRenderer(tree, lute.RenderOptions)
+	man = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Md2Confluence 将 markdown 渲染为 Confluence 存储格式（storage format）。
+func (lute *Lute) Md2Confluence(markdown string) (storage string) {
+	tree := lute.parse("", []byte(markdown))
+	renderer := render.NewConfluenceRenderer(tree, lute.RenderOptions)
+	storage = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Md2AsciiDoc 将 markdown 渲染为 AsciiDoc，warnings 为渲染不支持的节点时产生的警告。
+func (lute *Lute) Md2AsciiDoc(markdown string) (asciidoc string, warnings []string) {
+	tree := lute.parse("", []byte(markdown))
+	renderer := render.NewAsciiDocRenderer(tree, lute.RenderOptions).(*render.AsciiDocRenderer)
+	asciidoc = util.BytesToStr(renderer.Render())
+	warnings = renderer.Warnings
+	return
+}
+
+// Md2RST 将 markdown 渲染为 reStructuredText，warnings 为渲染不支持的节点时产生的警告。
+func (lute *Lute) Md2RST(markdown string) (rst string, warnings []string) {
+	tree := lute.parse("", []byte(markdown))
+	renderer := render.NewRSTRenderer(tree, lute.RenderOptions).(*render.RSTRenderer)
+	rst = util.BytesToStr(renderer.Render())
+	warnings = renderer.Warnings
+	return
+}
+
 // RenderJSON 用于渲染 JSON 格式数据。
 func (lute *Lute) RenderJSON(markdown string) (json string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parse("", []byte(markdown))
 	renderer := render.NewJSONRenderer(tree, lute.RenderOptions)
 	output := renderer.Render()
 	json = util.BytesToStr(output)
@@ -177,10 +268,18 @@ func (lute *Lute) GetEmojis() (ret map[string]string) {
 }
 
 // PutEmojis 将指定的 emojiMap 合并覆盖已有的 Emoji 字典。
+//
+// 默认的 Emoji 字典是全局共享的，这里会在第一次写入前复制字典，避免影响其他引擎。
 func (lute *Lute) PutEmojis(emojiMap map[string]string) {
+	options := lute.ParseOptions
+	if lute.emojisOwner != options {
+		options.AliasEmoji = copyStrMap(options.AliasEmoji, len(emojiMap))
+		options.EmojiAlias = copyStrMap(options.EmojiAlias, len(emojiMap))
+		lute.emojisOwner = options
+	}
 	for k, v := range emojiMap {
-		lute.ParseOptions.AliasEmoji[k] = v
-		lute.ParseOptions.EmojiAlias[v] = k
+		options.AliasEmoji[k] = v
+		options.EmojiAlias[v] = k
 	}
 }
 
@@ -198,13 +297,21 @@ func (lute *Lute) GetTerms() map[string]string {
 }
 
 // PutTerms 将制定的 termMap 合并覆盖已有的术语字典。
+//
+// 术语字典可能和配置快照共享，这里会在第一次写入前复制字典。
 func (lute *Lute) PutTerms(termMap map[string]string) {
-	if nil == lute.RenderOptions.Terms {
-		lute.RenderOptions.Terms = render.NewTerms()
+	options := lute.RenderOptions
+	if lute.termsOwner != options {
+		if nil == options.Terms {
+			options.Terms = render.NewTerms()
+		} else {
+			options.Terms = copyStrMap(options.Terms, len(termMap))
+		}
+		lute.termsOwner = options
 	}
 
 	for k, v := range termMap {
-		lute.RenderOptions.Terms[k] = v
+		options.Terms[k] = v
 	}
 }
 
@@ -313,6 +420,7 @@ func (lute *Lute) SetEmoji(b bool) {
 
 func (lute *Lute) SetEmojis(emojis map[string]string) {
 	lute.ParseOptions.AliasEmoji = emojis
+	lute.emojisOwner = nil
 }
 
 func (lute *Lute) SetEmojiSite(emojiSite string) {
@@ -325,6 +433,7 @@ func (lute *Lute) SetHeadingAnchor(b bool) {
 
 func (lute *Lute) SetTerms(terms map[string]string) {
 	lute.RenderOptions.Terms = terms
+	lute.termsOwner = nil
 }
 
 func (lute *Lute) SetVditorWYSIWYG(b bool) {
@@ -474,6 +583,176 @@ func (lute *Lute) SetProtyleMarkNetImg(b bool) {
 	lute.RenderOptions.ProtyleMarkNetImg = b
 }
 
+func (lute *Lute) SetMaxNestingDepth(n int) {
+	lute.ParseOptions.MaxNestingDepth = n
+}
+
+func (lute *Lute) SetMaxDelimiters(n int) {
+	lute.ParseOptions.MaxDelimiters = n
+}
+
+func (lute *Lute) SetMaxInputSize(n int) {
+	lute.ParseOptions.MaxInputSize = n
+}
+
+func (lute *Lute) SetMaxLinkRefDefs(n int) {
+	lute.ParseOptions.MaxLinkRefDefs = n
+}
+
+func (lute *Lute) SetCJKFriendlyEmphasis(b bool) {
+	lute.ParseOptions.CJKFriendlyEmphasis = b
+}
+
+func (lute *Lute) SetJoinCJKSoftBreak(b bool) {
+	lute.RenderOptions.JoinCJKSoftBreak = b
+}
+
+func (lute *Lute) SetGFMTagFilter(b bool) {
+	lute.RenderOptions.GFMTagFilter = b
+}
+
+// SetStrict 设置是否启用严格模式。启用后会关闭所有非 CommonMark/GFM 规范的解析和渲染选项（参考 strictBoolOptions 和 strictStringOptions），
+// GFM 相关选项保持不变；关闭后将这些选项中仍然是严格模式取值的恢复为启用前的值，启用期间通过 Set* 修改过的选项保持修改后的值。
+func (lute *Lute) SetStrict(b bool) {
+	if !b {
+		if nil != lute.strictBools {
+			for i, option := range strictBoolOptions {
+				if option.value == option.get(lute) {
+					option.set(lute, lute.strictBools[i])
+				}
+			}
+			for i, option := range strictStringOptions {
+				if "" == option.get(lute) {
+					option.set(lute, lute.strictStrings[i])
+				}
+			}
+			lute.strictBools, lute.strictStrings = nil, nil
+		}
+		lute.ParseOptions.Strict = false
+		return
+	}
+
+	if !lute.ParseOptions.Strict {
+		lute.strictBools, lute.strictStrings = make([]bool, len(strictBoolOptions)), make([]string, len(strictStringOptions))
+		for i, option := range strictBoolOptions {
+			lute.strictBools[i] = option.get(lute)
+		}
+		for i, option := range strictStringOptions {
+			lute.strictStrings[i] = option.get(lute)
+		}
+	}
+	lute.ParseOptions.Strict = true
+	for _, option := range strictBoolOptions {
+		option.set(lute, option.value)
+	}
+	for _, option := range strictStringOptions {
+		option.set(lute, "")
+	}
+}
+
+// strictBoolOption 描述了严格模式强制设置的布尔选项，value 为严格模式下的值。
+type strictBoolOption struct {
+	get   func(lute *Lute) bool
+	set   func(lute *Lute, b bool)
+	value bool
+}
+
+// strictStringOption 描述了严格模式强制设置的字符串选项，严格模式下的值为空字符串。
+type strictStringOption struct {
+	get func(lute *Lute) string
+	set func(lute *Lute, str string)
+}
+
+var strictBoolOptions = []*strictBoolOption{
+	{func(lute *Lute) bool { return lute.ParseOptions.Footnotes }, (*Lute).SetFootnotes, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.ToC }, (*Lute).SetToC, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.HeadingID }, (*Lute).SetHeadingID, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.Emoji }, (*Lute).SetEmoji, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.YamlFrontMatter }, (*Lute).SetYamlFrontMatter, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.BlockRef }, (*Lute).SetBlockRef, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.FileAnnotationRef }, (*Lute).SetFileAnnotationRef, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.Mark }, (*Lute).SetMark, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.KramdownBlockIAL }, (*Lute).SetKramdownIAL, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.Tag }, (*Lute).SetTag, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.ImgPathAllowSpace }, (*Lute).SetImgPathAllowSpace, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.SuperBlock }, (*Lute).SetSuperBlock, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.Sup }, (*Lute).SetSup, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.Sub }, (*Lute).SetSub, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.GitConflict }, (*Lute).SetGitConflict, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.Setext }, (*Lute).SetSetext, true},
+	{func(lute *Lute) bool { return lute.ParseOptions.LinkRef }, (*Lute).SetLinkRef, true},
+	{func(lute *Lute) bool { return lute.ParseOptions.IndentCodeBlock }, (*Lute).SetIndentCodeBlock, true},
+	{func(lute *Lute) bool { return lute.ParseOptions.ParagraphBeginningSpace }, (*Lute).SetParagraphBeginningSpace, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.CJKFriendlyEmphasis }, (*Lute).SetCJKFriendlyEmphasis, false},
+	{func(lute *Lute) bool { return lute.RenderOptions.JoinCJKSoftBreak }, (*Lute).SetJoinCJKSoftBreak, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.VditorWYSIWYG }, (*Lute).SetVditorWYSIWYG, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.VditorIR }, (*Lute).SetVditorIR, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.VditorSV }, (*Lute).SetVditorSV, false},
+	{func(lute *Lute) bool { return lute.ParseOptions.ProtyleWYSIWYG }, (*Lute).SetProtyleWYSIWYG, false},
+	{func(lute *Lute) bool { return lute.RenderOptions.SoftBreak2HardBreak }, (*Lute).SetSoftBreak2HardBreak, false},
+	{func(lute *Lute) bool { return lute.RenderOptions.CodeSyntaxHighlight }, (*Lute).SetCodeSyntaxHighlight, false},
+	{func(lute *Lute) bool { return lute.RenderOptions.AutoSpace }, (*Lute).SetAutoSpace, false},
+	{func(lute *Lute) bool { return lute.RenderOptions.FixTermTypo }, (*Lute).SetFixTermTypo, false},
+	{func(lute *Lute) bool { return lute.RenderOptions.ChineseParagraphBeginningSpace }, (*Lute).SetChineseParagraphBeginningSpace, false},
+	{func(lute *Lute) bool { return lute.RenderOptions.RenderListStyle }, (*Lute).SetRenderListStyle, false},
+	{func(lute *Lute) bool { return lute.RenderOptions.HeadingAnchor }, (*Lute).SetHeadingAnchor, false},
+}
+
+var strictStringOptions = []*strictStringOption{
+	{func(lute *Lute) string { return lute.RenderOptions.ImageLazyLoading }, (*Lute).SetImageLazyLoading},
+	{func(lute *Lute) string { return lute.RenderOptions.LinkBase }, (*Lute).SetLinkBase},
+	{func(lute *Lute) string { return lute.RenderOptions.LinkPrefix }, (*Lute).SetLinkPrefix},
+	{func(lute *Lute) string { return lute.RenderOptions.GFMTaskListItemClass }, (*Lute).SetGFMTaskListItemClass},
+}
+
+func (lute *Lute) SetTextWrapWidth(width int) {
+	lute.RenderOptions.TextWrapWidth = width
+}
+
+func (lute *Lute) SetANSITrueColor(b bool) {
+	lute.RenderOptions.ANSITrueColor = b
+}
+
+func (lute *Lute) SetLaTeXDocumentClass(class string) {
+	lute.RenderOptions.LaTeXDocumentClass = class
+}
+
+func (lute *Lute) SetLaTeXDocumentClassOptions(options string) {
+	lute.RenderOptions.LaTeXDocumentClassOptions = options
+}
+
+func (lute *Lute) SetLaTeXPreamble(preamble string) {
+	lute.RenderOptions.LaTeXPreamble = preamble
+}
+
+func (lute *Lute) SetLaTeXXeCJK(b bool) {
+	lute.RenderOptions.LaTeXXeCJK = b
+}
+
+func (lute *Lute) SetLaTeXMinted(b bool) {
+	lute.RenderOptions.LaTeXMinted = b
+}
+
+func (lute *Lute) SetLaTeXLongTable(b bool) {
+	lute.RenderOptions.LaTeXLongTable = b
+}
+
+func (lute *Lute) SetDocxResourceDir(dir string) {
+	lute.RenderOptions.DocxResourceDir = dir
+}
+
+func (lute *Lute) SetEPUBSplitHeading(b bool) {
+	lute.RenderOptions.EPUBSplitHeading = b
+}
+
+func (lute *Lute) SetEPUBCSS(css string) {
+	lute.RenderOptions.EPUBCSS = css
+}
+
+func (lute *Lute) SetValidateTree(b bool) {
+	lute.ValidateTree = b
+}
+
 func (lute *Lute) SetJSRenderers(options map[string]map[string]*js.Object) {
 	for rendererType, extRenderer := range options["renderers"] {
 		switch extRenderer.Interface().(type) { // 稍微进行一点格式校验
@@ -504,6 +783,12 @@ func (lute *Lute) SetJSRenderers(options map[string]map[string]*js.Object) {
 			rendererFuncs = lute.Md2BlockDOMRendererFuncs
 		} else if "Md2VditorSVDOM" == rendererType {
 			rendererFuncs = lute.Md2VditorSVDOMRendererFuncs
+		} else if nil != render.Lookup(rendererType) {
+			// 通过 render.Register 注册的输出格式，用于 Render
+			if rendererFuncs = lute.ExtRendererFuncs[rendererType]; nil == rendererFuncs {
+				rendererFuncs = map[ast.NodeType]render.ExtRendererFunc{}
+				lute.ExtRendererFuncs[rendererType] = rendererFuncs
+			}
 		} else {
 			panic("unknown ext renderer func [" + rendererType + "]")
 		}
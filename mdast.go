@@ -0,0 +1,58 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
+)
+
+// Md2Mdast 将 markdown 转换为 mdast（https://github.com/syntax-tree/mdast）JSON。
+func (lute *Lute) Md2Mdast(markdown string) (mdast string) {
+	tree := lute.parse("", []byte(markdown))
+	mdast = lute.Tree2Mdast(tree)
+	return
+}
+
+// Tree2Mdast 将 tree 渲染为 mdast JSON。
+func (lute *Lute) Tree2Mdast(tree *parse.Tree) (mdast string) {
+	renderer := render.NewMdastRenderer(tree, lute.RenderOptions)
+	mdast = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Mdast2Tree 将 mdast JSON 转换为语法树，比如 remark 插件处理后的结果可以转换为语法树后使用 Lute 的渲染器进行渲染。
+func (lute *Lute) Mdast2Tree(mdast string) (tree *parse.Tree, err error) {
+	return parse.ParseMdast("", []byte(mdast), lute.ParseOptions)
+}
+
+// Mdast2HTML 将 mdast JSON 渲染为 HTML。
+func (lute *Lute) Mdast2HTML(mdast string) (html string, err error) {
+	tree, err := lute.Mdast2Tree(mdast)
+	if nil != err {
+		return
+	}
+	renderer := render.NewHtmlRenderer(tree, lute.RenderOptions)
+	html = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Mdast2Md 将 mdast JSON 格式化为 markdown。
+func (lute *Lute) Mdast2Md(mdast string) (markdown string, err error) {
+	tree, err := lute.Mdast2Tree(mdast)
+	if nil != err {
+		return
+	}
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
@@ -0,0 +1,256 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
+)
+
+// mergeBlock 描述了三方合并时参与合并的顶层块。
+type mergeBlock struct {
+	content string     // 块的 Markdown 内容，不包括块级内联属性列表
+	ial     [][]string // 块级内联属性列表
+}
+
+// Merge 以 base 为共同祖先三方合并语法树 ours 和 theirs，conflicts 为冲突的块数。
+//
+// 顶层块按照块级内联属性列表中的 id 进行匹配（没有 id 的块按照内容、相似度和位置匹配 base 中的块）：仅一方修改或删除的块采用该方的结果，双方都修改的块
+// 按词合并文本，合并失败或者一方修改另一方删除时生成 Git 冲突标记块（ours 在前，theirs 在后），块的顺序以 ours 为准。
+func (lute *Lute) Merge(base, ours, theirs *parse.Tree) (ret *parse.Tree, conflicts int) {
+	baseKeys := mergeBaseKeys(base)
+	baseBlocks, _ := lute.mergeBlocks(base, baseKeys)
+	oursBlocks, oursKeys := lute.mergeBlocks(ours, mergeMatches(base, ours, baseKeys))
+	theirsBlocks, theirsKeys := lute.mergeBlocks(theirs, mergeMatches(base, theirs, baseKeys))
+
+	// theirs 新增的块插入到 theirs 中它前面的最近一个块之后
+	keys := append([]string{}, oursKeys...)
+	for i, key := range theirsKeys {
+		if _, ok := oursBlocks[key]; ok {
+			continue
+		}
+		pos := 0
+		for j := i - 1; 0 <= j && 0 == pos; j-- {
+			for k, existing := range keys {
+				if existing == theirsKeys[j] {
+					pos = k + 1
+					break
+				}
+			}
+		}
+		keys = append(keys[:pos], append([]string{key}, keys[pos:]...)...)
+	}
+
+	var blocks []string
+	for _, key := range keys {
+		b, o, t := baseBlocks[key], oursBlocks[key], theirsBlocks[key]
+		if nil == o || nil == t {
+			if nil != b && (nil == o || o.content == b.content) && (nil == t || t.content == b.content) {
+				// 一方删除，另一方未修改
+				continue
+			}
+			if nil == b {
+				// 一方新增
+				if nil == o {
+					o = t
+				}
+				blocks = append(blocks, o.markdown())
+				continue
+			}
+
+			// 一方修改，另一方删除
+			blocks = append(blocks, mergeConflict(o, t))
+			conflicts++
+			continue
+		}
+
+		baseContent, baseIAL := "", [][]string{}
+		if nil != b {
+			baseContent, baseIAL = b.content, b.ial
+		}
+		content, ok := parse.MergeText(baseContent, o.content, t.content)
+		if !ok {
+			blocks = append(blocks, mergeConflict(o, t))
+			conflicts++
+			continue
+		}
+		merged := &mergeBlock{content: content, ial: mergeIAL(baseIAL, o.ial, t.ial)}
+		blocks = append(blocks, merged.markdown())
+	}
+
+	if docIAL := mergeDocIAL(base, ours, theirs); nil != docIAL {
+		blocks = append(blocks, util.BytesToStr(parse.IAL2Tokens(docIAL)))
+	}
+
+	options := *lute.ParseOptions
+	options.GitConflict = true
+	ret = parse.Parse("", []byte(strings.Join(blocks, "\n\n")), &options)
+	return
+}
+
+// MergeMarkdown 以 baseMarkdown 为共同祖先三方合并 oursMarkdown 和 theirsMarkdown，返回格式化后的合并结果，conflicts 为冲突的块数。
+func (lute *Lute) MergeMarkdown(baseMarkdown, oursMarkdown, theirsMarkdown string) (markdown string, conflicts int) {
+	tree, conflicts := lute.Merge(lute.parseEditing("", []byte(baseMarkdown)), lute.parseEditing("", []byte(oursMarkdown)), lute.parseEditing("", []byte(theirsMarkdown)))
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
+
+// mergeBlocks 返回 tree 中参与合并的顶层块，以及按顺序排列的块键。没有 id 的块使用 matches 中匹配的 base 块的键。
+func (lute *Lute) mergeBlocks(tree *parse.Tree, matches map[*ast.Node]string) (blocks map[string]*mergeBlock, keys []string) {
+	blocks = map[string]*mergeBlock{}
+	for c := tree.Root.FirstChild; nil != c; c = c.Next {
+		if ast.NodeKramdownBlockIAL == c.Type {
+			continue
+		}
+
+		block := &mergeBlock{content: FormatNode(c, lute.ParseOptions, lute.RenderOptions), ial: c.KramdownIAL}
+		key := c.ID
+		if "" == key {
+			key = matches[c]
+		}
+		if "" == key {
+			// 没有匹配的块使用内容作为键，双方新增相同内容的块时合并为一个，相同内容的块按出现次序区分
+			key = "content:" + block.content
+			for i := 1; nil != blocks[key]; i++ {
+				key = "content:" + strconv.Itoa(i) + ":" + block.content
+			}
+		}
+		blocks[key] = block
+		keys = append(keys, key)
+	}
+	return
+}
+
+// mergeBaseKeys 返回 base 中没有 id 的顶层块的键。
+func mergeBaseKeys(base *parse.Tree) (ret map[*ast.Node]string) {
+	ret = map[*ast.Node]string{}
+	i := 0
+	for c := base.Root.FirstChild; nil != c; c = c.Next {
+		if ast.NodeKramdownBlockIAL == c.Type {
+			continue
+		}
+		if "" == c.ID {
+			ret[c] = "base:" + strconv.Itoa(i)
+		}
+		i++
+	}
+	return
+}
+
+// mergeMatches 将 tree 中没有 id 的顶层块和 base 中没有 id 的顶层块进行匹配，返回匹配的块对应的 base 块键。
+//
+// 块先按照内容和相似度匹配（参考 parse.Diff），仍未匹配的新增块和同一间隔中被删除的同类型 base 块按位置匹配。
+func mergeMatches(base, tree *parse.Tree, baseKeys map[*ast.Node]string) (ret map[*ast.Node]string) {
+	ret = map[*ast.Node]string{}
+	match := func(old, new *ast.Node) {
+		if key, ok := baseKeys[old]; ok && "" == new.ID {
+			ret[new] = key
+		}
+	}
+
+	var deleted []*ast.Node // 当前间隔中被删除的 base 块
+	for _, op := range parse.Diff(base, tree).Ops {
+		switch op.Type {
+		case parse.DiffDelete:
+			deleted = append(deleted, op.Old)
+		case parse.DiffInsert:
+			for i, old := range deleted {
+				if old.Type == op.New.Type {
+					match(old, op.New)
+					deleted = append(deleted[:i], deleted[i+1:]...)
+					break
+				}
+			}
+		case parse.DiffMove:
+			match(op.Old, op.New)
+		default:
+			match(op.Old, op.New)
+			deleted = nil
+		}
+	}
+	return
+}
+
+func (block *mergeBlock) markdown() string {
+	if 1 > len(block.ial) {
+		return block.content
+	}
+	return block.content + "\n" + util.BytesToStr(parse.IAL2Tokens(block.ial))
+}
+
+// mergeConflict 返回 ours 和 theirs 冲突的 Git 冲突标记块，被删除的一方为 nil。
+func mergeConflict(ours, theirs *mergeBlock) string {
+	buf := &strings.Builder{}
+	buf.WriteString("<<<<<<< ours\n")
+	if nil != ours {
+		buf.WriteString(ours.markdown() + "\n")
+	}
+	buf.WriteString("=======\n")
+	if nil != theirs {
+		buf.WriteString(theirs.markdown() + "\n")
+	}
+	buf.WriteString(">>>>>>> theirs")
+	return buf.String()
+}
+
+// mergeDocIAL 三方合并文档级内联属性列表，ours 和 theirs 都没有文档级内联属性列表时返回 nil。
+func mergeDocIAL(base, ours, theirs *parse.Tree) [][]string {
+	docIAL := func(tree *parse.Tree) [][]string {
+		if last := tree.Root.LastChild; nil != last && ast.NodeKramdownBlockIAL == last.Type && util.IsDocIAL(last.Tokens) {
+			return parse.Tokens2IAL(last.Tokens)
+		}
+		return nil
+	}
+	o, t := docIAL(ours), docIAL(theirs)
+	if nil == o && nil == t {
+		return nil
+	}
+	return mergeIAL(docIAL(base), o, t)
+}
+
+// mergeIAL 按属性三方合并内联属性列表，双方修改了同一个属性并且值不同时采用 ours 的值。
+func mergeIAL(base, ours, theirs [][]string) (ret [][]string) {
+	baseMap, oursMap, theirsMap := ialMap(base), ialMap(ours), ialMap(theirs)
+	merged := map[string]bool{}
+	for _, kv := range append(append([][]string{}, ours...), theirs...) {
+		name := kv[0]
+		if merged[name] {
+			continue
+		}
+		merged[name] = true
+
+		baseVal, inBase := baseMap[name]
+		val, ok := oursMap[name]
+		theirsVal, inTheirs := theirsMap[name]
+		if ok == inBase && val == baseVal {
+			// ours 未修改该属性时采用 theirs 的修改
+			val, ok = theirsVal, inTheirs
+		}
+		if ok {
+			ret = append(ret, []string{name, val})
+		}
+	}
+	return
+}
+
+func ialMap(ial [][]string) (ret map[string]string) {
+	ret = map[string]string{}
+	for _, kv := range ial {
+		ret[kv[0]] = kv[1]
+	}
+	return
+}
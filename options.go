@@ -0,0 +1,80 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"encoding/json"
+
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"gopkg.in/yaml.v3"
+)
+
+// Options 描述了引擎选项配置，由预设名称、解析选项和渲染选项组合而成，用于 JSON 和 YAML 序列化。
+//
+// 反序列化时先应用 Preset 预设，然后使用 Parse 和 Render 中出现的字段覆盖预设，字段名和结构体字段名一致（不区分大小写）。
+// Emoji 字典不参与序列化。
+type Options struct {
+	Preset string          `json:"preset,omitempty"`
+	Parse  *parse.Options  `json:"parse"`
+	Render *render.Options `json:"render"`
+}
+
+// MarshalOptionsJSON 将引擎当前的解析选项和渲染选项序列化为 JSON。
+func (lute *Lute) MarshalOptionsJSON() ([]byte, error) {
+	return json.MarshalIndent(&Options{Parse: lute.ParseOptions, Render: lute.RenderOptions}, "", "  ")
+}
+
+// MarshalOptionsYAML 将引擎当前的解析选项和渲染选项序列化为 YAML。
+func (lute *Lute) MarshalOptionsYAML() ([]byte, error) {
+	data, err := json.Marshal(&Options{Parse: lute.ParseOptions, Render: lute.RenderOptions})
+	if nil != err {
+		return nil, err
+	}
+
+	// 通过 JSON 中转，保证 YAML 和 JSON 使用相同的字段名
+	var m map[string]interface{}
+	if err = json.Unmarshal(data, &m); nil != err {
+		return nil, err
+	}
+	return yaml.Marshal(m)
+}
+
+// NewWithOptionsJSON 使用 JSON 格式的引擎选项配置 data 创建一个新的 Lute 引擎。
+func NewWithOptionsJSON(data []byte) (ret *Lute, err error) {
+	preset := &Options{}
+	if err = json.Unmarshal(data, preset); nil != err {
+		return
+	}
+
+	if "" == preset.Preset {
+		ret = New()
+	} else if ret, err = NewWithPreset(preset.Preset); nil != err {
+		return
+	}
+	if err = json.Unmarshal(data, &Options{Parse: ret.ParseOptions, Render: ret.RenderOptions}); nil != err {
+		ret = nil
+	}
+	return
+}
+
+// NewWithOptionsYAML 使用 YAML 格式的引擎选项配置 data 创建一个新的 Lute 引擎。
+func NewWithOptionsYAML(data []byte) (ret *Lute, err error) {
+	var m map[string]interface{}
+	if err = yaml.Unmarshal(data, &m); nil != err {
+		return
+	}
+
+	if data, err = json.Marshal(m); nil != err {
+		return
+	}
+	return NewWithOptionsJSON(data)
+}
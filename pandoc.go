@@ -0,0 +1,58 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
+)
+
+// Md2PandocJSON 将 markdown 转换为 Pandoc JSON AST（pandoc -t json）。
+func (lute *Lute) Md2PandocJSON(markdown string) (pandocJSON string) {
+	tree := lute.parse("", []byte(markdown))
+	pandocJSON = lute.Tree2PandocJSON(tree)
+	return
+}
+
+// Tree2PandocJSON 将 tree 渲染为 Pandoc JSON AST。
+func (lute *Lute) Tree2PandocJSON(tree *parse.Tree) (pandocJSON string) {
+	renderer := render.NewPandocJSONRenderer(tree, lute.RenderOptions)
+	pandocJSON = util.BytesToStr(renderer.Render())
+	return
+}
+
+// PandocJSON2Tree 将 Pandoc JSON AST 转换为语法树，比如 Pandoc 过滤器（pandoc --filter）处理后的结果可以转换为语法树后使用 Lute 的渲染器进行渲染。
+func (lute *Lute) PandocJSON2Tree(pandocJSON string) (tree *parse.Tree, err error) {
+	return parse.ParsePandocJSON("", []byte(pandocJSON), lute.ParseOptions)
+}
+
+// PandocJSON2HTML 将 Pandoc JSON AST 渲染为 HTML。
+func (lute *Lute) PandocJSON2HTML(pandocJSON string) (html string, err error) {
+	tree, err := lute.PandocJSON2Tree(pandocJSON)
+	if nil != err {
+		return
+	}
+	renderer := render.NewHtmlRenderer(tree, lute.RenderOptions)
+	html = util.BytesToStr(renderer.Render())
+	return
+}
+
+// PandocJSON2Md 将 Pandoc JSON AST 格式化为 markdown。
+func (lute *Lute) PandocJSON2Md(pandocJSON string) (markdown string, err error) {
+	tree, err := lute.PandocJSON2Tree(pandocJSON)
+	if nil != err {
+		return
+	}
+	renderer := render.NewFormatRenderer(tree, lute.RenderOptions)
+	markdown = util.BytesToStr(renderer.Render())
+	return
+}
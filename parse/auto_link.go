@@ -427,7 +427,9 @@ func (t *Tree) isValidDomain(domain []byte) bool {
 					break
 				}
 			}
-			if !suffixIsDigit { // 如果后缀不是数字的话检查是否在后缀可用名单中
+			if t.Context.ParseOption.Strict { // 严格模式下不检查后缀
+				validSuffix = true
+			} else if !suffixIsDigit { // 如果后缀不是数字的话检查是否在后缀可用名单中
 				for j := 0; j < len(validAutoLinkDomainSuffix); j++ {
 					if bytes.Equal(segment, validAutoLinkDomainSuffix[j]) {
 						validSuffix = true
@@ -26,6 +26,10 @@ func BlockquoteStart(t *Tree, container *ast.Node) int {
 		return 0
 	}
 
+	if t.Context.exceedNestingDepth(container) {
+		return 0
+	}
+
 	markers := []byte{marker}
 	t.Context.advanceNextNonspace()
 	t.Context.advanceOffset(1, false)
@@ -39,6 +39,11 @@ func (t *Tree) parseBlocks() {
 
 		t.incorporateLine(line)
 		lines++
+		if t.Context.canceled() {
+			// 解析已经被取消，剩余的内容不再进行解析
+			t.Context.raw = append(t.lexer.Remains(), t.Context.raw...)
+			break
+		}
 	}
 	for nil != t.Context.Tip {
 		t.Context.finalize(t.Context.Tip)
@@ -47,8 +47,8 @@ func (t *Tree) handleDelim(block *ast.Node, ctx *InlineContext) {
 	node := &ast.Node{Type: ast.NodeText, Tokens: text}
 	block.AppendChild(node)
 
-	// 将这个分隔符入栈
-	if delim.canOpen || delim.canClose {
+	// 将这个分隔符入栈，分隔符栈满的话作为文本
+	if (delim.canOpen || delim.canClose) && !t.Context.exceedDelimiters(ctx.delimitersLen) {
 		ctx.delimiters = &delimiter{
 			typ:         delim.typ,
 			num:         delim.num,
@@ -62,6 +62,7 @@ func (t *Tree) handleDelim(block *ast.Node, ctx *InlineContext) {
 		if nil != ctx.delimiters.previous {
 			ctx.delimiters.previous.next = ctx.delimiters
 		}
+		ctx.delimitersLen++
 	}
 }
 
@@ -256,6 +257,9 @@ func (t *Tree) processEmphasis(stackBottom *delimiter, ctx *InlineContext) {
 
 			// remove elts between opener and closer in delimiters stack
 			if opener.next != closer {
+				for d := opener.next; d != closer; d = d.next {
+					ctx.delimitersLen--
+				}
 				opener.next = closer
 				closer.previous = opener
 			}
@@ -334,16 +338,16 @@ func (t *Tree) scanDelims(ctx *InlineContext) *delimiter {
 
 	afterIsWhitespace := lex.IsUnicodeWhitespace(tokenAfter)
 	afterIsPunct := unicode.IsPunct(tokenAfter) || unicode.IsSymbol(tokenAfter)
-	if (lex.ItemAsterisk == token && '~' == tokenAfter) || (lex.ItemTilde == token && '*' == tokenAfter) ||
+	if !t.Context.ParseOption.Strict && ((lex.ItemAsterisk == token && '~' == tokenAfter) || (lex.ItemTilde == token && '*' == tokenAfter) ||
 		(lex.ItemCaret == token && ('+' == tokenAfter || '-' == tokenAfter)) ||
-		(lex.ItemTilde == token && ('+' == tokenAfter || '-' == tokenAfter)) {
+		(lex.ItemTilde == token && ('+' == tokenAfter || '-' == tokenAfter))) {
 		afterIsPunct = false
 	}
 	beforeIsWhitespace := lex.IsUnicodeWhitespace(tokenBefore)
 	beforeIsPunct := unicode.IsPunct(tokenBefore) || unicode.IsSymbol(tokenBefore)
-	if (lex.ItemAsterisk == token && '~' == tokenBefore) || (lex.ItemTilde == token && '*' == tokenBefore) ||
+	if !t.Context.ParseOption.Strict && ((lex.ItemAsterisk == token && '~' == tokenBefore) || (lex.ItemTilde == token && '*' == tokenBefore) ||
 		(lex.ItemCaret == token && ('+' == tokenBefore || '-' == tokenBefore)) ||
-		(lex.ItemTilde == token && ('+' == tokenBefore || '-' == tokenBefore)) {
+		(lex.ItemTilde == token && ('+' == tokenBefore || '-' == tokenBefore))) {
 		beforeIsPunct = false
 	}
 
@@ -354,6 +358,13 @@ func (t *Tree) scanDelims(ctx *InlineContext) *delimiter {
 
 	isLeftFlanking := !afterIsWhitespace && (!afterIsPunct || beforeIsWhitespace || beforeIsPunct)
 	isRightFlanking := !beforeIsWhitespace && (!beforeIsPunct || afterIsWhitespace || afterIsPunct)
+	if t.Context.ParseOption.CJKFriendlyEmphasis {
+		// 标点符号和中日韩字符相邻时不作为单词边界，比如 **加粗：**后面的文字、**「引用」**测试
+		// https://github.com/tats-u/markdown-cjk-friendly
+		beforeIsCJK, afterIsCJK := lex.IsCJK(tokenBefore), lex.IsCJK(tokenAfter)
+		isLeftFlanking = isLeftFlanking || (!afterIsWhitespace && afterIsPunct && (beforeIsCJK || afterIsCJK))
+		isRightFlanking = isRightFlanking || (!beforeIsWhitespace && beforeIsPunct && (afterIsCJK || beforeIsCJK))
+	}
 	var canOpen, canClose bool
 	if lex.ItemUnderscore == token {
 		canOpen = isLeftFlanking && (!isRightFlanking || beforeIsPunct)
@@ -385,6 +396,7 @@ func (t *Tree) scanDelims(ctx *InlineContext) *delimiter {
 }
 
 func (t *Tree) removeDelimiter(delim *delimiter, ctx *InlineContext) (ret *delimiter) {
+	ctx.delimitersLen--
 	if nil != delim.previous {
 		delim.previous.next = delim.next
 	}
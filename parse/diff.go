@@ -0,0 +1,425 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+)
+
+// DiffType 描述了差异类型。
+type DiffType int
+
+const (
+	DiffEqual  DiffType = iota // 未变化
+	DiffInsert                 // 新增
+	DiffDelete                 // 删除
+	DiffUpdate                 // 内容修改
+	DiffMove                   // 位置移动，内容也可能修改
+)
+
+func (t DiffType) String() string {
+	switch t {
+	case DiffInsert:
+		return "insert"
+	case DiffDelete:
+		return "delete"
+	case DiffUpdate:
+		return "update"
+	case DiffMove:
+		return "move"
+	}
+	return "equal"
+}
+
+// TreeDiff 描述了两棵语法树之间的块级差异。
+type TreeDiff struct {
+	OldTree, NewTree *Tree
+	Ops              []*DiffOp // 按照合并后的文档顺序排列的所有块级操作，包括未变化的块，删除的块位于其原位置
+}
+
+// DiffOp 描述了一个块级操作。
+type DiffOp struct {
+	Type DiffType
+	Old  *ast.Node   // 旧树中的块，新增时为 nil
+	New  *ast.Node   // 新树中的块，删除时为 nil
+	Text []*TextDiff // 块内文本差异，仅在内容修改（包括移动并修改）时不为 nil
+}
+
+// TextDiff 描述了块内的一段文本差异，Type 为 DiffEqual、DiffInsert 或者 DiffDelete。
+type TextDiff struct {
+	Type DiffType
+	Text string
+}
+
+// Changes 返回所有有变化的块级操作。
+func (d *TreeDiff) Changes() (ret []*DiffOp) {
+	for _, op := range d.Ops {
+		if DiffEqual != op.Type {
+			ret = append(ret, op)
+		}
+	}
+	return
+}
+
+// diffSimilarityThreshold 是按照内容匹配块时要求的最低相似度。
+const diffSimilarityThreshold = 0.5
+
+// Diff 比较 oldTree 和 newTree 的顶层块。
+//
+// 块的匹配规则：先按照块级内联属性列表中的 id 匹配，然后按照内容匹配，内容相同优先，否则匹配相同类型中相似度最高（不低于 0.5）的块。
+// 匹配的块如果相对顺序发生变化则为移动，内容不同则为修改并计算块内文本差异，未匹配的旧块为删除，未匹配的新块为新增。
+func Diff(oldTree, newTree *Tree) (ret *TreeDiff) {
+	ret = &TreeDiff{OldTree: oldTree, NewTree: newTree}
+	oldBlocks, newBlocks := diffBlocks(oldTree), diffBlocks(newTree)
+	oldContents, newContents := make([]string, len(oldBlocks)), make([]string, len(newBlocks))
+	for i, b := range oldBlocks {
+		oldContents[i] = diffBlockContent(b)
+	}
+	for i, b := range newBlocks {
+		newContents[i] = diffBlockContent(b)
+	}
+
+	// matches[newIndex] = oldIndex，未匹配为 -1
+	matches := make([]int, len(newBlocks))
+	matched := make([]bool, len(oldBlocks))
+	for i := range matches {
+		matches[i] = -1
+	}
+	match := func(newIdx, oldIdx int) {
+		matches[newIdx] = oldIdx
+		matched[oldIdx] = true
+	}
+
+	oldIDs := map[string]int{}
+	for i, b := range oldBlocks {
+		if id := b.IALAttr("id"); "" != id {
+			oldIDs[id] = i
+		}
+	}
+	for i, b := range newBlocks {
+		if oldIdx, ok := oldIDs[b.IALAttr("id")]; ok && "" != b.IALAttr("id") && !matched[oldIdx] {
+			match(i, oldIdx)
+		}
+	}
+
+	for i, b := range newBlocks {
+		if -1 != matches[i] {
+			continue
+		}
+		for j, o := range oldBlocks {
+			if !matched[j] && b.Type == o.Type && newContents[i] == oldContents[j] {
+				match(i, j)
+				break
+			}
+		}
+	}
+
+	oldTokens := make([][]string, len(oldBlocks))
+	for i, b := range newBlocks {
+		if -1 != matches[i] {
+			continue
+		}
+		newTokens := diffTokens(newContents[i])
+		best, bestSimilarity := -1, diffSimilarityThreshold
+		for j, o := range oldBlocks {
+			if matched[j] || b.Type != o.Type {
+				continue
+			}
+			if nil == oldTokens[j] {
+				oldTokens[j] = diffTokens(oldContents[j])
+			}
+			if similarity := diffSimilarity(oldTokens[j], newTokens); similarity >= bestSimilarity {
+				best, bestSimilarity = j, similarity
+			}
+		}
+		if -1 != best {
+			match(i, best)
+		}
+	}
+
+	// 匹配块中旧序号的最长递增子序列保持相对顺序，其余的匹配块为移动
+	inOrder := diffLIS(matches)
+	oldIdx := 0
+	flushDeleted := func(until int) {
+		for ; oldIdx < until; oldIdx++ {
+			if !matched[oldIdx] {
+				ret.Ops = append(ret.Ops, &DiffOp{Type: DiffDelete, Old: oldBlocks[oldIdx]})
+			}
+		}
+	}
+	// nextAnchors[i] 为新块 i 之后（包括 i）第一个保持相对顺序的块对应的旧序号，删除的块位于同一间隔中的新增和移动的块之前
+	nextAnchors := make([]int, len(newBlocks)+1)
+	nextAnchors[len(newBlocks)] = len(oldBlocks)
+	for i := len(newBlocks) - 1; 0 <= i; i-- {
+		nextAnchors[i] = nextAnchors[i+1]
+		if inOrder[i] {
+			nextAnchors[i] = matches[i]
+		}
+	}
+	for i, b := range newBlocks {
+		j := matches[i]
+		if -1 == j {
+			flushDeleted(nextAnchors[i])
+			ret.Ops = append(ret.Ops, &DiffOp{Type: DiffInsert, New: b})
+			continue
+		}
+
+		op := &DiffOp{Type: DiffEqual, Old: oldBlocks[j], New: b}
+		if inOrder[i] {
+			flushDeleted(j + 1)
+		} else {
+			flushDeleted(nextAnchors[i])
+			op.Type = DiffMove
+		}
+		if oldContents[j] != newContents[i] {
+			if DiffEqual == op.Type {
+				op.Type = DiffUpdate
+			}
+			op.Text = diffTextTokens(diffBlockTokens(op.Old), diffBlockTokens(op.New))
+		}
+		ret.Ops = append(ret.Ops, op)
+	}
+	flushDeleted(len(oldBlocks))
+	return
+}
+
+// DiffText 按照词（连续的字母数字，其他字符单独作为一个词）比较文本 oldText 和 newText。
+func DiffText(oldText, newText string) (ret []*TextDiff) {
+	return diffTextTokens(diffTokens(oldText), diffTokens(newText))
+}
+
+// diffTextTokens 比较词序列 a 和 b，返回文本差异。
+func diffTextTokens(a, b []string) (ret []*TextDiff) {
+	ret = []*TextDiff{}
+	lcs := diffLCSTable(a, b)
+	appendDiff := func(typ DiffType, text string) {
+		if last := len(ret) - 1; 0 <= last && typ == ret[last].Type {
+			ret[last].Text += text
+			return
+		}
+		ret = append(ret, &TextDiff{Type: typ, Text: text})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			appendDiff(DiffEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			appendDiff(DiffDelete, a[i])
+			i++
+		default:
+			appendDiff(DiffInsert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		appendDiff(DiffDelete, a[i])
+	}
+	for ; j < len(b); j++ {
+		appendDiff(DiffInsert, b[j])
+	}
+	return cleanupTextDiffs(ret)
+}
+
+// cleanupTextDiffs 将两段差异之间只包含空白的未变化文本并入差异，并将连续的差异合并为一段删除和一段新增，使差异更易读。
+func cleanupTextDiffs(diffs []*TextDiff) (ret []*TextDiff) {
+	ret = []*TextDiff{}
+	var deleted, inserted string
+	flush := func() {
+		if "" != deleted {
+			ret = append(ret, &TextDiff{Type: DiffDelete, Text: deleted})
+		}
+		if "" != inserted {
+			ret = append(ret, &TextDiff{Type: DiffInsert, Text: inserted})
+		}
+		deleted, inserted = "", ""
+	}
+	for i, diff := range diffs {
+		switch diff.Type {
+		case DiffDelete:
+			deleted += diff.Text
+		case DiffInsert:
+			inserted += diff.Text
+		default:
+			if 0 < i && i < len(diffs)-1 && "" == strings.TrimSpace(diff.Text) {
+				deleted += diff.Text
+				inserted += diff.Text
+				continue
+			}
+			flush()
+			ret = append(ret, diff)
+		}
+	}
+	flush()
+	return
+}
+
+// DiffBlockText 返回块 block 中参与文本差异比较的文本，即文本节点、链接文本节点和 HTML 实体节点（不包括图片替代文本）按顺序拼接的结果。
+func DiffBlockText(block *ast.Node) string {
+	buf := &bytes.Buffer{}
+	walkDiffText(block, func(n *ast.Node) {
+		buf.Write(n.Tokens)
+	})
+	return buf.String()
+}
+
+// diffBlockTokens 返回块 block 中参与文本差异比较的词，每个文本节点单独切分，HTML 实体作为一个词，使节点边界（比如表格单元格之间）总是词的边界。
+func diffBlockTokens(block *ast.Node) (ret []string) {
+	ret = []string{}
+	walkDiffText(block, func(n *ast.Node) {
+		if ast.NodeHTMLEntity == n.Type {
+			ret = append(ret, string(n.Tokens))
+			return
+		}
+		ret = append(ret, diffTokens(string(n.Tokens))...)
+	})
+	return
+}
+
+// walkDiffText 按顺序对块 block 中参与文本差异比较的节点调用 f。
+func walkDiffText(block *ast.Node, f func(n *ast.Node)) {
+	ast.Walk(block, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		switch n.Type {
+		case ast.NodeImage:
+			return ast.WalkSkipChildren
+		case ast.NodeText, ast.NodeLinkText, ast.NodeHTMLEntity:
+			f(n)
+		}
+		return ast.WalkContinue
+	})
+}
+
+// diffBlocks 返回 tree 中参与比较的顶层块，块级内联属性列表节点不参与比较。
+func diffBlocks(tree *Tree) (ret []*ast.Node) {
+	for c := tree.Root.FirstChild; nil != c; c = c.Next {
+		if ast.NodeKramdownBlockIAL != c.Type {
+			ret = append(ret, c)
+		}
+	}
+	return
+}
+
+// diffBlockContent 返回块 block 的内容，即所有叶子节点 Tokens 使用换行分隔拼接的结果，分隔后相邻节点（比如表格单元格）的内容不会连成一个词。
+func diffBlockContent(block *ast.Node) string {
+	buf := &bytes.Buffer{}
+	ast.Walk(block, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering && nil == n.FirstChild && ast.NodeKramdownBlockIAL != n.Type && ast.NodeKramdownSpanIAL != n.Type {
+			if 0 < buf.Len() {
+				buf.WriteByte('\n')
+			}
+			buf.Write(n.Tokens)
+		}
+		return ast.WalkContinue
+	})
+	return buf.String()
+}
+
+// diffTokens 将 text 切分为词，连续的字母数字作为一个词，其他字符（包括中日韩文字）单独作为一个词。
+func diffTokens(text string) (ret []string) {
+	ret = []string{}
+	start := -1
+	for i, r := range text {
+		word := (unicode.IsLetter(r) || unicode.IsDigit(r)) && 0x2E80 > r
+		if word {
+			if 0 > start {
+				start = i
+			}
+			continue
+		}
+		if 0 <= start {
+			ret = append(ret, text[start:i])
+			start = -1
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		ret = append(ret, text[i:i+size])
+	}
+	if 0 <= start {
+		ret = append(ret, text[start:])
+	}
+	return
+}
+
+// diffSimilarity 返回词序列 a 和 b 的相似度 2 * LCS / (len(a) + len(b))。
+func diffSimilarity(a, b []string) float64 {
+	total := len(a) + len(b)
+	if 0 == total {
+		return 1
+	}
+	shorter, longer := len(a), len(b)
+	if shorter > longer {
+		shorter, longer = longer, shorter
+	}
+	if float64(2*shorter)/float64(total) < diffSimilarityThreshold {
+		// 长度相差太多时不可能达到阈值
+		return 0
+	}
+	return float64(2*diffLCSTable(a, b)[0][0]) / float64(total)
+}
+
+// diffLCSTable 返回词序列 a 和 b 的最长公共子序列表，ret[i][j] 为 a[i:] 和 b[j:] 的最长公共子序列长度。
+func diffLCSTable(a, b []string) (ret [][]int) {
+	ret = make([][]int, len(a)+1)
+	for i := range ret {
+		ret[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; 0 <= i; i-- {
+		for j := len(b) - 1; 0 <= j; j-- {
+			if a[i] == b[j] {
+				ret[i][j] = ret[i+1][j+1] + 1
+			} else if ret[i+1][j] >= ret[i][j+1] {
+				ret[i][j] = ret[i+1][j]
+			} else {
+				ret[i][j] = ret[i][j+1]
+			}
+		}
+	}
+	return
+}
+
+// diffLIS 返回 matches 中不为 -1 的元素构成的最长递增子序列，ret[i] 表示 matches[i] 是否在该子序列中。
+func diffLIS(matches []int) (ret []bool) {
+	ret = make([]bool, len(matches))
+	var tails []int // tails[k] 为长度为 k+1 的递增子序列的最后一个元素在 matches 中的下标
+	prev := make([]int, len(matches))
+	for i, m := range matches {
+		if -1 == m {
+			continue
+		}
+		k := sort.Search(len(tails), func(k int) bool { return matches[tails[k]] >= m })
+		prev[i] = -1
+		if 0 < k {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	if 0 < len(tails) {
+		for i := tails[len(tails)-1]; -1 != i; i = prev[i] {
+			ret[i] = true
+		}
+	}
+	return
+}
@@ -51,6 +51,9 @@ func FootnotesStart(t *Tree, container *ast.Node) int {
 	if lex.ItemColon != t.Context.currentLine[i+1] {
 		return 0
 	}
+	if t.Context.exceedNestingDepth(container) {
+		return 0
+	}
 	t.Context.advanceOffset(1, false)
 
 	t.Context.closeUnmatchedBlocks()
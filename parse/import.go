@@ -0,0 +1,256 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/util"
+)
+
+// 这里的函数用于从其他格式（比如 mdast JSON）导入语法树时构造节点，构造出的节点结构和解析 Markdown 得到的结构一致。
+
+// appendTextNode 添加文本节点，文本中的换行会被转换为软换行节点，和前一个文本节点相邻时进行合并。
+func appendTextNode(parent *ast.Node, text string) {
+	typ := ast.NodeText
+	if ast.NodeLink == parent.Type || ast.NodeImage == parent.Type {
+		typ = ast.NodeLinkText
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if 0 < i {
+			parent.AppendChild(&ast.Node{Type: ast.NodeSoftBreak, Tokens: []byte("\n")})
+		}
+		if "" == line {
+			continue
+		}
+		if last := parent.LastChild; nil != last && typ == last.Type {
+			last.Tokens = append(last.Tokens, line...)
+			continue
+		}
+		parent.AppendChild(&ast.Node{Type: typ, Tokens: []byte(line)})
+	}
+}
+
+// newDelimitedNode 创建一个由开始和结束标记符包裹的行级节点，比如强调、加粗和删除线，children 用于添加子节点。
+func newDelimitedNode(typ, openType, closeType ast.NodeType, marker string, children func(node *ast.Node) error) (ret *ast.Node, err error) {
+	ret = &ast.Node{Type: typ}
+	ret.AppendChild(&ast.Node{Type: openType, Tokens: []byte(marker)})
+	if err = children(ret); nil != err {
+		return
+	}
+	ret.AppendChild(&ast.Node{Type: closeType, Tokens: []byte(marker)})
+	return
+}
+
+func newCodeSpanNode(value string) (ret *ast.Node) {
+	markerLen := longestRun(value, '`') + 1
+	marker := []byte(strings.Repeat("`", markerLen))
+	ret = &ast.Node{Type: ast.NodeCodeSpan, CodeMarkerLen: markerLen}
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeSpanOpenMarker, Tokens: marker})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeSpanContent, Tokens: []byte(value)})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeSpanCloseMarker, Tokens: marker})
+	return
+}
+
+func newCodeBlockNode(info, value string) (ret *ast.Node) {
+	fenceLen := longestRun(value, '`') + 1
+	if 3 > fenceLen {
+		fenceLen = 3
+	}
+	fence := []byte(strings.Repeat("`", fenceLen))
+	code := []byte(value)
+	if "" != value && !strings.HasSuffix(value, "\n") {
+		code = append(code, '\n')
+	}
+
+	ret = &ast.Node{Type: ast.NodeCodeBlock, IsFencedCodeBlock: true, CodeBlockFenceChar: '`', CodeBlockFenceLen: fenceLen,
+		CodeBlockOpenFence: fence, CodeBlockInfo: []byte(info), CodeBlockCloseFence: fence}
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeBlockFenceOpenMarker, Tokens: fence, CodeBlockFenceLen: fenceLen})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeBlockFenceInfoMarker, CodeBlockInfo: ret.CodeBlockInfo})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeBlockCode, Tokens: code})
+	ret.AppendChild(&ast.Node{Type: ast.NodeCodeBlockFenceCloseMarker, Tokens: fence, CodeBlockFenceLen: fenceLen})
+	return
+}
+
+func newMathBlockNode(value string) (ret *ast.Node) {
+	ret = &ast.Node{Type: ast.NodeMathBlock}
+	ret.AppendChild(&ast.Node{Type: ast.NodeMathBlockOpenMarker})
+	ret.AppendChild(&ast.Node{Type: ast.NodeMathBlockContent, Tokens: []byte(value)})
+	ret.AppendChild(&ast.Node{Type: ast.NodeMathBlockCloseMarker})
+	return
+}
+
+func newInlineMathNode(value string) (ret *ast.Node) {
+	ret = &ast.Node{Type: ast.NodeInlineMath}
+	ret.AppendChild(&ast.Node{Type: ast.NodeInlineMathOpenMarker})
+	ret.AppendChild(&ast.Node{Type: ast.NodeInlineMathContent, Tokens: []byte(value)})
+	ret.AppendChild(&ast.Node{Type: ast.NodeInlineMathCloseMarker})
+	return
+}
+
+func newHeadingNode(level int) (ret *ast.Node) {
+	ret = &ast.Node{Type: ast.NodeHeading, HeadingLevel: level}
+	ret.AppendChild(&ast.Node{Type: ast.NodeHeadingC8hMarker, Tokens: []byte(strings.Repeat("#", level) + " ")})
+	return
+}
+
+func newBlockquoteNode() (ret *ast.Node) {
+	ret = &ast.Node{Type: ast.NodeBlockquote}
+	ret.AppendChild(&ast.Node{Type: ast.NodeBlockquoteMarker, Tokens: []byte("> ")})
+	return
+}
+
+// newLinkNode 创建一个链接或者图片节点，children 用于添加链接文本（图片替代文本）子节点。
+func newLinkNode(typ ast.NodeType, url string, title *string, linkType int, children func(node *ast.Node) error) (ret *ast.Node, err error) {
+	ret = &ast.Node{Type: typ, LinkType: linkType}
+	if ast.NodeImage == typ {
+		ret.AppendChild(&ast.Node{Type: ast.NodeBang, Tokens: []byte("!")})
+	}
+	ret.AppendChild(&ast.Node{Type: ast.NodeOpenBracket, Tokens: []byte("[")})
+	if err = children(ret); nil != err {
+		return
+	}
+	ret.AppendChild(&ast.Node{Type: ast.NodeCloseBracket, Tokens: []byte("]")})
+	ret.AppendChild(&ast.Node{Type: ast.NodeOpenParen, Tokens: []byte("(")})
+	ret.AppendChild(&ast.Node{Type: ast.NodeLinkDest, Tokens: []byte(url)})
+	if nil != title {
+		ret.AppendChild(&ast.Node{Type: ast.NodeLinkSpace, Tokens: []byte(" ")})
+		ret.AppendChild(&ast.Node{Type: ast.NodeLinkTitle, Tokens: []byte(*title)})
+	}
+	ret.AppendChild(&ast.Node{Type: ast.NodeCloseParen, Tokens: []byte(")")})
+	return
+}
+
+// newListNode 创建一个列表节点，列表项需要通过 newListItemNode 创建，添加完列表项后需要调用 finishListNode。
+func newListNode(ordered bool, start int, delimiter byte, tight, task bool) *ast.Node {
+	listData := &ast.ListData{Tight: tight, Num: -1}
+	if ordered {
+		listData.Typ, listData.Start, listData.Delimiter, listData.Num = 1, start, delimiter, start
+	} else {
+		listData.BulletChar = '-'
+	}
+	if task {
+		listData.Typ = 3
+	}
+	return &ast.Node{Type: ast.NodeList, ListData: listData}
+}
+
+// newListItemNode 创建列表 list 的第 i 个列表项，checked 为 nil 时表示不是任务列表项。
+func newListItemNode(list *ast.Node, i int, checked *bool) *ast.Node {
+	listData := *list.ListData
+	marker := "-"
+	if 0 == listData.BulletChar {
+		listData.Num = list.ListData.Start + i
+		listData.Start = listData.Num
+		marker = strconv.Itoa(listData.Num)
+		listData.Padding = len(marker) + 2
+	} else {
+		listData.Padding = 2
+	}
+	listData.Marker = []byte(marker)
+	listData.Checked = nil != checked && *checked
+	return &ast.Node{Type: ast.NodeListItem, ListData: &listData, Tokens: []byte(marker)}
+}
+
+// finishListNode 在添加完列表项后调用，用于补全列表节点的附加信息。
+func finishListNode(list *ast.Node) {
+	if first := list.FirstChild; nil != first && nil != first.ListData {
+		list.ListData.Checked = first.ListData.Checked
+		list.ListData.Marker = first.ListData.Marker
+		list.ListData.Padding = first.ListData.Padding
+	}
+}
+
+// prependTaskListItemMarker 在列表项 li 的第一个段落开头插入任务列表项标记符。
+func prependTaskListItemMarker(li *ast.Node, checked bool) {
+	paragraph := li.FirstChild
+	if nil == paragraph || ast.NodeParagraph != paragraph.Type {
+		paragraph = &ast.Node{Type: ast.NodeParagraph}
+		li.PrependChild(paragraph)
+	}
+
+	marker := &ast.Node{Type: ast.NodeTaskListItemMarker, Tokens: []byte("[ ]"), TaskListItemChecked: checked}
+	if checked {
+		marker.Tokens = []byte("[x]")
+	}
+	if first := paragraph.FirstChild; nil != first && ast.NodeText == first.Type {
+		first.Tokens = append([]byte(" "), first.Tokens...)
+	} else {
+		paragraph.PrependChild(&ast.Node{Type: ast.NodeText, Tokens: []byte(" ")})
+	}
+	paragraph.PrependChild(marker)
+}
+
+// newTableNode 创建一个表节点，表行需要通过 appendTableRow 添加，第一行为表头。
+func newTableNode(aligns []int) *ast.Node {
+	return &ast.Node{Type: ast.NodeTable, TableAligns: aligns}
+}
+
+// appendTableRow 为表 table 添加一行，cells 用于添加单元格子节点。
+func appendTableRow(table *ast.Node, cellsLen int, cells func(i int, cell *ast.Node) error) error {
+	aligns := table.TableAligns
+	tr := &ast.Node{Type: ast.NodeTableRow, TableAligns: aligns}
+	for i := 0; i < cellsLen; i++ {
+		td := &ast.Node{Type: ast.NodeTableCell}
+		if i < len(aligns) {
+			td.TableCellAlign = aligns[i]
+		}
+		if err := cells(i, td); nil != err {
+			return err
+		}
+		tr.AppendChild(td)
+	}
+	if nil == table.FirstChild {
+		tr.TableAligns = nil
+		head := &ast.Node{Type: ast.NodeTableHead}
+		head.AppendChild(tr)
+		table.AppendChild(head)
+		return nil
+	}
+	table.AppendChild(tr)
+	return nil
+}
+
+// linkFootnotesRefs 关联脚注引用和脚注定义，没有对应脚注定义的引用按照文本处理。
+func (t *Tree) linkFootnotesRefs(refs []*ast.Node) {
+	for _, ref := range refs {
+		idx, def := t.FindFootnotesDef(ref.Tokens)
+		if nil == def {
+			ref.Type = ast.NodeText
+			ref.Tokens = []byte("[" + util.BytesToStr(ref.Tokens) + "]")
+			continue
+		}
+
+		ref.FootnotesRefId = strconv.Itoa(idx)
+		if refsLen := len(def.FootnotesRefs); 0 < refsLen {
+			ref.FootnotesRefId += ":" + strconv.Itoa(refsLen+1)
+		}
+		def.FootnotesRefs = append(def.FootnotesRefs, ref)
+	}
+}
+
+// longestRun 返回 s 中连续字符 c 的最大长度。
+func longestRun(s string, c byte) (ret int) {
+	run := 0
+	for i := 0; i < len(s); i++ {
+		if c == s[i] {
+			run++
+			if ret < run {
+				ret = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	return
+}
@@ -61,7 +61,12 @@ func (t *Tree) parseInline(block *ast.Node, ctx *InlineContext) {
 		case lex.ItemBang:
 			n = t.parseBang(ctx)
 		case lex.ItemDollar:
-			n = t.parseInlineMath(ctx)
+			if t.Context.ParseOption.Strict { // 规范中没有数学公式
+				ctx.pos++
+				n = &ast.Node{Type: ast.NodeText, Tokens: dollar}
+			} else {
+				n = t.parseInlineMath(ctx)
+			}
 		case lex.ItemOpenBrace:
 			n = t.parseHeadingID(block, ctx)
 		case lex.ItemOpenParen:
@@ -431,6 +436,11 @@ func (t *Tree) parseOpenBracket(ctx *InlineContext) (ret *ast.Node) {
 }
 
 func (t *Tree) addBracket(node *ast.Node, index int, image bool, ctx *InlineContext) {
+	if t.Context.exceedDelimiters(ctx.bracketsLen) {
+		// 括号栈满的话作为文本
+		return
+	}
+
 	if nil != ctx.brackets {
 		ctx.brackets.bracketAfter = true
 	}
@@ -443,8 +453,10 @@ func (t *Tree) addBracket(node *ast.Node, index int, image bool, ctx *InlineCont
 		image:             image,
 		active:            true,
 	}
+	ctx.bracketsLen++
 }
 
 func (t *Tree) removeBracket(ctx *InlineContext) {
 	ctx.brackets = ctx.brackets.previous
+	ctx.bracketsLen--
 }
@@ -59,6 +59,13 @@ func (t *Tree) walkParseInline(node *ast.Node) {
 			return
 		}
 
+		if t.Context.canceled() {
+			// 解析已经被取消，不再生成行级子节点，直接作为文本
+			node.AppendChild(&ast.Node{Type: ast.NodeText, Tokens: tokens})
+			node.Tokens = nil
+			return
+		}
+
 		ctx := &InlineContext{tokens: tokens, tokensLen: length}
 
 		// 生成该块节点的行级子节点
@@ -0,0 +1,586 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+)
+
+// ParseJiraWiki 将 Jira 文本格式（wiki markup）解析为语法树。
+//
+// 支持标题、段落、{quote}、bq.、{code}、{noformat}、{panel}、列表、表格、分隔线以及常用的行级文本效果、链接和图片，
+// 其他宏只保留内容。{info}、{tip}、{note}、{warning} 和以提示类型为标题的 {panel} 会转换为 GitHub 风格的提示块 > [!NOTE]，
+// 以 (/) 或者 (x) 开头的无序列表会转换为任务列表。
+func ParseJiraWiki(name string, wiki []byte, options *Options) (tree *Tree) {
+	tree = &Tree{Name: name, Context: &Context{ParseOption: options}}
+	tree.Context.Tree = tree
+	tree.Root = &ast.Node{Type: ast.NodeDocument}
+	p := &jiraImporter{}
+	text := strings.ReplaceAll(string(wiki), "\r\n", "\n")
+	p.appendBlocks(tree.Root, strings.Split(text, "\n"))
+	return
+}
+
+// jiraImporter 用于将 Jira 文本格式转换为语法树节点。
+type jiraImporter struct{}
+
+var (
+	jiraHeading   = regexp.MustCompile(`^\s*h([1-6])\.\s*(.*)$`)
+	jiraBq        = regexp.MustCompile(`^\s*bq\.\s*(.*)$`)
+	jiraRule      = regexp.MustCompile(`^\s*-{4,}\s*$`)
+	jiraMacro     = regexp.MustCompile(`^\s*\{(code|noformat|quote|panel|info|tip|note|warning)(?::([^}]*))?\}(.*)$`)
+	jiraListItem  = regexp.MustCompile(`^\s*([*#]+|-)\s+(.*)$`)
+	jiraTableLine = regexp.MustCompile(`^\s*\|`)
+	jiraColor     = regexp.MustCompile(`^\{color(?::[^}]*)?\}`)
+)
+
+// jiraAdmonitions 定义了 Jira 提示宏到提示类型的映射。
+var jiraAdmonitions = map[string]string{"info": "NOTE", "tip": "TIP", "note": "IMPORTANT", "warning": "WARNING"}
+
+// jiraBlockStart 判断行 line 是否开始一个新的块。
+func jiraBlockStart(line string) bool {
+	return jiraHeading.MatchString(line) || jiraBq.MatchString(line) || jiraRule.MatchString(line) || jiraMacro.MatchString(line) ||
+		jiraListItem.MatchString(line) || jiraTableLine.MatchString(line)
+}
+
+func (p *jiraImporter) appendBlocks(parent *ast.Node, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if "" == strings.TrimSpace(line) {
+			i++
+			continue
+		}
+
+		if m := jiraHeading.FindStringSubmatch(line); nil != m {
+			heading := newHeadingNode(int(m[1][0] - '0'))
+			p.appendInlines(heading, strings.TrimSpace(m[2]))
+			parent.AppendChild(heading)
+			i++
+			continue
+		}
+		if m := jiraBq.FindStringSubmatch(line); nil != m {
+			blockquote := newBlockquoteNode()
+			p.appendBlocks(blockquote, []string{m[1]})
+			parent.AppendChild(blockquote)
+			i++
+			continue
+		}
+		if jiraRule.MatchString(line) {
+			parent.AppendChild(&ast.Node{Type: ast.NodeThematicBreak, Tokens: []byte("---")})
+			i++
+			continue
+		}
+		if jiraMacro.MatchString(line) {
+			i = p.appendMacro(parent, lines, i)
+			continue
+		}
+		if jiraListItem.MatchString(line) {
+			i = p.appendLists(parent, lines, i)
+			continue
+		}
+		if jiraTableLine.MatchString(line) {
+			i = p.appendTable(parent, lines, i)
+			continue
+		}
+
+		// 段落中的换行是强制换行
+		var paragraphLines []string
+		for ; i < len(lines) && "" != strings.TrimSpace(lines[i]); i++ {
+			if 0 < len(paragraphLines) && jiraBlockStart(lines[i]) {
+				break
+			}
+			paragraphLines = append(paragraphLines, strings.TrimSpace(lines[i]))
+		}
+		paragraph := &ast.Node{Type: ast.NodeParagraph}
+		p.appendInlines(paragraph, strings.Join(paragraphLines, "\n"))
+		parent.AppendChild(paragraph)
+	}
+}
+
+// appendMacro 解析从第 i 行开始的宏，返回宏之后的行号。结束标记所在行中的剩余内容会作为新的一行继续解析。
+func (p *jiraImporter) appendMacro(parent *ast.Node, lines []string, i int) int {
+	m := jiraMacro.FindStringSubmatch(lines[i])
+	name, params, closing := m[1], jiraMacroParams(m[2]), "{"+m[1]+"}"
+
+	var body []string
+	rest, next := m[3], len(lines)
+	for j := i; j < len(lines); j++ {
+		if idx := strings.Index(rest, closing); 0 <= idx {
+			body = append(body, rest[:idx])
+			if after := rest[idx+len(closing):]; "" != strings.TrimSpace(after) {
+				lines[j], next = after, j
+			} else {
+				next = j + 1
+			}
+			break
+		}
+		body = append(body, rest)
+		if j+1 < len(lines) {
+			rest = lines[j+1]
+		}
+	}
+	if 0 < len(body) && "" == strings.TrimSpace(body[0]) {
+		body = body[1:]
+	}
+
+	switch name {
+	case "code", "noformat":
+		code := strings.TrimRight(strings.Join(body, "\n"), "\n ")
+		parent.AppendChild(newCodeBlockNode(params["language"], code))
+		return next
+	}
+
+	blockquote := newBlockquoteNode()
+	p.appendBlocks(blockquote, body)
+	kind := jiraAdmonitions[name]
+	title := params["title"]
+	if "panel" == name && "" != title {
+		switch upper := strings.ToUpper(title); upper {
+		case "NOTE", "TIP", "IMPORTANT", "WARNING", "CAUTION":
+			kind = upper
+		default:
+			paragraph := &ast.Node{Type: ast.NodeParagraph}
+			strong, _ := newDelimitedNode(ast.NodeStrong, ast.NodeStrongA6kOpenMarker, ast.NodeStrongA6kCloseMarker, "**", func(node *ast.Node) error {
+				p.appendInlines(node, title)
+				return nil
+			})
+			paragraph.AppendChild(strong)
+			blockquote.FirstChild.InsertAfter(paragraph)
+		}
+	}
+	if "" != kind {
+		// 提示标记和第一个段落之间使用软换行，和 GitHub 的写法一致
+		first := blockquote.FirstChild.Next
+		if nil == first || ast.NodeParagraph != first.Type {
+			first = &ast.Node{Type: ast.NodeParagraph}
+			blockquote.FirstChild.InsertAfter(first)
+		} else {
+			first.PrependChild(&ast.Node{Type: ast.NodeSoftBreak, Tokens: []byte("\n")})
+		}
+		first.PrependChild(&ast.Node{Type: ast.NodeText, Tokens: []byte("[!" + kind + "]")})
+	}
+	parent.AppendChild(blockquote)
+	return next
+}
+
+// jiraMacroParams 解析宏参数，比如 {code:title=Foo.java|language=java} 中的 title=Foo.java|language=java，
+// 第一个没有等号的参数作为语言。
+func jiraMacroParams(params string) (ret map[string]string) {
+	ret = map[string]string{}
+	for i, param := range strings.Split(params, "|") {
+		param = strings.TrimSpace(param)
+		if idx := strings.Index(param, "="); 0 < idx {
+			ret[strings.ToLower(param[:idx])] = strings.TrimSpace(param[idx+1:])
+		} else if 0 == i && "" != param {
+			ret["language"] = param
+		}
+	}
+	return
+}
+
+// jiraItem 描述了 Jira 列表项，列表项的嵌套层级为标记的长度。
+type jiraItem struct {
+	ordered  bool
+	depth    int
+	text     string
+	children []*jiraItem
+}
+
+// appendLists 解析从第 i 行开始的连续列表行，返回列表之后的行号。不是列表项也不是其他块的非空行会接在前一个列表项之后。
+func (p *jiraImporter) appendLists(parent *ast.Node, lines []string, i int) int {
+	root := &jiraItem{}
+	stack := []*jiraItem{root}
+	var last *jiraItem
+	for ; i < len(lines) && "" != strings.TrimSpace(lines[i]); i++ {
+		m := jiraListItem.FindStringSubmatch(lines[i])
+		if nil == m {
+			if jiraBlockStart(lines[i]) {
+				break
+			}
+			last.text += "\n" + strings.TrimSpace(lines[i])
+			continue
+		}
+
+		marker := m[1]
+		item := &jiraItem{ordered: strings.HasSuffix(marker, "#"), depth: len(marker), text: strings.TrimSpace(m[2])}
+		for 1 < len(stack) && stack[len(stack)-1].depth >= item.depth {
+			stack = stack[:len(stack)-1]
+		}
+		top := stack[len(stack)-1]
+		top.children = append(top.children, item)
+		stack = append(stack, item)
+		last = item
+	}
+	p.appendItems(parent, root.children)
+	return i
+}
+
+// appendItems 将兄弟列表项 items 转换为列表，相邻的同类列表项属于同一个列表。
+func (p *jiraImporter) appendItems(parent *ast.Node, items []*jiraItem) {
+	for i := 0; i < len(items); {
+		j := i
+		task := !items[i].ordered
+		for ; j < len(items) && items[j].ordered == items[i].ordered; j++ {
+			task = task && (strings.HasPrefix(items[j].text, "(/)") || strings.HasPrefix(items[j].text, "(x)"))
+		}
+
+		list := newListNode(items[i].ordered, 1, '.', true, task)
+		for k, item := range items[i:j] {
+			var checked *bool
+			text := item.text
+			if task {
+				done := strings.HasPrefix(text, "(/)")
+				checked = &done
+				text = strings.TrimSpace(text[len("(/)"):])
+			}
+			li := newListItemNode(list, k, checked)
+			paragraph := &ast.Node{Type: ast.NodeParagraph}
+			p.appendInlines(paragraph, text)
+			li.AppendChild(paragraph)
+			if task {
+				prependTaskListItemMarker(li, *checked)
+			}
+			p.appendItems(li, item.children)
+			list.AppendChild(li)
+		}
+		finishListNode(list)
+		parent.AppendChild(list)
+		i = j
+	}
+}
+
+// appendTable 解析从第 i 行开始的连续表格行，返回表格之后的行号。第一行不是 || 表头时也作为表头。
+func (p *jiraImporter) appendTable(parent *ast.Node, lines []string, i int) int {
+	var rows [][]string
+	cols := 0
+	for ; i < len(lines) && jiraTableLine.MatchString(lines[i]); i++ {
+		cells := jiraTableCells(strings.TrimSpace(lines[i]))
+		rows = append(rows, cells)
+		if cols < len(cells) {
+			cols = len(cells)
+		}
+	}
+
+	table := newTableNode(make([]int, cols))
+	for _, cells := range rows {
+		appendTableRow(table, cols, func(i int, cell *ast.Node) error {
+			if i < len(cells) {
+				p.appendInlines(cell, cells[i])
+			}
+			return nil
+		})
+	}
+	parent.AppendChild(table)
+	return i
+}
+
+// jiraTableCells 按照 | 和 || 拆分表格行，链接 [text|url] 和转义的 \| 中的竖线不作为分隔符。
+func jiraTableCells(line string) (ret []string) {
+	line = strings.TrimLeft(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = strings.TrimRight(line, "|")
+	}
+
+	cell := &strings.Builder{}
+	depth := 0
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '\\':
+			cell.WriteByte(c)
+			if i+1 < len(line) {
+				i++
+				cell.WriteByte(line[i])
+			}
+			continue
+		case '[':
+			depth++
+		case ']':
+			if 0 < depth {
+				depth--
+			}
+		case '|':
+			if 0 == depth {
+				ret = append(ret, strings.TrimSpace(cell.String()))
+				cell.Reset()
+				if i+1 < len(line) && '|' == line[i+1] {
+					i++
+				}
+				continue
+			}
+		}
+		cell.WriteByte(line[i])
+	}
+	ret = append(ret, strings.TrimSpace(cell.String()))
+	return
+}
+
+// appendInlines 解析行级内容 text 并添加到 parent 中，text 中的换行是强制换行。
+func (p *jiraImporter) appendInlines(parent *ast.Node, text string) {
+	runes := []rune(text)
+	buf := &strings.Builder{}
+	flush := func() {
+		if 0 < buf.Len() {
+			appendTextNode(parent, buf.String())
+			buf.Reset()
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		rest := string(runes[i:])
+		switch {
+		case '\\' == c && i+1 < len(runes) && '\\' == runes[i+1]:
+			flush()
+			parent.AppendChild(&ast.Node{Type: ast.NodeHardBreak, Tokens: []byte("\n")})
+			i += 2
+			for i < len(runes) && ' ' == runes[i] {
+				i++
+			}
+			continue
+		case '\\' == c && i+1 < len(runes):
+			if escaped := runes[i+1]; utf8.RuneSelf > escaped && lex.IsASCIIPunct(byte(escaped)) {
+				// 保留为 Markdown 的转义，避免格式化后被解析为标记
+				flush()
+				backslash := &ast.Node{Type: ast.NodeBackslash}
+				backslash.AppendChild(&ast.Node{Type: ast.NodeBackslashContent, Tokens: []byte{byte(escaped)}})
+				parent.AppendChild(backslash)
+			} else {
+				buf.WriteRune(escaped)
+			}
+			i += 2
+			continue
+		case '\n' == c:
+			flush()
+			parent.AppendChild(&ast.Node{Type: ast.NodeHardBreak, Tokens: []byte("\n")})
+			i++
+			continue
+		case strings.HasPrefix(rest, "{{"):
+			if end := jiraClose(runes, i+2, '}'); 0 < end && end+1 < len(runes) && '}' == runes[end+1] && i+2 < end {
+				flush()
+				parent.AppendChild(newCodeSpanNode(jiraUnescape(string(runes[i+2 : end]))))
+				i = end + 2
+				continue
+			}
+		case jiraColor.MatchString(rest):
+			i += len([]rune(jiraColor.FindString(rest)))
+			continue
+		case '[' == c:
+			if n := p.appendLink(parent, runes, i, flush); 0 < n {
+				i += n
+				continue
+			}
+		case '!' == c:
+			if n := p.appendImage(parent, runes, i, flush); 0 < n {
+				i += n
+				continue
+			}
+		}
+		if n := p.appendEffect(parent, runes, i, flush); 0 < n {
+			i += n
+			continue
+		}
+		buf.WriteRune(c)
+		i++
+	}
+	flush()
+}
+
+// jiraUnescape 去掉 text 中的反斜杠转义。
+func jiraUnescape(text string) string {
+	buf := &strings.Builder{}
+	for i := 0; i < len(text); i++ {
+		if '\\' == text[i] && i+1 < len(text) && '\\' != text[i+1] {
+			continue
+		}
+		buf.WriteByte(text[i])
+	}
+	return buf.String()
+}
+
+// jiraWordChar 判断 r 是否为字母或者数字。
+func jiraWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// jiraEffectMarker 判断 runes[i:] 是否以文本效果标记开始，返回标记和标记长度，{*} 形式的标记可以用在单词中间。
+func jiraEffectMarker(runes []rune, i int) (marker string, length int) {
+	if '{' == runes[i] && i+2 < len(runes) && '}' == runes[i+2] && strings.ContainsRune("*_-+^~", runes[i+1]) {
+		return string(runes[i+1]), 3
+	}
+	if '?' == runes[i] && i+1 < len(runes) && '?' == runes[i+1] {
+		return "??", 2
+	}
+	if strings.ContainsRune("*_-+^~", runes[i]) {
+		return string(runes[i]), 1
+	}
+	return "", 0
+}
+
+// appendEffect 解析 runes[i:] 开始的文本效果，比如 *strong*、_emphasis_、-deleted-，返回消耗的字符数，不是文本效果时返回 0。
+func (p *jiraImporter) appendEffect(parent *ast.Node, runes []rune, i int, flush func()) int {
+	marker, length := jiraEffectMarker(runes, i)
+	if 0 == length {
+		return 0
+	}
+	braced := 3 == length
+	start := i + length
+	if start >= len(runes) || unicode.IsSpace(runes[start]) || strings.HasPrefix(string(runes[start:]), marker) {
+		return 0
+	}
+	if !braced && 0 < i && jiraWordChar(runes[i-1]) {
+		return 0
+	}
+
+	for j := start + 1; j < len(runes) && '\n' != runes[j]; j++ {
+		if '\\' == runes[j] {
+			j++
+			continue
+		}
+		m, l := jiraEffectMarker(runes, j)
+		if m != marker {
+			continue
+		}
+		if 3 != l {
+			if unicode.IsSpace(runes[j-1]) || (j+l < len(runes) && jiraWordChar(runes[j+l])) {
+				continue
+			}
+		}
+
+		content := string(runes[start:j])
+		flush()
+		children := func(node *ast.Node) error {
+			p.appendInlines(node, content)
+			return nil
+		}
+		var node *ast.Node
+		switch marker {
+		case "*":
+			node, _ = newDelimitedNode(ast.NodeStrong, ast.NodeStrongA6kOpenMarker, ast.NodeStrongA6kCloseMarker, "**", children)
+		case "_", "??":
+			node, _ = newDelimitedNode(ast.NodeEmphasis, ast.NodeEmA6kOpenMarker, ast.NodeEmA6kCloseMarker, "*", children)
+		case "-":
+			node, _ = newDelimitedNode(ast.NodeStrikethrough, ast.NodeStrikethrough2OpenMarker, ast.NodeStrikethrough2CloseMarker, "~~", children)
+		case "^":
+			node, _ = newDelimitedNode(ast.NodeSup, ast.NodeSupOpenMarker, ast.NodeSupCloseMarker, "^", children)
+		case "~":
+			node, _ = newDelimitedNode(ast.NodeSub, ast.NodeSubOpenMarker, ast.NodeSubCloseMarker, "~", children)
+		case "+":
+			// 使用行级 HTML 表示下划线，Markdown 和 HTML 都可以保留
+			parent.AppendChild(&ast.Node{Type: ast.NodeInlineHTML, Tokens: []byte("<u>")})
+			children(parent)
+			parent.AppendChild(&ast.Node{Type: ast.NodeInlineHTML, Tokens: []byte("</u>")})
+			return j + l - i
+		}
+		parent.AppendChild(node)
+		return j + l - i
+	}
+	return 0
+}
+
+// jiraClose 返回 runes[i:] 中第一个未转义的字符 c 的位置，找不到时返回 -1。
+func jiraClose(runes []rune, i int, c rune) int {
+	for ; i < len(runes) && '\n' != runes[i]; i++ {
+		if '\\' == runes[i] {
+			i++
+			continue
+		}
+		if c == runes[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendLink 解析 runes[i:] 开始的链接 [text|url]、[url]、[#anchor]、[~user] 和 [^attachment]，返回消耗的字符数，不是链接时返回 0。
+func (p *jiraImporter) appendLink(parent *ast.Node, runes []rune, i int, flush func()) int {
+	end := jiraClose(runes, i+1, ']')
+	if 0 > end {
+		return 0
+	}
+
+	content := string(runes[i+1 : end])
+	text, url := "", content
+	if bar := jiraClose([]rune(content), 0, '|'); 0 <= bar {
+		text, url = string([]rune(content)[:bar]), string([]rune(content)[bar+1:])
+		// [text|url|tooltip]
+		if bar = strings.Index(url, "|"); 0 <= bar {
+			url = url[:bar]
+		}
+	}
+	url = strings.TrimSpace(url)
+	switch {
+	case strings.HasPrefix(url, "~"):
+		if "" == text {
+			text = "@" + url[1:]
+		}
+		flush()
+		p.appendInlines(parent, text)
+		return end + 1 - i
+	case strings.HasPrefix(url, "^"):
+		url = url[1:]
+	case "" == text && !strings.Contains(url, "://") && !strings.HasPrefix(url, "#") && !strings.HasPrefix(url, "mailto:") && !strings.HasPrefix(url, "/"):
+		// 比如问题编号 [ABC-123]，按照文本处理
+		return 0
+	case "" == url:
+		return 0
+	}
+	if "" == text {
+		text = strings.TrimPrefix(strings.TrimPrefix(url, "mailto:"), "#")
+	}
+
+	flush()
+	link, _ := newLinkNode(ast.NodeLink, url, nil, 0, func(node *ast.Node) error {
+		p.appendInlines(node, text)
+		return nil
+	})
+	parent.AppendChild(link)
+	return end + 1 - i
+}
+
+// appendImage 解析 runes[i:] 开始的图片 !src! 和 !src|alt=text, thumbnail!，返回消耗的字符数，不是图片时返回 0。
+func (p *jiraImporter) appendImage(parent *ast.Node, runes []rune, i int, flush func()) int {
+	if i+1 >= len(runes) || unicode.IsSpace(runes[i+1]) {
+		return 0
+	}
+	end := jiraClose(runes, i+1, '!')
+	if 0 > end {
+		return 0
+	}
+
+	content := string(runes[i+1 : end])
+	src, alt := content, ""
+	if bar := strings.Index(content, "|"); 0 <= bar {
+		src = content[:bar]
+		for _, param := range strings.Split(content[bar+1:], ",") {
+			if kv := strings.SplitN(strings.TrimSpace(param), "=", 2); 2 == len(kv) && "alt" == kv[0] {
+				alt = strings.TrimSpace(kv[1])
+			}
+		}
+	}
+	src = strings.TrimSpace(src)
+	if strings.ContainsAny(src, " \t") || (!strings.Contains(src, ".") && !strings.Contains(src, "://")) {
+		return 0
+	}
+
+	flush()
+	image, _ := newLinkNode(ast.NodeImage, src, nil, 0, func(node *ast.Node) error {
+		appendTextNode(node, alt)
+		return nil
+	})
+	parent.AppendChild(image)
+	return end + 1 - i
+}
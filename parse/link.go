@@ -83,6 +83,13 @@ func (context *Context) parseLinkRefDef(tokens []byte) []byte {
 		remains = tokens
 	}
 
+	if max := context.ParseOption.MaxLinkRefDefs; 0 < max && max <= context.linkRefDefs {
+		// 超出链接引用定义最大个数，作为段落文本
+		context.limitExceeded = true
+		return nil
+	}
+	context.linkRefDefs++
+
 	link := context.Tree.newLink(ast.NodeLink, label, destination, title, 1)
 	def := &ast.Node{Type: ast.NodeLinkRefDef, Tokens: label}
 	def.AppendChild(link)
@@ -24,11 +24,18 @@ func ListStart(t *Tree, container *ast.Node) int {
 		return 0
 	}
 
+	offset, column := t.Context.offset, t.Context.column
 	data, ial := t.parseListMarker(container)
 	if nil == data {
 		return 0
 	}
 
+	if t.Context.exceedNestingDepth(container) {
+		// 超出最大嵌套层数，还原位置后列表标记符作为文本
+		t.Context.offset, t.Context.column, t.Context.partiallyConsumedTab = offset, column, false
+		return 0
+	}
+
 	t.Context.closeUnmatchedBlocks()
 
 	listsMatch := container.Type == ast.NodeList && t.Context.listsMatch(container.ListData, data)
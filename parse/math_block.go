@@ -20,7 +20,7 @@ import (
 
 // MathBlockStart 判断数学公式块（$$）是否开始。
 func MathBlockStart(t *Tree, container *ast.Node) int {
-	if t.Context.indented {
+	if t.Context.indented || t.Context.ParseOption.Strict {
 		return 0
 	}
 
@@ -0,0 +1,344 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/88250/lute/ast"
+)
+
+// ParseMdast 将 mdast（https://github.com/syntax-tree/mdast）JSON 解析为语法树，生成的节点结构和解析 Markdown 得到的结构一致，可以直接交给各个渲染器渲染。
+func ParseMdast(name string, mdast []byte, options *Options) (tree *Tree, err error) {
+	root := &ast.MdastNode{}
+	if err = json.Unmarshal(mdast, root); nil != err {
+		return
+	}
+	if "root" != root.Type {
+		return nil, fmt.Errorf("invalid mdast root type [%s]", root.Type)
+	}
+
+	tree = &Tree{Name: name, Context: &Context{ParseOption: options}}
+	tree.Context.Tree = tree
+	m := &mdastImporter{tree: tree, definitions: map[string]*ast.MdastNode{}}
+	m.collectDefinitions(root)
+	tree.Root = &ast.Node{Type: ast.NodeDocument}
+	if err = m.appendChildren(tree.Root, root); nil != err {
+		return nil, err
+	}
+	if nil != m.footnotesDefBlock {
+		tree.Root.AppendChild(m.footnotesDefBlock)
+	}
+	tree.linkFootnotesRefs(m.footnotesRefs)
+	if options.KramdownBlockIAL {
+		// 文档 IAL 记录在 root 的 data.kramdownIAL 中，和解析 Markdown 一样作为文档最后一个节点
+		doc := &ast.Node{}
+		m.setIAL(doc, root)
+		if 0 < len(doc.KramdownIAL) {
+			tree.Root.ID = doc.IALAttr("id")
+			tree.ID = tree.Root.ID
+			tree.Root.AppendChild(&ast.Node{Type: ast.NodeKramdownBlockIAL, Tokens: IAL2Tokens(doc.KramdownIAL)})
+		}
+	}
+	return
+}
+
+// mdastImporter 用于将 mdast 节点转换为语法树节点。
+type mdastImporter struct {
+	tree              *Tree
+	definitions       map[string]*ast.MdastNode // 链接引用定义，用于解析 linkReference 和 imageReference
+	footnotesDefBlock *ast.Node                 // 脚注定义块，所有脚注定义都会放到文档末尾
+	footnotesRefs     []*ast.Node               // 脚注引用
+}
+
+func (m *mdastImporter) collectDefinitions(n *ast.MdastNode) {
+	if "definition" == n.Type {
+		if _, ok := m.definitions[n.Identifier]; !ok {
+			m.definitions[n.Identifier] = n
+		}
+	}
+	for _, c := range n.Children {
+		m.collectDefinitions(c)
+	}
+}
+
+func (m *mdastImporter) appendChildren(node *ast.Node, n *ast.MdastNode) error {
+	for _, c := range n.Children {
+		if err := m.appendNode(node, n, c); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// children 返回用于添加 n 的子节点的函数，图片节点使用 alt 作为子节点。
+func (m *mdastImporter) children(n *ast.MdastNode) func(node *ast.Node) error {
+	return func(node *ast.Node) error {
+		if nil != n.Alt {
+			appendTextNode(node, *n.Alt)
+			return nil
+		}
+		return m.appendChildren(node, n)
+	}
+}
+
+func (m *mdastImporter) appendNode(parent *ast.Node, mparent, n *ast.MdastNode) (err error) {
+	var node *ast.Node
+	switch n.Type {
+	case "paragraph":
+		node = &ast.Node{Type: ast.NodeParagraph}
+		err = m.appendChildren(node, n)
+	case "heading":
+		if 1 > n.Depth || 6 < n.Depth {
+			return fmt.Errorf("invalid mdast heading depth [%d]", n.Depth)
+		}
+		node = newHeadingNode(n.Depth)
+		if err = m.appendChildren(node, n); nil != err {
+			return
+		}
+		if id, ok := n.Data["id"].(string); ok && "" != id {
+			node.AppendChild(&ast.Node{Type: ast.NodeHeadingID, Tokens: []byte(id)})
+		}
+	case "thematicBreak":
+		node = &ast.Node{Type: ast.NodeThematicBreak}
+	case "blockquote":
+		node = newBlockquoteNode()
+		err = m.appendChildren(node, n)
+	case "list":
+		node, err = m.list(n)
+	case "html":
+		typ := ast.NodeInlineHTML
+		switch mparent.Type {
+		case "root", "blockquote", "listItem", "footnoteDefinition":
+			typ = ast.NodeHTMLBlock
+		}
+		node = &ast.Node{Type: typ, Tokens: []byte(mdastValue(n))}
+	case "code":
+		node = newCodeBlockNode(strings.TrimSpace(mdastValuePtr(n.Lang)+" "+mdastValuePtr(n.Meta)), mdastValue(n))
+	case "math":
+		node = newMathBlockNode(mdastValue(n))
+	case "inlineMath":
+		node = newInlineMathNode(mdastValue(n))
+	case "yaml":
+		value := []byte(mdastValue(n))
+		node = &ast.Node{Type: ast.NodeYamlFrontMatter, Tokens: value}
+		node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterOpenMarker})
+		node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterContent, Tokens: value})
+		node.AppendChild(&ast.Node{Type: ast.NodeYamlFrontMatterCloseMarker})
+	case "text":
+		appendTextNode(parent, mdastValue(n))
+		return
+	case "emphasis":
+		node, err = newDelimitedNode(ast.NodeEmphasis, ast.NodeEmA6kOpenMarker, ast.NodeEmA6kCloseMarker, "*", m.children(n))
+	case "strong":
+		node, err = newDelimitedNode(ast.NodeStrong, ast.NodeStrongA6kOpenMarker, ast.NodeStrongA6kCloseMarker, "**", m.children(n))
+	case "delete":
+		node, err = newDelimitedNode(ast.NodeStrikethrough, ast.NodeStrikethrough2OpenMarker, ast.NodeStrikethrough2CloseMarker, "~~", m.children(n))
+	case "inlineCode":
+		node = newCodeSpanNode(mdastValue(n))
+	case "break":
+		node = &ast.Node{Type: ast.NodeHardBreak, Tokens: []byte("\n")}
+	case "link":
+		node, err = newLinkNode(ast.NodeLink, mdastValuePtr(n.URL), n.Title, 0, m.children(n))
+	case "image":
+		node, err = newLinkNode(ast.NodeImage, mdastValuePtr(n.URL), n.Title, 0, m.children(n))
+	case "linkReference", "imageReference":
+		def := m.definitions[n.Identifier]
+		if nil == def {
+			// 没有对应的链接引用定义时按照文本处理
+			return m.appendChildren(parent, n)
+		}
+		typ := ast.NodeLink
+		if "imageReference" == n.Type {
+			typ = ast.NodeImage
+		}
+		if node, err = newLinkNode(typ, mdastValuePtr(def.URL), def.Title, 3, m.children(n)); nil == err {
+			node.LinkRefLabel = []byte(def.Label)
+		}
+	case "definition":
+		label := []byte(n.Label)
+		if 1 > len(label) {
+			label = []byte(n.Identifier)
+		}
+		var title []byte
+		if nil != n.Title {
+			title = []byte(*n.Title)
+		}
+		def := &ast.Node{Type: ast.NodeLinkRefDef, Tokens: label}
+		def.AppendChild(m.tree.newLink(ast.NodeLink, label, []byte(mdastValuePtr(n.URL)), title, 1))
+		node = &ast.Node{Type: ast.NodeLinkRefDefBlock}
+		node.AppendChild(def)
+	case "footnoteDefinition":
+		def := &ast.Node{Type: ast.NodeFootnotesDef, Tokens: []byte("^" + mdastLabel(n))}
+		if err = m.appendChildren(def, n); nil != err {
+			return
+		}
+		m.setIAL(def, n)
+		if nil == m.footnotesDefBlock {
+			m.footnotesDefBlock = &ast.Node{Type: ast.NodeFootnotesDefBlock}
+		}
+		m.footnotesDefBlock.AppendChild(def)
+		m.appendIAL(def)
+		return
+	case "footnoteReference":
+		label := []byte("^" + mdastLabel(n))
+		node = &ast.Node{Type: ast.NodeFootnotesRef, Tokens: label, FootnotesRefLabel: label}
+		m.footnotesRefs = append(m.footnotesRefs, node)
+	case "table":
+		node, err = m.table(n)
+	default:
+		if strings.HasPrefix(n.Type, ast.MdastLutePrefix) {
+			node, err = m.luteNode(n)
+			break
+		}
+
+		// 未知类型的节点，有值的作为文本，否则展开子节点
+		if nil != n.Value {
+			appendTextNode(parent, *n.Value)
+			return
+		}
+		return m.appendChildren(parent, n)
+	}
+	if nil != err {
+		return
+	}
+
+	m.setIAL(node, n)
+	parent.AppendChild(node)
+	m.appendIAL(node)
+	return
+}
+
+func (m *mdastImporter) luteNode(n *ast.MdastNode) (ret *ast.Node, err error) {
+	typ := ast.Str2NodeType("Node" + strings.TrimPrefix(n.Type, ast.MdastLutePrefix))
+	if 0 > typ {
+		return nil, fmt.Errorf("unknown mdast lute node type [%s]", n.Type)
+	}
+	ret = &ast.Node{Type: typ}
+	if nil != n.Value {
+		ret.Tokens = []byte(*n.Value)
+	}
+	err = m.appendChildren(ret, n)
+	return
+}
+
+func (m *mdastImporter) list(n *ast.MdastNode) (ret *ast.Node, err error) {
+	ordered := nil != n.Ordered && *n.Ordered
+	tight := nil == n.Spread || !*n.Spread
+	task := false
+	for _, item := range n.Children {
+		if nil != item.Checked {
+			task = true
+			break
+		}
+	}
+	start := 1
+	if nil != n.Start {
+		start = *n.Start
+	}
+
+	ret = newListNode(ordered, start, '.', tight, task)
+	for i, item := range n.Children {
+		if "listItem" != item.Type {
+			return nil, fmt.Errorf("invalid mdast list child type [%s]", item.Type)
+		}
+
+		li := newListItemNode(ret, i, item.Checked)
+		if err = m.appendChildren(li, item); nil != err {
+			return
+		}
+		if nil != item.Checked {
+			prependTaskListItemMarker(li, *item.Checked)
+		}
+		m.setIAL(li, item)
+		ret.AppendChild(li)
+		m.appendIAL(li)
+	}
+	finishListNode(ret)
+	return
+}
+
+func (m *mdastImporter) table(n *ast.MdastNode) (ret *ast.Node, err error) {
+	var aligns []int
+	for _, align := range n.Align {
+		a := 0
+		if nil != align {
+			switch *align {
+			case "left":
+				a = 1
+			case "center":
+				a = 2
+			case "right":
+				a = 3
+			}
+		}
+		aligns = append(aligns, a)
+	}
+
+	ret = newTableNode(aligns)
+	for _, row := range n.Children {
+		if "tableRow" != row.Type {
+			return nil, fmt.Errorf("invalid mdast table child type [%s]", row.Type)
+		}
+
+		if err = appendTableRow(ret, len(row.Children), func(i int, cell *ast.Node) error {
+			return m.appendChildren(cell, row.Children[i])
+		}); nil != err {
+			return
+		}
+	}
+	return
+}
+
+func (m *mdastImporter) setIAL(node *ast.Node, n *ast.MdastNode) {
+	ial, ok := n.Data["kramdownIAL"].([]interface{})
+	if !ok {
+		return
+	}
+	for _, kv := range ial {
+		pair, ok := kv.([]interface{})
+		if !ok || 2 != len(pair) {
+			continue
+		}
+		k, _ := pair[0].(string)
+		v, _ := pair[1].(string)
+		node.KramdownIAL = append(node.KramdownIAL, []string{k, v})
+	}
+}
+
+// appendIAL 在块节点后插入 IAL 节点，和解析 Markdown 时生成的结构保持一致。
+func (m *mdastImporter) appendIAL(node *ast.Node) {
+	if !m.tree.Context.ParseOption.KramdownBlockIAL || 1 > len(node.KramdownIAL) || !node.IsBlock() {
+		return
+	}
+	node.ID = node.IALAttr("id")
+	node.InsertAfter(&ast.Node{Type: ast.NodeKramdownBlockIAL, Tokens: IAL2Tokens(node.KramdownIAL)})
+}
+
+func mdastLabel(n *ast.MdastNode) string {
+	if "" != n.Label {
+		return n.Label
+	}
+	return n.Identifier
+}
+
+func mdastValue(n *ast.MdastNode) string {
+	return mdastValuePtr(n.Value)
+}
+
+func mdastValuePtr(s *string) string {
+	if nil == s {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,85 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import "strings"
+
+// MergeText 以 base 为共同祖先，按照词（和 DiffText 一致）三方合并文本 ours 和 theirs。
+//
+// 双方修改了同一处文本并且修改内容不同时合并失败，此时 ok 为 false。
+func MergeText(base, ours, theirs string) (ret string, ok bool) {
+	if ours == theirs || base == theirs {
+		return ours, true
+	}
+	if base == ours {
+		return theirs, true
+	}
+
+	b, o, t := diffTokens(base), diffTokens(ours), diffTokens(theirs)
+	oursMatches, theirsMatches := mergeMatches(b, o), mergeMatches(b, t)
+	buf := &strings.Builder{}
+	i, j, k := 0, 0, 0
+	for {
+		// 输出双方都未修改的部分
+		for i < len(b) && oursMatches[i] == j && theirsMatches[i] == k {
+			buf.WriteString(b[i])
+			i, j, k = i+1, j+1, k+1
+		}
+
+		// 找到下一个双方都保留的词作为同步点，同步点之前为有修改的部分
+		next, nextOurs, nextTheirs := i, len(o), len(t)
+		for ; next < len(b); next++ {
+			if -1 != oursMatches[next] && -1 != theirsMatches[next] {
+				nextOurs, nextTheirs = oursMatches[next], theirsMatches[next]
+				break
+			}
+		}
+		baseChunk, oursChunk, theirsChunk := strings.Join(b[i:next], ""), strings.Join(o[j:nextOurs], ""), strings.Join(t[k:nextTheirs], "")
+		switch {
+		case oursChunk == theirsChunk, baseChunk == theirsChunk:
+			buf.WriteString(oursChunk)
+		case baseChunk == oursChunk:
+			buf.WriteString(theirsChunk)
+		default:
+			return "", false
+		}
+
+		if next == len(b) {
+			break
+		}
+		i, j, k = next, nextOurs, nextTheirs
+	}
+	return buf.String(), true
+}
+
+// mergeMatches 返回词序列 base 中的每个词在词序列 other 中对应的下标，base 中的词在 other 中被删除时为 -1。
+func mergeMatches(base, other []string) (ret []int) {
+	ret = make([]int, len(base))
+	lcs := diffLCSTable(base, other)
+	i, j := 0, 0
+	for i < len(base) && j < len(other) {
+		switch {
+		case base[i] == other[j]:
+			ret[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ret[i] = -1
+			i++
+		default:
+			j++
+		}
+	}
+	for ; i < len(base); i++ {
+		ret[i] = -1
+	}
+	return
+}
@@ -0,0 +1,726 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+)
+
+// ParsePandocJSON 将 Pandoc JSON AST（pandoc -t json）解析为语法树，生成的节点结构和解析 Markdown 得到的结构一致，可以直接交给各个渲染器渲染。
+//
+// 使用 Div/Span 表示的 Lute 特有节点会被还原，其他 Div/Span 会被展开，Lute 不支持的元素（比如下划线、引用）只保留其中的内容。
+func ParsePandocJSON(name string, data []byte, options *Options) (tree *Tree, err error) {
+	doc := &struct {
+		APIVersion []int            `json:"pandoc-api-version"`
+		Blocks     []*pandocElement `json:"blocks"`
+	}{}
+	if err = json.Unmarshal(data, doc); nil != err {
+		return
+	}
+	version := ast.PandocAPIVersion
+	if 2 > len(doc.APIVersion) || version[0] != doc.APIVersion[0] || version[1] > doc.APIVersion[1] {
+		return nil, fmt.Errorf("unsupported pandoc api version %v", doc.APIVersion)
+	}
+
+	tree = &Tree{Name: name, Context: &Context{ParseOption: options}}
+	tree.Context.Tree = tree
+	p := &pandocImporter{tree: tree}
+	tree.Root = &ast.Node{Type: ast.NodeDocument}
+	if err = p.appendBlocks(tree.Root, doc.Blocks); nil != err {
+		return nil, err
+	}
+	if nil != p.footnotesDefBlock {
+		tree.Root.AppendChild(p.footnotesDefBlock)
+	}
+	tree.linkFootnotesRefs(p.footnotesRefs)
+	return
+}
+
+// pandocImporter 用于将 Pandoc 元素转换为语法树节点。
+type pandocImporter struct {
+	tree              *Tree
+	footnotesDefBlock *ast.Node   // 脚注定义块，Note 会转换为脚注定义放到文档末尾
+	footnotesRefs     []*ast.Node // 脚注引用
+}
+
+// pandocElement 描述了 Pandoc JSON AST 中的块级或者行级元素，C 需要根据 T 进一步解析。
+type pandocElement struct {
+	T string          `json:"t"`
+	C json.RawMessage `json:"c"`
+}
+
+// args 将元素内容数组依次解析到 v 中。
+func (e *pandocElement) args(v ...interface{}) (err error) {
+	var c []json.RawMessage
+	if err = json.Unmarshal(e.C, &c); nil != err {
+		return fmt.Errorf("invalid pandoc element [%s]: %s", e.T, err)
+	}
+	if len(c) < len(v) {
+		return fmt.Errorf("invalid pandoc element [%s]: expected %d arguments but got %d", e.T, len(v), len(c))
+	}
+	for i, arg := range v {
+		if err = json.Unmarshal(c[i], arg); nil != err {
+			return fmt.Errorf("invalid pandoc element [%s]: %s", e.T, err)
+		}
+	}
+	return
+}
+
+// content 将元素内容解析到 v 中。
+func (e *pandocElement) content(v interface{}) (err error) {
+	if err = json.Unmarshal(e.C, v); nil != err {
+		return fmt.Errorf("invalid pandoc element [%s]: %s", e.T, err)
+	}
+	return
+}
+
+// pandocAttr 描述了 Pandoc 元素属性 [id, [classes], [[key, value]]]。
+type pandocAttr struct {
+	ID      string
+	Classes []string
+	KVs     [][]string
+}
+
+func (a *pandocAttr) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &[]interface{}{&a.ID, &a.Classes, &a.KVs})
+}
+
+func (a *pandocAttr) hasClass(class string) bool {
+	for _, c := range a.Classes {
+		if class == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *pandocAttr) value(key string) string {
+	for _, kv := range a.KVs {
+		if 2 == len(kv) && key == kv[0] {
+			return kv[1]
+		}
+	}
+	return ""
+}
+
+// pandocRow 描述了 Pandoc 表格行 [attr, [cells]]。
+type pandocRow struct {
+	Cells []*pandocCell
+}
+
+func (r *pandocRow) UnmarshalJSON(data []byte) error {
+	var attr pandocAttr
+	return json.Unmarshal(data, &[]interface{}{&attr, &r.Cells})
+}
+
+// pandocCell 描述了 Pandoc 表格单元格 [attr, alignment, rowSpan, colSpan, [blocks]]。
+type pandocCell struct {
+	Blocks []*pandocElement
+}
+
+func (c *pandocCell) UnmarshalJSON(data []byte) error {
+	var attr pandocAttr
+	var align pandocElement
+	var rowSpan, colSpan int
+	return json.Unmarshal(data, &[]interface{}{&attr, &align, &rowSpan, &colSpan, &c.Blocks})
+}
+
+func (p *pandocImporter) appendBlocks(parent *ast.Node, blocks []*pandocElement) error {
+	for _, b := range blocks {
+		if err := p.appendBlock(parent, b); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pandocImporter) appendBlock(parent *ast.Node, b *pandocElement) (err error) {
+	var node *ast.Node
+	switch b.T {
+	case "Plain", "Para":
+		var inlines []*pandocElement
+		if err = b.content(&inlines); nil != err {
+			return
+		}
+		if 1 == len(inlines) && "Math" == inlines[0].T {
+			// 只包含 DisplayMath 的段落是块级公式
+			var mathType pandocElement
+			var text string
+			if err = inlines[0].args(&mathType, &text); nil != err {
+				return
+			}
+			if "DisplayMath" == mathType.T {
+				node = newMathBlockNode(text)
+				break
+			}
+		}
+		node = &ast.Node{Type: ast.NodeParagraph}
+		err = p.appendInlines(node, inlines)
+	case "LineBlock":
+		var lines [][]*pandocElement
+		if err = b.content(&lines); nil != err {
+			return
+		}
+		node = &ast.Node{Type: ast.NodeParagraph}
+		for i, line := range lines {
+			if 0 < i {
+				node.AppendChild(&ast.Node{Type: ast.NodeHardBreak, Tokens: []byte("\n")})
+			}
+			if err = p.appendInlines(node, line); nil != err {
+				return
+			}
+		}
+	case "CodeBlock":
+		var attr pandocAttr
+		var text string
+		if err = b.args(&attr, &text); nil != err {
+			return
+		}
+		node = newCodeBlockNode(strings.Join(attr.Classes, " "), text)
+	case "RawBlock":
+		var format, text string
+		if err = b.args(&format, &text); nil != err {
+			return
+		}
+		if "html" != format {
+			// 和 Pandoc 的 Markdown 输出一致，忽略其他格式的原始内容
+			return
+		}
+		node = &ast.Node{Type: ast.NodeHTMLBlock, Tokens: []byte(text)}
+	case "BlockQuote":
+		var blocks []*pandocElement
+		if err = b.content(&blocks); nil != err {
+			return
+		}
+		node = newBlockquoteNode()
+		err = p.appendBlocks(node, blocks)
+	case "OrderedList":
+		var listAttrs struct {
+			Start     int
+			Style     pandocElement
+			Delimiter pandocElement
+		}
+		var items [][]*pandocElement
+		if err = b.args(&[]interface{}{&listAttrs.Start, &listAttrs.Style, &listAttrs.Delimiter}, &items); nil != err {
+			return
+		}
+		delimiter := byte('.')
+		if "OneParen" == listAttrs.Delimiter.T || "TwoParens" == listAttrs.Delimiter.T {
+			delimiter = ')'
+		}
+		node, err = p.list(true, listAttrs.Start, delimiter, items)
+	case "BulletList":
+		var items [][]*pandocElement
+		if err = b.content(&items); nil != err {
+			return
+		}
+		node, err = p.list(false, 1, 0, items)
+	case "DefinitionList":
+		// 定义列表展开为术语段落和定义内容
+		var items []json.RawMessage
+		if err = b.content(&items); nil != err {
+			return
+		}
+		for _, item := range items {
+			var inlines []*pandocElement
+			var definitions [][]*pandocElement
+			if err = json.Unmarshal(item, &[]interface{}{&inlines, &definitions}); nil != err {
+				return fmt.Errorf("invalid pandoc element [%s]: %s", b.T, err)
+			}
+			term := &ast.Node{Type: ast.NodeParagraph}
+			if err = p.appendInlines(term, inlines); nil != err {
+				return
+			}
+			parent.AppendChild(term)
+			for _, definition := range definitions {
+				if err = p.appendBlocks(parent, definition); nil != err {
+					return
+				}
+			}
+		}
+		return
+	case "Header":
+		var level int
+		var attr pandocAttr
+		var inlines []*pandocElement
+		if err = b.args(&level, &attr, &inlines); nil != err {
+			return
+		}
+		if 1 > level || 6 < level {
+			return fmt.Errorf("invalid pandoc header level [%d]", level)
+		}
+		node = newHeadingNode(level)
+		if err = p.appendInlines(node, inlines); nil != err {
+			return
+		}
+		if "" != attr.ID {
+			node.AppendChild(&ast.Node{Type: ast.NodeHeadingID, Tokens: []byte("#" + attr.ID)})
+		}
+	case "HorizontalRule":
+		node = &ast.Node{Type: ast.NodeThematicBreak}
+	case "Table":
+		node, err = p.table(b)
+	case "Div":
+		var attr pandocAttr
+		var blocks []*pandocElement
+		if err = b.args(&attr, &blocks); nil != err {
+			return
+		}
+		if attr.hasClass(ast.PandocLuteClass) {
+			node, err = p.luteNode(&attr, func(node *ast.Node) error {
+				for _, block := range blocks {
+					if "Plain" == block.T {
+						// 块级节点下的行级节点渲染时使用 Plain 包裹，这里需要展开
+						var inlines []*pandocElement
+						if err := block.content(&inlines); nil != err {
+							return err
+						}
+						if err := p.appendInlines(node, inlines); nil != err {
+							return err
+						}
+						continue
+					}
+					if err := p.appendBlock(node, block); nil != err {
+						return err
+					}
+				}
+				return nil
+			})
+			break
+		}
+
+		last := parent.LastChild
+		if err = p.appendBlocks(parent, blocks); nil != err {
+			return
+		}
+		if attr.hasClass(ast.PandocLuteIALClass) {
+			first := parent.FirstChild
+			if nil != last {
+				first = last.Next
+			}
+			if nil != first {
+				first.KramdownIAL = attr.KVs
+			}
+		}
+		return
+	case "Figure":
+		var attr pandocAttr
+		var caption json.RawMessage
+		var blocks []*pandocElement
+		if err = b.args(&attr, &caption, &blocks); nil != err {
+			return
+		}
+		return p.appendBlocks(parent, blocks)
+	case "Null":
+		return
+	default:
+		return fmt.Errorf("unknown pandoc block type [%s]", b.T)
+	}
+	if nil != err {
+		return
+	}
+	parent.AppendChild(node)
+	return
+}
+
+func (p *pandocImporter) list(ordered bool, start int, delimiter byte, items [][]*pandocElement) (ret *ast.Node, err error) {
+	// Pandoc 紧凑列表的列表项使用 Plain，任务列表项以 ☒ 或者 ☐ 开头
+	tight, task := true, false
+	checks := make([]*bool, len(items))
+	for i, item := range items {
+		if 0 < len(item) && "Para" == item[0].T {
+			tight = false
+		}
+		if checks[i], err = p.stripTaskMarker(item); nil != err {
+			return
+		}
+		if nil != checks[i] {
+			task = true
+		}
+	}
+
+	ret = newListNode(ordered, start, delimiter, tight, task)
+	for i, item := range items {
+		li := newListItemNode(ret, i, checks[i])
+		var blocks, listBlocks []*pandocElement
+		for _, block := range item {
+			if "Div" == block.T {
+				var attr pandocAttr
+				if err = block.args(&attr); nil != err {
+					return
+				}
+				if attr.hasClass(ast.PandocLuteListClass) {
+					listBlocks = append(listBlocks, block)
+					continue
+				}
+			}
+			blocks = append(blocks, block)
+		}
+		if err = p.appendBlocks(li, blocks); nil != err {
+			return
+		}
+		if nil != checks[i] {
+			prependTaskListItemMarker(li, *checks[i])
+		}
+		ret.AppendChild(li)
+
+		// 还原列表下的 Lute 特有节点
+		if err = p.appendBlocks(ret, listBlocks); nil != err {
+			return
+		}
+		if ial := li.Next; nil != ial && ast.NodeKramdownBlockIAL == ial.Type {
+			li.KramdownIAL = Tokens2IAL(ial.Tokens)
+		}
+	}
+	finishListNode(ret)
+	return
+}
+
+// stripTaskMarker 判断列表项 item 是否以 ☒ 或者 ☐ 开头，如果是的话移除该标记并返回是否勾选。
+func (p *pandocImporter) stripTaskMarker(item []*pandocElement) (checked *bool, err error) {
+	if 1 > len(item) || ("Plain" != item[0].T && "Para" != item[0].T) {
+		return
+	}
+	var inlines []*pandocElement
+	if err = item[0].content(&inlines); nil != err {
+		return
+	}
+	if 1 > len(inlines) || "Str" != inlines[0].T {
+		return
+	}
+	var str string
+	if err = inlines[0].content(&str); nil != err {
+		return
+	}
+
+	var marker string
+	switch {
+	case strings.HasPrefix(str, "☒"):
+		marker = "☒"
+	case strings.HasPrefix(str, "☐"):
+		marker = "☐"
+	default:
+		return
+	}
+	c := "☒" == marker
+	checked = &c
+	if str = strings.TrimPrefix(str, marker); "" == str {
+		inlines = inlines[1:]
+		if 0 < len(inlines) && "Space" == inlines[0].T {
+			inlines = inlines[1:]
+		}
+	} else {
+		inlines[0].C, _ = json.Marshal(str)
+	}
+	item[0].C, err = json.Marshal(inlines)
+	return
+}
+
+func (p *pandocImporter) table(b *pandocElement) (ret *ast.Node, err error) {
+	var attr pandocAttr
+	var caption, head, foot json.RawMessage
+	var colSpecs [][]*pandocElement
+	var bodies []json.RawMessage
+	if err = b.args(&attr, &caption, &colSpecs, &head, &bodies, &foot); nil != err {
+		return
+	}
+
+	var rows, headRows, bodyRows, footRows []*pandocRow
+	if err = json.Unmarshal(head, &[]interface{}{&attr, &headRows}); nil != err {
+		return nil, fmt.Errorf("invalid pandoc table head: %s", err)
+	}
+	rows = append(rows, headRows...)
+	for _, body := range bodies {
+		var rowHeadColumns int
+		headRows, bodyRows = nil, nil
+		if err = json.Unmarshal(body, &[]interface{}{&attr, &rowHeadColumns, &headRows, &bodyRows}); nil != err {
+			return nil, fmt.Errorf("invalid pandoc table body: %s", err)
+		}
+		rows = append(rows, headRows...)
+		rows = append(rows, bodyRows...)
+	}
+	if err = json.Unmarshal(foot, &[]interface{}{&attr, &footRows}); nil != err {
+		return nil, fmt.Errorf("invalid pandoc table foot: %s", err)
+	}
+	rows = append(rows, footRows...)
+
+	var aligns []int
+	for _, colSpec := range colSpecs {
+		align := 0
+		if 0 < len(colSpec) {
+			switch colSpec[0].T {
+			case "AlignLeft":
+				align = 1
+			case "AlignCenter":
+				align = 2
+			case "AlignRight":
+				align = 3
+			}
+		}
+		aligns = append(aligns, align)
+	}
+
+	// Markdown 表格必须有表头，没有表头时使用第一行作为表头
+	ret = newTableNode(aligns)
+	for _, r := range rows {
+		cells := r.Cells
+		if err = appendTableRow(ret, len(cells), func(i int, cell *ast.Node) error {
+			return p.appendCellBlocks(cell, cells[i].Blocks)
+		}); nil != err {
+			return
+		}
+	}
+	return
+}
+
+// appendCellBlocks 添加表格单元格内容，单元格中只能包含行级节点，多个块之间使用空格分隔。
+func (p *pandocImporter) appendCellBlocks(cell *ast.Node, blocks []*pandocElement) error {
+	for i, block := range blocks {
+		var inlines []*pandocElement
+		switch block.T {
+		case "Plain", "Para":
+			if err := block.content(&inlines); nil != err {
+				return err
+			}
+		case "Div":
+			var attr pandocAttr
+			var children []*pandocElement
+			if err := block.args(&attr, &children); nil != err {
+				return err
+			}
+			if err := p.appendCellBlocks(cell, children); nil != err {
+				return err
+			}
+			continue
+		default:
+			// 单元格中无法表示的块级元素被忽略
+			continue
+		}
+		if 0 < i {
+			appendTextNode(cell, " ")
+		}
+		if err := p.appendInlines(cell, inlines); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pandocImporter) appendInlines(parent *ast.Node, inlines []*pandocElement) error {
+	for _, inline := range inlines {
+		if err := p.appendInline(parent, inline); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// inlines 返回用于添加 e 的子元素的函数，e 的内容为行级元素数组。
+func (p *pandocImporter) inlines(e *pandocElement) func(node *ast.Node) error {
+	return func(node *ast.Node) error {
+		var inlines []*pandocElement
+		if err := e.content(&inlines); nil != err {
+			return err
+		}
+		return p.appendInlines(node, inlines)
+	}
+}
+
+func (p *pandocImporter) appendInline(parent *ast.Node, e *pandocElement) (err error) {
+	var node *ast.Node
+	switch e.T {
+	case "Str":
+		var text string
+		if err = e.content(&text); nil != err {
+			return
+		}
+		appendTextNode(parent, text)
+		return
+	case "Space":
+		appendTextNode(parent, " ")
+		return
+	case "SoftBreak":
+		appendTextNode(parent, "\n")
+		return
+	case "LineBreak":
+		node = &ast.Node{Type: ast.NodeHardBreak, Tokens: []byte("\n")}
+	case "Emph":
+		node, err = newDelimitedNode(ast.NodeEmphasis, ast.NodeEmA6kOpenMarker, ast.NodeEmA6kCloseMarker, "*", p.inlines(e))
+	case "Strong":
+		node, err = newDelimitedNode(ast.NodeStrong, ast.NodeStrongA6kOpenMarker, ast.NodeStrongA6kCloseMarker, "**", p.inlines(e))
+	case "Strikeout":
+		node, err = newDelimitedNode(ast.NodeStrikethrough, ast.NodeStrikethrough2OpenMarker, ast.NodeStrikethrough2CloseMarker, "~~", p.inlines(e))
+	case "Superscript":
+		node, err = newDelimitedNode(ast.NodeSup, ast.NodeSupOpenMarker, ast.NodeSupCloseMarker, "^", p.inlines(e))
+	case "Subscript":
+		node, err = newDelimitedNode(ast.NodeSub, ast.NodeSubOpenMarker, ast.NodeSubCloseMarker, "~", p.inlines(e))
+	case "Underline", "SmallCaps":
+		return p.inlines(e)(parent)
+	case "Quoted":
+		var quoteType pandocElement
+		var inlines []*pandocElement
+		if err = e.args(&quoteType, &inlines); nil != err {
+			return
+		}
+		quote := "\""
+		if "SingleQuote" == quoteType.T {
+			quote = "'"
+		}
+		appendTextNode(parent, quote)
+		if err = p.appendInlines(parent, inlines); nil != err {
+			return
+		}
+		appendTextNode(parent, quote)
+		return
+	case "Cite":
+		var citations json.RawMessage
+		var inlines []*pandocElement
+		if err = e.args(&citations, &inlines); nil != err {
+			return
+		}
+		return p.appendInlines(parent, inlines)
+	case "Code":
+		var attr pandocAttr
+		var text string
+		if err = e.args(&attr, &text); nil != err {
+			return
+		}
+		node = newCodeSpanNode(text)
+	case "Math":
+		var mathType pandocElement
+		var text string
+		if err = e.args(&mathType, &text); nil != err {
+			return
+		}
+		node = newInlineMathNode(text)
+	case "RawInline":
+		var format, text string
+		if err = e.args(&format, &text); nil != err {
+			return
+		}
+		if "html" != format {
+			return
+		}
+		node = &ast.Node{Type: ast.NodeInlineHTML, Tokens: []byte(text)}
+	case "Link", "Image":
+		var attr pandocAttr
+		var inlines []*pandocElement
+		var target []string
+		if err = e.args(&attr, &inlines, &target); nil != err {
+			return
+		}
+		if 2 != len(target) {
+			return fmt.Errorf("invalid pandoc element [%s] target", e.T)
+		}
+		var title *string
+		if "" != target[1] {
+			title = &target[1]
+		}
+		typ := ast.NodeLink
+		if "Image" == e.T {
+			typ = ast.NodeImage
+		}
+		node, err = newLinkNode(typ, target[0], title, 0, func(node *ast.Node) error {
+			if ast.NodeImage == typ {
+				// 图片的替代文本只保留纯文本
+				appendTextNode(node, pandocText(inlines))
+				return nil
+			}
+			return p.appendInlines(node, inlines)
+		})
+	case "Note":
+		var blocks []*pandocElement
+		if err = e.content(&blocks); nil != err {
+			return
+		}
+		if nil == p.footnotesDefBlock {
+			p.footnotesDefBlock = &ast.Node{Type: ast.NodeFootnotesDefBlock}
+		}
+		label := []byte("^" + strconv.Itoa(len(p.footnotesRefs)+1))
+		def := &ast.Node{Type: ast.NodeFootnotesDef, Tokens: label}
+		if err = p.appendBlocks(def, blocks); nil != err {
+			return
+		}
+		p.footnotesDefBlock.AppendChild(def)
+		node = &ast.Node{Type: ast.NodeFootnotesRef, Tokens: label, FootnotesRefLabel: label}
+		p.footnotesRefs = append(p.footnotesRefs, node)
+	case "Span":
+		var attr pandocAttr
+		var inlines []*pandocElement
+		if err = e.args(&attr, &inlines); nil != err {
+			return
+		}
+		if !attr.hasClass(ast.PandocLuteClass) {
+			return p.appendInlines(parent, inlines)
+		}
+		node, err = p.luteNode(&attr, func(node *ast.Node) error {
+			return p.appendInlines(node, inlines)
+		})
+	default:
+		return fmt.Errorf("unknown pandoc inline type [%s]", e.T)
+	}
+	if nil != err {
+		return
+	}
+	parent.AppendChild(node)
+	return
+}
+
+// luteNode 还原使用 Div/Span 表示的 Lute 特有节点，children 用于添加子节点。
+func (p *pandocImporter) luteNode(attr *pandocAttr, children func(node *ast.Node) error) (ret *ast.Node, err error) {
+	name := attr.value(ast.PandocLuteTypeKey)
+	typ := ast.Str2NodeType("Node" + name)
+	if 0 > typ {
+		return nil, fmt.Errorf("unknown pandoc lute node type [%s]", name)
+	}
+	ret = &ast.Node{Type: typ, ID: attr.ID}
+	if tokens := attr.value(ast.PandocLuteTokensKey); "" != tokens {
+		ret.Tokens = []byte(tokens)
+	}
+	err = children(ret)
+	return
+}
+
+// pandocText 返回行级元素 inlines 的纯文本。
+func pandocText(inlines []*pandocElement) string {
+	buf := &strings.Builder{}
+	for _, inline := range inlines {
+		switch inline.T {
+		case "Str":
+			var text string
+			json.Unmarshal(inline.C, &text)
+			buf.WriteString(text)
+		case "Space", "SoftBreak", "LineBreak":
+			buf.WriteByte(' ')
+		case "Code", "Math":
+			var text string
+			inline.args(new(json.RawMessage), &text)
+			buf.WriteString(text)
+		default:
+			var c []json.RawMessage
+			if nil != json.Unmarshal(inline.C, &c) {
+				continue
+			}
+			for _, arg := range c {
+				var children []*pandocElement
+				if nil == json.Unmarshal(arg, &children) {
+					buf.WriteString(pandocText(children))
+				}
+			}
+		}
+	}
+	return buf.String()
+}
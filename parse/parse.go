@@ -11,6 +11,10 @@
 package parse
 
 import (
+	"bytes"
+	"context"
+	"unicode/utf8"
+
 	"github.com/88250/lute/ast"
 	"github.com/88250/lute/lex"
 )
@@ -27,15 +31,62 @@ const (
 func Parse(name string, markdown []byte, options *Options) (tree *Tree) {
 	tree = &Tree{Name: name, Context: &Context{ParseOption: options}}
 	tree.Context.Tree = tree
-	tree.lexer = lex.NewLexer(markdown)
-	tree.Root = &ast.Node{Type: ast.NodeDocument}
-	tree.parseBlocks()
-	tree.parseInlines()
-	tree.finalParseBlockIAL()
-	tree.lexer = nil
+	tree.parse(markdown)
+	return
+}
+
+// ParseWithContext 会将 markdown 原始文本字节数组解析为一棵语法树，解析过程中会检查 ctx 是否已经取消或者超时。
+// 如果 ctx 在解析过程中被取消，剩余未解析的内容会作为文本段落挂到树上，并返回 ctx.Err()。
+func ParseWithContext(ctx context.Context, name string, markdown []byte, options *Options) (tree *Tree, err error) {
+	tree = &Tree{Name: name, Context: &Context{ParseOption: options, done: ctx.Done()}}
+	tree.Context.Tree = tree
+	tree.parse(markdown)
+	if tree.Context.aborted {
+		err = ctx.Err()
+	}
 	return
 }
 
+func (t *Tree) parse(markdown []byte) {
+	t.lexer = lex.NewLexer(t.limitInput(markdown))
+	t.Root = &ast.Node{Type: ast.NodeDocument}
+	t.parseBlocks()
+	t.parseInlines()
+	t.appendRaw()
+	t.finalParseBlockIAL()
+	t.lexer = nil
+}
+
+// limitInput 按照 MaxInputSize 截断 markdown，超出的部分暂存到 raw 中不进行解析。
+func (t *Tree) limitInput(markdown []byte) []byte {
+	max := t.Context.ParseOption.MaxInputSize
+	if 1 > max || len(markdown) <= max {
+		return markdown
+	}
+
+	t.Context.limitExceeded = true
+	end := bytes.LastIndexByte(markdown[:max], lex.ItemNewline) + 1
+	if 1 > end { // 没有换行的话在字符边界上截断
+		for end = max; 0 < end && !utf8.RuneStart(markdown[end]); end-- {
+		}
+	}
+	t.Context.raw = markdown[end:]
+	return markdown[:end:end] // 限制容量，避免词法分析器追加换行时覆盖 raw
+}
+
+// appendRaw 将未解析的原始文本作为文本段落添加到根节点上。
+func (t *Tree) appendRaw() {
+	raw := lex.TrimWhitespace(t.Context.raw)
+	if 1 > len(raw) {
+		return
+	}
+
+	p := &ast.Node{Type: ast.NodeParagraph}
+	p.AppendChild(&ast.Node{Type: ast.NodeText, Tokens: raw})
+	t.Root.AppendChild(p)
+	t.Context.raw = nil
+}
+
 func (t *Tree) finalParseBlockIAL() {
 	if !t.Context.ParseOption.KramdownBlockIAL {
 		return
@@ -73,9 +124,10 @@ func (t *Tree) finalParseBlockIAL() {
 func Block(name string, markdown []byte, options *Options) (tree *Tree) {
 	tree = &Tree{Name: name, Context: &Context{ParseOption: options}}
 	tree.Context.Tree = tree
-	tree.lexer = lex.NewLexer(markdown)
+	tree.lexer = lex.NewLexer(tree.limitInput(markdown))
 	tree.Root = &ast.Node{Type: ast.NodeDocument}
 	tree.parseBlocks()
+	tree.appendRaw()
 	tree.finalParseBlockIAL()
 	tree.lexer = nil
 	return
@@ -106,6 +158,12 @@ type Context struct {
 	lastMatchedContainer                                     *ast.Node // 最后一个匹配的块节点
 
 	rootIAL *ast.Node // 根节点 kramdown IAL
+
+	done          <-chan struct{} // 调用方 context.Context 的取消信号
+	aborted       bool            // 解析是否已经被取消
+	limitExceeded bool            // 解析时是否超出了解析选项中设置的限制
+	linkRefDefs   int             // 已经解析的链接引用定义个数
+	raw           []byte          // 不进行解析的剩余原始文本
 }
 
 // InlineContext 描述了行级元素解析上下文。
@@ -115,6 +173,62 @@ type InlineContext struct {
 	pos        int        // 当前解析到的 token 位置
 	delimiters *delimiter // 分隔符栈，用于强调解析
 	brackets   *delimiter // 括号栈，用于图片和链接解析
+
+	delimitersLen int // 分隔符栈深度
+	bracketsLen   int // 括号栈深度
+}
+
+// LimitExceeded 判断解析时是否超出了解析选项中设置的限制（MaxNestingDepth、MaxDelimiters、MaxInputSize 和 MaxLinkRefDefs）。
+func (context *Context) LimitExceeded() bool {
+	return context.limitExceeded
+}
+
+// canceled 判断解析是否已经被调用方取消。
+func (context *Context) canceled() bool {
+	if context.aborted {
+		return true
+	}
+	if nil == context.done {
+		return false
+	}
+
+	select {
+	case <-context.done:
+		context.aborted = true
+	default:
+	}
+	return context.aborted
+}
+
+// exceedNestingDepth 判断在 container 下再添加一层块级容器是否会超出最大嵌套层数 MaxNestingDepth。
+func (context *Context) exceedNestingDepth(container *ast.Node) bool {
+	max := context.ParseOption.MaxNestingDepth
+	if 1 > max {
+		return false
+	}
+
+	depth := 1
+	for n := container; nil != n; n = n.Parent {
+		switch n.Type {
+		case ast.NodeBlockquote, ast.NodeListItem, ast.NodeSuperBlock, ast.NodeFootnotesDef:
+			depth++
+		}
+	}
+	if max < depth {
+		context.limitExceeded = true
+		return true
+	}
+	return false
+}
+
+// exceedDelimiters 判断深度为 length 的分隔符栈或括号栈是否已满（MaxDelimiters）。
+func (context *Context) exceedDelimiters(length int) bool {
+	max := context.ParseOption.MaxDelimiters
+	if 1 > max || length < max {
+		return false
+	}
+	context.limitExceeded = true
+	return true
 }
 
 // advanceOffset 用于移动 count 个字符位置，columns 指定了遇到 tab 时是否需要空格进行补偿偏移。
@@ -298,9 +412,9 @@ type Options struct {
 	// Emoji 设置是否对 Emoji 别名替换为原生 Unicode 字符。
 	Emoji bool
 	// AliasEmoji 存储 ASCII 别名到表情 Unicode 映射。
-	AliasEmoji map[string]string
+	AliasEmoji map[string]string `json:"-"`
 	// EmojiAlias 存储表情 Unicode 到 ASCII 别名映射。
-	EmojiAlias map[string]string
+	EmojiAlias map[string]string `json:"-"`
 	// EmojiSite 设置图片 Emoji URL 的路径前缀。
 	EmojiSite string
 	// Vditor 所见即所得支持。
@@ -345,6 +459,18 @@ type Options struct {
 	IndentCodeBlock bool
 	// ParagraphBeginningSpace 设置是否打开“段首空格”支持。
 	ParagraphBeginningSpace bool
+	// CJKFriendlyEmphasis 设置是否启用中日韩友好的强调分隔符规则，标点符号和中日韩字符相邻时也可以作为强调的开始或结束。
+	CJKFriendlyEmphasis bool
+	// Strict 设置是否启用严格模式，严格模式下关闭所有和 CommonMark/GFM 规范不一致的解析行为，比如数学公式和自动链接域名后缀校验。
+	Strict bool
+	// MaxNestingDepth 设置块级容器（块引用、列表项、超级块和脚注定义）的最大嵌套层数，超出的部分作为段落文本解析，0 表示不限制。
+	MaxNestingDepth int
+	// MaxDelimiters 设置行级解析时分隔符栈（强调、加粗等）和括号栈（链接、图片）的最大深度，超出的分隔符作为文本解析，0 表示不限制。
+	MaxDelimiters int
+	// MaxInputSize 设置输入文本的最大字节数，超出的部分不进行解析，直接作为文本段落，0 表示不限制。
+	MaxInputSize int
+	// MaxLinkRefDefs 设置链接引用定义的最大个数，超出的链接引用定义作为段落文本解析，0 表示不限制。
+	MaxLinkRefDefs int
 }
 
 func NewOptions() *Options {
@@ -25,6 +25,10 @@ func SuperBlockStart(t *Tree, container *ast.Node) int {
 	}
 
 	if ok, layout := t.parseSuperBlock(); ok {
+		if t.Context.exceedNestingDepth(container) {
+			return 0
+		}
+
 		t.Context.closeUnmatchedBlocks()
 		t.Context.addChild(ast.NodeSuperBlock)
 		t.Context.addChildMarker(ast.NodeSuperBlockOpenMarker, nil)
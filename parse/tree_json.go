@@ -0,0 +1,299 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/util"
+)
+
+// TreeJSONSchema 是语法树 JSON 序列化格式的当前版本号，序列化格式发生不兼容变更时需要递增该版本号并在 migrateTreeJSON 中添加迁移逻辑。
+const TreeJSONSchema = 1
+
+// treeJSON 描述了语法树的 JSON 序列化结构。
+type treeJSON struct {
+	Schema int `json:"schema"` // 序列化格式版本号
+
+	Name    string   `json:"name,omitempty"`
+	ID      string   `json:"id,omitempty"`
+	Box     string   `json:"box,omitempty"`
+	Path    string   `json:"path,omitempty"`
+	HPath   string   `json:"hPath,omitempty"`
+	Marks   []string `json:"marks,omitempty"`
+	Created int64    `json:"created,omitempty"`
+	Updated int64    `json:"updated,omitempty"`
+	Hash    string   `json:"hash,omitempty"`
+
+	Root *nodeJSON `json:"root,omitempty"`
+}
+
+// nodeJSON 描述了节点的 JSON 序列化结构，和 ast.Node 的字段一一对应，节点之间的链接关系通过 Children 表示。
+type nodeJSON struct {
+	Type   string `json:"type"`
+	Data   string `json:"data,omitempty"`   // Tokens 是合法 UTF-8 文本时使用该字段
+	Tokens []byte `json:"tokens,omitempty"` // Tokens 不是合法 UTF-8 文本时使用该字段
+
+	ID   string `json:"id,omitempty"`
+	Box  string `json:"box,omitempty"`
+	Path string `json:"path,omitempty"`
+
+	Close           bool `json:"close,omitempty"`
+	LastLineBlank   bool `json:"lastLineBlank,omitempty"`
+	LastLineChecked bool `json:"lastLineChecked,omitempty"`
+
+	CodeMarkerLen        int    `json:"codeMarkerLen,omitempty"`
+	IsFencedCodeBlock    bool   `json:"isFencedCodeBlock,omitempty"`
+	CodeBlockFenceChar   byte   `json:"codeBlockFenceChar,omitempty"`
+	CodeBlockFenceLen    int    `json:"codeBlockFenceLen,omitempty"`
+	CodeBlockFenceOffset int    `json:"codeBlockFenceOffset,omitempty"`
+	CodeBlockOpenFence   []byte `json:"codeBlockOpenFence,omitempty"`
+	CodeBlockInfo        []byte `json:"codeBlockInfo,omitempty"`
+	CodeBlockCloseFence  []byte `json:"codeBlockCloseFence,omitempty"`
+
+	HtmlBlockType int `json:"htmlBlockType,omitempty"`
+
+	ListData            *ast.ListData `json:"listData,omitempty"`
+	TaskListItemChecked bool          `json:"taskListItemChecked,omitempty"`
+
+	TableAligns              []int `json:"tableAligns,omitempty"`
+	TableCellAlign           int   `json:"tableCellAlign,omitempty"`
+	TableCellContentWidth    int   `json:"tableCellContentWidth,omitempty"`
+	TableCellContentMaxWidth int   `json:"tableCellContentMaxWidth,omitempty"`
+
+	LinkType     int    `json:"linkType,omitempty"`
+	LinkRefLabel []byte `json:"linkRefLabel,omitempty"`
+
+	HeadingLevel        int    `json:"headingLevel,omitempty"`
+	HeadingSetext       bool   `json:"headingSetext,omitempty"`
+	HeadingNormalizedID string `json:"headingNormalizedID,omitempty"`
+
+	MathBlockDollarOffset int `json:"mathBlockDollarOffset,omitempty"`
+
+	FootnotesRefLabel []byte `json:"footnotesRefLabel,omitempty"`
+	FootnotesRefId    string `json:"footnotesRefId,omitempty"`
+	FootnotesRefs     []int  `json:"footnotesRefs,omitempty"` // 脚注引用节点在树中的先序遍历序号
+
+	HtmlEntityTokens []byte `json:"htmlEntityTokens,omitempty"`
+
+	KramdownIAL [][]string        `json:"kramdownIAL,omitempty"`
+	Properties  map[string]string `json:"properties,omitempty"`
+
+	Children []*nodeJSON `json:"children,omitempty"`
+}
+
+// MarshalJSON 将语法树序列化为 JSON，序列化结果包含树的元数据和所有节点字段，可以通过 UnmarshalJSON 无损还原。
+func (t *Tree) MarshalJSON() ([]byte, error) {
+	ret := &treeJSON{
+		Schema:  TreeJSONSchema,
+		Name:    t.Name,
+		ID:      t.ID,
+		Box:     t.Box,
+		Path:    t.Path,
+		HPath:   t.HPath,
+		Marks:   t.Marks,
+		Created: t.Created,
+		Updated: t.Updated,
+		Hash:    t.Hash,
+	}
+	if nil != t.Root {
+		// 脚注引用指向树中的其他节点，序列化时使用节点的先序遍历序号代替
+		indexes := map[*ast.Node]int{}
+		ast.Walk(t.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if entering {
+				indexes[n] = len(indexes)
+			}
+			return ast.WalkContinue
+		})
+		var err error
+		if ret.Root, err = marshalNodeJSON(t.Root, indexes); nil != err {
+			return nil, err
+		}
+	}
+	return json.Marshal(ret)
+}
+
+// UnmarshalJSON 从 MarshalJSON 的序列化结果还原语法树，节点之间的父子和兄弟链接会被重建。
+func (t *Tree) UnmarshalJSON(data []byte) (err error) {
+	tree := &treeJSON{}
+	if err = json.Unmarshal(data, tree); nil != err {
+		return
+	}
+	if err = migrateTreeJSON(tree); nil != err {
+		return
+	}
+
+	t.Name, t.ID, t.Box, t.Path, t.HPath = tree.Name, tree.ID, tree.Box, tree.Path, tree.HPath
+	t.Marks, t.Created, t.Updated, t.Hash = tree.Marks, tree.Created, tree.Updated, tree.Hash
+	if nil == t.Context {
+		t.Context = &Context{ParseOption: NewOptions()}
+	}
+	t.Context.Tree = t
+	t.Root = nil
+	if nil == tree.Root {
+		return
+	}
+
+	var nodes []*ast.Node
+	if t.Root, err = unmarshalNodeJSON(tree.Root, &nodes); nil != err {
+		return
+	}
+	return resolveFootnotesRefs(tree.Root, nodes, new(int))
+}
+
+// migrateTreeJSON 将旧版本的序列化结构迁移到当前版本。
+func migrateTreeJSON(tree *treeJSON) error {
+	if 1 > tree.Schema {
+		return fmt.Errorf("invalid tree json schema [%d]", tree.Schema)
+	}
+	if TreeJSONSchema < tree.Schema {
+		return fmt.Errorf("unsupported tree json schema [%d], the latest supported schema is [%d]", tree.Schema, TreeJSONSchema)
+	}
+
+	// 目前只有版本 1，以后的版本在这里按顺序逐级迁移
+	tree.Schema = TreeJSONSchema
+	return nil
+}
+
+func marshalNodeJSON(n *ast.Node, indexes map[*ast.Node]int) (ret *nodeJSON, err error) {
+	ret = &nodeJSON{
+		Type:                     n.Type.String(),
+		ID:                       n.ID,
+		Box:                      n.Box,
+		Path:                     n.Path,
+		Close:                    n.Close,
+		LastLineBlank:            n.LastLineBlank,
+		LastLineChecked:          n.LastLineChecked,
+		CodeMarkerLen:            n.CodeMarkerLen,
+		IsFencedCodeBlock:        n.IsFencedCodeBlock,
+		CodeBlockFenceChar:       n.CodeBlockFenceChar,
+		CodeBlockFenceLen:        n.CodeBlockFenceLen,
+		CodeBlockFenceOffset:     n.CodeBlockFenceOffset,
+		CodeBlockOpenFence:       n.CodeBlockOpenFence,
+		CodeBlockInfo:            n.CodeBlockInfo,
+		CodeBlockCloseFence:      n.CodeBlockCloseFence,
+		HtmlBlockType:            n.HtmlBlockType,
+		ListData:                 n.ListData,
+		TaskListItemChecked:      n.TaskListItemChecked,
+		TableAligns:              n.TableAligns,
+		TableCellAlign:           n.TableCellAlign,
+		TableCellContentWidth:    n.TableCellContentWidth,
+		TableCellContentMaxWidth: n.TableCellContentMaxWidth,
+		LinkType:                 n.LinkType,
+		LinkRefLabel:             n.LinkRefLabel,
+		HeadingLevel:             n.HeadingLevel,
+		HeadingSetext:            n.HeadingSetext,
+		HeadingNormalizedID:      n.HeadingNormalizedID,
+		MathBlockDollarOffset:    n.MathBlockDollarOffset,
+		FootnotesRefLabel:        n.FootnotesRefLabel,
+		FootnotesRefId:           n.FootnotesRefId,
+		HtmlEntityTokens:         n.HtmlEntityTokens,
+		KramdownIAL:              n.KramdownIAL,
+		Properties:               n.Properties,
+	}
+	if utf8.Valid(n.Tokens) {
+		ret.Data = util.BytesToStr(n.Tokens)
+	} else {
+		ret.Tokens = n.Tokens
+	}
+	for _, ref := range n.FootnotesRefs {
+		index, ok := indexes[ref]
+		if !ok {
+			return nil, fmt.Errorf("footnotes ref of node [%s] is not in the tree", n.Type)
+		}
+		ret.FootnotesRefs = append(ret.FootnotesRefs, index)
+	}
+	for c := n.FirstChild; nil != c; c = c.Next {
+		child, err := marshalNodeJSON(c, indexes)
+		if nil != err {
+			return nil, err
+		}
+		ret.Children = append(ret.Children, child)
+	}
+	return
+}
+
+func unmarshalNodeJSON(n *nodeJSON, nodes *[]*ast.Node) (ret *ast.Node, err error) {
+	typ := ast.Str2NodeType(n.Type)
+	if 0 > typ {
+		return nil, fmt.Errorf("unknown node type [%s]", n.Type)
+	}
+
+	ret = &ast.Node{
+		Type:                     typ,
+		Tokens:                   n.Tokens,
+		ID:                       n.ID,
+		Box:                      n.Box,
+		Path:                     n.Path,
+		Close:                    n.Close,
+		LastLineBlank:            n.LastLineBlank,
+		LastLineChecked:          n.LastLineChecked,
+		CodeMarkerLen:            n.CodeMarkerLen,
+		IsFencedCodeBlock:        n.IsFencedCodeBlock,
+		CodeBlockFenceChar:       n.CodeBlockFenceChar,
+		CodeBlockFenceLen:        n.CodeBlockFenceLen,
+		CodeBlockFenceOffset:     n.CodeBlockFenceOffset,
+		CodeBlockOpenFence:       n.CodeBlockOpenFence,
+		CodeBlockInfo:            n.CodeBlockInfo,
+		CodeBlockCloseFence:      n.CodeBlockCloseFence,
+		HtmlBlockType:            n.HtmlBlockType,
+		ListData:                 n.ListData,
+		TaskListItemChecked:      n.TaskListItemChecked,
+		TableAligns:              n.TableAligns,
+		TableCellAlign:           n.TableCellAlign,
+		TableCellContentWidth:    n.TableCellContentWidth,
+		TableCellContentMaxWidth: n.TableCellContentMaxWidth,
+		LinkType:                 n.LinkType,
+		LinkRefLabel:             n.LinkRefLabel,
+		HeadingLevel:             n.HeadingLevel,
+		HeadingSetext:            n.HeadingSetext,
+		HeadingNormalizedID:      n.HeadingNormalizedID,
+		MathBlockDollarOffset:    n.MathBlockDollarOffset,
+		FootnotesRefLabel:        n.FootnotesRefLabel,
+		FootnotesRefId:           n.FootnotesRefId,
+		HtmlEntityTokens:         n.HtmlEntityTokens,
+		KramdownIAL:              n.KramdownIAL,
+		Properties:               n.Properties,
+	}
+	if "" != n.Data {
+		ret.Tokens = []byte(n.Data)
+	}
+	*nodes = append(*nodes, ret)
+	for _, c := range n.Children {
+		child, err := unmarshalNodeJSON(c, nodes)
+		if nil != err {
+			return nil, err
+		}
+		ret.AppendChild(child)
+	}
+	return
+}
+
+// resolveFootnotesRefs 按照先序遍历序号还原脚注引用，index 是 n 对应节点的先序遍历序号。
+func resolveFootnotesRefs(n *nodeJSON, nodes []*ast.Node, index *int) error {
+	node := nodes[*index]
+	for _, ref := range n.FootnotesRefs {
+		if 0 > ref || len(nodes) <= ref {
+			return fmt.Errorf("invalid footnotes ref index [%d]", ref)
+		}
+		node.FootnotesRefs = append(node.FootnotesRefs, nodes[ref])
+	}
+	for _, c := range n.Children {
+		*index++
+		if err := resolveFootnotesRefs(c, nodes, index); nil != err {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"errors"
+	"sort"
+)
+
+// 内置预设名称。
+const (
+	PresetCommonMark = "commonmark" // 标准 CommonMark
+	PresetGFM        = "gfm"        // GitHub Flavored Markdown
+	PresetVditor     = "vditor"     // Vditor 编辑器
+	PresetSiYuan     = "siyuan"     // 思源笔记 Protyle 编辑器
+	PresetProtyle    = "protyle"    // 同 PresetSiYuan
+)
+
+// presets 定义了内置预设，预设在默认选项的基础上进行调整。
+var presets = map[string]ParseOption{
+	PresetCommonMark: func(lute *Lute) {
+		lute.SetStrict(true)
+		lute.SetGFMTable(false)
+		lute.SetGFMTaskListItem(false)
+		lute.SetGFMStrikethrough(false)
+		lute.SetGFMAutoLink(false)
+	},
+	PresetGFM: func(lute *Lute) {
+		lute.SetSoftBreak2HardBreak(false)
+		lute.SetAutoSpace(false)
+		lute.SetGFMTaskListItemClass("")
+		lute.SetGFMTagFilter(true)
+	},
+	PresetVditor: func(lute *Lute) {
+		lute.SetMark(true)
+		lute.SetToC(true)
+		lute.SetHeadingID(true)
+		lute.SetSanitize(true)
+	},
+	PresetSiYuan:  siyuanPreset,
+	PresetProtyle: siyuanPreset,
+}
+
+func siyuanPreset(lute *Lute) {
+	lute.SetProtyleWYSIWYG(true)
+	lute.SetKramdownIAL(true)
+	lute.SetBlockRef(true)
+	lute.SetFileAnnotationRef(true)
+	lute.SetMark(true)
+	lute.SetSuperBlock(true)
+	lute.SetTag(true)
+	lute.SetSup(true)
+	lute.SetSub(true)
+	lute.SetGitConflict(true)
+	lute.SetIndentCodeBlock(false)
+	lute.SetParagraphBeginningSpace(true)
+	lute.SetAutoSpace(true)
+}
+
+// Presets 返回所有内置预设名称。
+func Presets() (ret []string) {
+	for name := range presets {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return
+}
+
+// NewWithPreset 使用名为 name 的内置预设创建一个新的 Lute 引擎，opts 会在预设之后应用。
+func NewWithPreset(name string, opts ...ParseOption) (ret *Lute, err error) {
+	preset := presets[name]
+	if nil == preset {
+		return nil, errors.New("unknown preset [" + name + "]")
+	}
+
+	ret = New(append([]ParseOption{preset}, opts...)...)
+	return
+}
@@ -29,7 +29,7 @@ func (lute *Lute) SpinBlockDOM(ivHTML string) (ovHTML string) {
 	//fmt.Println(ivHTML)
 	markdown := lute.blockDOM2Md(ivHTML)
 	markdown = strings.ReplaceAll(markdown, parse.Zwsp, "")
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 
 	firstChild := tree.Root.FirstChild
 	if ast.NodeParagraph == firstChild.Type && "" == firstChild.ID {
@@ -61,7 +61,7 @@ func (lute *Lute) HTML2BlockDOM(sHTML string) (vHTML string) {
 		return
 	}
 
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	renderer := render.NewBlockRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.HTML2BlockDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -79,7 +79,7 @@ func (lute *Lute) BlockDOM2HTML(vHTML string) (sHTML string) {
 
 func (lute *Lute) BlockDOM2InlineBlockDOM(vHTML string) (vIHTML string) {
 	markdown := lute.blockDOM2Md(vHTML)
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	var inlines []*ast.Node
 	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
 		if !entering {
@@ -112,7 +112,13 @@ func (lute *Lute) BlockDOM2InlineBlockDOM(vHTML string) (vIHTML string) {
 }
 
 func (lute *Lute) Md2BlockDOM(markdown string) (vHTML string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
+	vHTML = lute.renderBlockDOM(tree)
+	return
+}
+
+// renderBlockDOM 将 tree 渲染为 Protyle DOM，Md2BlockDOM 和 Md2BlockDOME 共用。
+func (lute *Lute) renderBlockDOM(tree *parse.Tree) (vHTML string) {
 	renderer := render.NewBlockRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2BlockDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -124,6 +130,7 @@ func (lute *Lute) Md2BlockDOM(markdown string) (vHTML string) {
 
 func (lute *Lute) InlineMd2BlockDOM(markdown string) (vHTML string) {
 	tree := parse.Inline("", []byte(markdown), lute.ParseOptions)
+	lute.transform(tree, true)
 	renderer := render.NewBlockRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2BlockDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -174,6 +181,9 @@ func (lute *Lute) BlockDOM2StdMd(htmlStr string) (markdown string) {
 
 func (lute *Lute) BlockDOM2Text(htmlStr string) (text string) {
 	tree := lute.BlockDOM2Tree(htmlStr)
+	if lute.RenderOptions.JoinCJKSoftBreak {
+		return tree.Root.PlainText()
+	}
 	return tree.Root.Text()
 }
 
@@ -248,6 +258,8 @@ func (lute *Lute) BlockDOM2Tree(htmlStr string) (ret *parse.Tree) {
 		}
 		return ast.WalkContinue
 	})
+
+	lute.validateTree(ret)
 	return
 }
 
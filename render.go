@@ -0,0 +1,47 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+	"github.com/88250/lute/util"
+)
+
+// Render 将 markdown 渲染为 format 格式，format 为通过 render.Register 注册的输出格式名（参考 Formats），格式未注册时返回空字符串，
+// 需要区分格式未注册的情况时请使用 RenderE。
+func (lute *Lute) Render(format, markdown string) (output string) {
+	tree := lute.parse("", []byte(markdown))
+	output = lute.RenderTree(format, tree)
+	return
+}
+
+// RenderTree 将语法树 tree 渲染为 format 格式，格式未注册时返回空字符串。
+//
+// ExtRendererFuncs 中该格式的用户自定义渲染器函数会被设置到实现了 render.ExtRenderer 的渲染器上。
+func (lute *Lute) RenderTree(format string, tree *parse.Tree) (output string) {
+	factory := render.Lookup(format)
+	if nil == factory {
+		return
+	}
+
+	renderer := factory(tree, lute.RenderOptions)
+	if extRenderer, ok := renderer.(render.ExtRenderer); ok {
+		extRenderer.SetExtRendererFuncs(lute.ExtRendererFuncs[format])
+	}
+	output = util.BytesToStr(renderer.Render())
+	return
+}
+
+// Formats 返回所有已注册的输出格式名。
+func (lute *Lute) Formats() []string {
+	return render.Formats()
+}
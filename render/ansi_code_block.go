@@ -0,0 +1,49 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// +build !javascript
+
+package render
+
+import (
+	"bytes"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/formatters"
+	chromalexers "github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+)
+
+// highlight 使用 chroma 终端格式化器对代码 code 进行语法高亮，高亮失败时返回原始代码。
+func (r *ANSIRenderer) highlight(code, language string) string {
+	var lexer chroma.Lexer
+	if "" != language {
+		lexer = chromalexers.Get(language)
+	} else if r.Options.CodeSyntaxHighlightDetectLang {
+		lexer = chromalexers.Analyse(code)
+	}
+	if nil == lexer {
+		lexer = chromalexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if nil != err {
+		return code
+	}
+
+	formatter := formatters.TTY256
+	if r.Options.ANSITrueColor {
+		formatter = formatters.TTY16m
+	}
+	buf := &bytes.Buffer{}
+	if err = formatter.Format(buf, styles.Get(r.Options.CodeSyntaxHighlightStyleName), iterator); nil != err {
+		return code
+	}
+	return buf.String()
+}
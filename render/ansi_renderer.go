@@ -0,0 +1,164 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// ANSIRenderer 描述了终端渲染器，用于命令行工具输出。
+//
+// 在纯文本渲染的基础上使用 ANSI 转义序列输出粗体、斜体、下划线和彩色标题，代码块绘制边框并使用 chroma 进行语法高亮，
+// 表格使用制表符绘制边框，链接使用 OSC 8 超链接。设置了 Options.TextWrapWidth 时按照该宽度自动换行。
+type ANSIRenderer struct {
+	*TextRenderer
+}
+
+// ansiHeadingColors 定义了各级标题的前景色，超出部分使用最后一个颜色。
+var ansiHeadingColors = []string{"35", "34", "36"}
+
+// NewANSIRenderer 创建一个终端渲染器。
+func NewANSIRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &ANSIRenderer{TextRenderer: newTextRenderer(tree, options)}
+	ret.bullet = "•"
+	ret.quote = "\x1b[90m│\x1b[39m "
+	ret.rule = "─"
+	ret.table = &textTableBorder{
+		top:        [3]string{"┌", "┬", "┐"},
+		separator:  [3]string{"├", "┼", "┤"},
+		bottom:     [3]string{"└", "┴", "┘"},
+		horizontal: "─",
+		vertical:   "│",
+	}
+
+	ret.blockFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.blockFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.blockFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+
+	ret.inlineFuncs[ast.NodeEmphasis] = ansiStyle("3", "23")
+	ret.inlineFuncs[ast.NodeStrong] = ansiStyle("1", "22")
+	ret.inlineFuncs[ast.NodeUnderline] = ansiStyle("4", "24")
+	ret.inlineFuncs[ast.NodeStrikethrough] = ansiStyle("9", "29")
+	ret.inlineFuncs[ast.NodeMark] = ansiStyle("7", "27")
+	ret.inlineFuncs[ast.NodeCodeSpan] = ansiStyle("36", "39")
+	ret.inlineFuncs[ast.NodeKbd] = ansiStyle("7", "27")
+	ret.inlineFuncs[ast.NodeLink] = ret.renderLink
+	ret.inlineFuncs[ast.NodeImage] = ret.renderLink
+	ret.inlineFuncs[ast.NodeTableCell] = ret.renderTableCell
+	return ret
+}
+
+// ansiStyle 返回一个行级节点渲染函数，进入节点时输出 SGR 参数 on，离开时输出 off。
+func ansiStyle(on, off string) textInlineFunc {
+	return func(n *ast.Node, entering bool) string {
+		if entering {
+			return "\x1b[" + on + "m"
+		}
+		return "\x1b[" + off + "m"
+	}
+}
+
+func (r *ANSIRenderer) renderHeading(n *ast.Node, width int) (ret []string) {
+	color := ansiHeadingColors[len(ansiHeadingColors)-1]
+	if n.HeadingLevel <= len(ansiHeadingColors) {
+		color = ansiHeadingColors[n.HeadingLevel-1]
+	}
+	text := r.inlineText(n)
+	if 1 == n.HeadingLevel {
+		text = "\x1b[4m" + text + "\x1b[24m"
+	}
+	for _, line := range r.wrap(text, width) {
+		ret = append(ret, "\x1b[1;"+color+"m"+line+"\x1b[0m")
+	}
+	return
+}
+
+func (r *ANSIRenderer) renderCodeBlock(n *ast.Node, width int) (ret []string) {
+	code := n.ChildByType(ast.NodeCodeBlockCode)
+	if nil == code {
+		return
+	}
+	language := ""
+	if info := strings.Fields(util.BytesToStr(n.CodeBlockInfo)); 0 < len(info) {
+		language = info[0]
+	}
+	content := strings.TrimRight(util.BytesToStr(code.Tokens), "\n")
+	if r.Options.CodeSyntaxHighlight {
+		content = r.highlight(content, language)
+	}
+	return r.box(strings.Split(content, "\n"), language, width)
+}
+
+func (r *ANSIRenderer) renderMathBlock(n *ast.Node, width int) (ret []string) {
+	content := n.ChildByType(ast.NodeMathBlockContent)
+	if nil == content {
+		return
+	}
+	return r.box(strings.Split(strings.TrimSpace(util.BytesToStr(content.Tokens)), "\n"), "math", width)
+}
+
+// box 使用制表符为 lines 绘制边框，label 显示在上边框中。边框宽度在 width 大于 0 时占满可用宽度，否则适应最宽的行。
+func (r *ANSIRenderer) box(lines []string, label string, width int) (ret []string) {
+	inner := 0
+	for _, line := range lines {
+		if w := textWidth(line); w > inner {
+			inner = w
+		}
+	}
+	if "" != label {
+		label = " " + label + " "
+	}
+	if w := textWidth(label) + 1; w > inner {
+		inner = w
+	}
+	if 0 < width && width-4 > inner {
+		inner = width - 4
+	}
+
+	ret = append(ret, "┌─"+label+strings.Repeat("─", inner+1-textWidth(label))+"┐")
+	for _, line := range lines {
+		padding := strings.Repeat(" ", inner-textWidth(line))
+		if strings.Contains(line, "\x1b") {
+			// 高亮后的代码可能包含跨行的样式，在右边框前重置
+			padding = "\x1b[0m" + padding
+		}
+		ret = append(ret, "│ "+line+padding+" │")
+	}
+	ret = append(ret, "└"+strings.Repeat("─", inner+2)+"┘")
+	return
+}
+
+// renderLink 将链接和图片渲染为 OSC 8 超链接，链接文本带下划线，图片输出替代文本。
+func (r *ANSIRenderer) renderLink(n *ast.Node, entering bool) string {
+	if !entering {
+		return "\x1b[24m\x1b]8;;\x1b\\"
+	}
+	dest := ""
+	if d := n.ChildByType(ast.NodeLinkDest); nil != d {
+		dest = util.BytesToStr(r.LinkPath(d.Tokens))
+	}
+	return "\x1b]8;;" + dest + "\x1b\\\x1b[4m"
+}
+
+// renderTableCell 将表头单元格渲染为粗体。
+func (r *ANSIRenderer) renderTableCell(n *ast.Node, entering bool) string {
+	if nil == n.Parent || nil == n.Parent.Parent || ast.NodeTableHead != n.Parent.Parent.Type {
+		return ""
+	}
+	if entering {
+		return "\x1b[1m"
+	}
+	return "\x1b[22m"
+}
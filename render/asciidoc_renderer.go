@@ -0,0 +1,582 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// AsciiDocRenderer 描述了 AsciiDoc 渲染器，用于将 Markdown 文档迁移到 Antora 等基于 AsciiDoc 的文档站点。
+//
+// 文档中唯一的一级标题位于开头时作为文档标题；以 [!NOTE] 或者 **Note:** 开头的引述渲染为提示块；
+// 指向其他 Markdown 文档的链接渲染为 xref；不支持的块渲染为原始透传块（++++）并记录到 Warnings 中。
+type AsciiDocRenderer struct {
+	*BaseRenderer
+	Warnings  []string        // 渲染过程中产生的警告，比如不支持的节点
+	title     *ast.Node       // 作为文档标题的一级标题
+	footnotes map[string]bool // 已经输出过内容的脚注
+}
+
+// asciiDocSkippedBlocks 定义了不输出的块，脚注定义在引用处内联输出。
+var asciiDocSkippedBlocks = map[ast.NodeType]bool{
+	ast.NodeLinkRefDefBlock: true, ast.NodeFootnotesDefBlock: true, ast.NodeKramdownBlockIAL: true,
+}
+
+// NewAsciiDocRenderer 创建一个 AsciiDoc 渲染器。
+func NewAsciiDocRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &AsciiDocRenderer{BaseRenderer: NewBaseRenderer(tree, options)}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBlockRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeFileAnnotationRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBackslashContent] = ret.renderText
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiAlias] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiUnicode] = ret.renderEmojiUnicode
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderQuoted("", "_")
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderQuoted("", "*")
+	ret.RendererFuncs[ast.NodeMark] = ret.renderQuoted("", "#")
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderQuoted("[.line-through]", "#")
+	ret.RendererFuncs[ast.NodeUnderline] = ret.renderQuoted("[.underline]", "#")
+	ret.RendererFuncs[ast.NodeSup] = ret.renderWrapped("^", "^")
+	ret.RendererFuncs[ast.NodeSub] = ret.renderWrapped("~", "~")
+	ret.RendererFuncs[ast.NodeKbd] = ret.renderWrapped("kbd:[", "]")
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeBr] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeBlockRef] = ret.renderBlockRef
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderInlineHTML
+	ret.RendererFuncs[ast.NodeKramdownSpanIAL] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeTableRow] = ret.renderTableRow
+	ret.RendererFuncs[ast.NodeTableCell] = ret.renderTableCell
+	ret.RendererFuncs[ast.NodeToC] = ret.renderToC
+	ret.RendererFuncs[ast.NodeSuperBlock] = ret.renderSuperBlock
+	for typ := range asciiDocSkippedBlocks {
+		ret.RendererFuncs[typ] = ret.renderSkip
+	}
+	ret.DefaultRendererFunc = ret.renderDefault
+	return ret
+}
+
+// renderDefault 将不支持的块渲染为原始透传块并记录警告，不支持的行级节点只渲染其子节点。
+func (r *AsciiDocRenderer) renderDefault(node *ast.Node, entering bool) ast.WalkStatus {
+	if !node.IsBlock() {
+		return ast.WalkContinue
+	}
+	if entering {
+		r.Warnings = append(r.Warnings, "unsupported node ["+node.Type.String()+"] is rendered as raw passthrough block")
+		r.WriteString("++++\n" + markupRaw(r.Tree, node, r.Options) + "\n++++\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderSkip(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	r.Warnings = nil
+	r.footnotes = map[string]bool{}
+	r.title = nil
+	var h1s []*ast.Node
+	var stem, toc bool
+	ast.Walk(node, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.WalkContinue
+		}
+		switch n.Type {
+		case ast.NodeHeading:
+			if 1 == n.HeadingLevel {
+				h1s = append(h1s, n)
+			}
+		case ast.NodeMathBlock, ast.NodeInlineMath:
+			stem = true
+		case ast.NodeToC:
+			toc = true
+		}
+		return ast.WalkContinue
+	})
+	if 1 == len(h1s) && h1s[0] == markupFirstBlock(node) {
+		r.title = h1s[0]
+	}
+
+	var header []string
+	if nil != r.title {
+		header = append(header, "= "+strings.TrimSpace(markupRenderChildren(r.BaseRenderer, r.title)))
+	}
+	if stem {
+		header = append(header, ":stem: latexmath")
+	}
+	if toc {
+		header = append(header, ":toc: macro")
+	}
+	if 0 < len(header) {
+		r.WriteString(strings.Join(header, "\n") + "\n")
+		if nil == r.title || nil != markupNextBlock(r.title, asciiDocSkippedBlocks) {
+			r.WriteByte('\n')
+		}
+	}
+	return ast.WalkContinue
+}
+
+// blockEnd 在块 node 结束后换行：相邻块之间使用空行分隔，列表项中的相邻块使用 + 连接。
+func (r *AsciiDocRenderer) blockEnd(node *ast.Node) {
+	r.Newline()
+	next := markupNextBlock(node, asciiDocSkippedBlocks)
+	if nil == next {
+		return
+	}
+	if ast.NodeListItem == node.Parent.Type {
+		if ast.NodeList != next.Type {
+			r.WriteString("+\n")
+		}
+		return
+	}
+	r.WriteByte('\n')
+}
+
+func (r *AsciiDocRenderer) renderSuperBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	if image := node.FirstChild; nil != image && ast.NodeImage == image.Type && nil == image.Next {
+		// 单独成段的图片渲染为块图片
+		if entering {
+			r.WriteString("image::" + r.linkDest(image) + "[" + r.alt(image) + "]\n")
+			r.blockEnd(node)
+		}
+		return ast.WalkSkipChildren
+	}
+	if !entering {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+	text := util.BytesToStr(node.Tokens)
+	if prev := node.Previous; nil != prev && ast.NodeTaskListItemMarker == prev.Type {
+		text = strings.TrimLeft(text, " ")
+	}
+	if markupInside(node, ast.NodeLink) || markupInside(node, ast.NodeFootnotesDef) {
+		text = strings.ReplaceAll(text, "]", "\\]")
+	}
+	if markupInside(node, ast.NodeTableCell) {
+		text = strings.ReplaceAll(text, "|", "\\|")
+	}
+	r.WriteString(text)
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderEmojiUnicode(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Write(node.Tokens)
+	}
+	return ast.WalkSkipChildren
+}
+
+// constrained 判断行级节点 node 能否使用受限标记（比如 *foo*），紧邻字母或者数字时需要使用非受限标记（比如 **foo**）。
+func (r *AsciiDocRenderer) constrained(node *ast.Node) bool {
+	return !markupWordChar(markupPrevRune(node)) && !markupWordChar(markupNextRune(node))
+}
+
+// renderQuoted 返回一个使用成对标记 mark 渲染行级节点的渲染函数，role 为标记前的角色，比如 [.line-through]。
+func (r *AsciiDocRenderer) renderQuoted(role, mark string) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		marker := mark
+		if !r.constrained(node) {
+			marker += mark
+		}
+		if entering {
+			r.WriteString(role + marker)
+		} else {
+			r.WriteString(marker)
+		}
+		return ast.WalkContinue
+	}
+}
+
+// renderWrapped 返回一个使用 open 和 close 包裹行级节点的渲染函数。
+func (r *AsciiDocRenderer) renderWrapped(open, close string) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			r.WriteString(open)
+		} else {
+			r.WriteString(close)
+		}
+		return ast.WalkContinue
+	}
+}
+
+func (r *AsciiDocRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		mark := "`"
+		if !r.constrained(node) {
+			mark = "``"
+		}
+		if content := node.ChildByType(ast.NodeCodeSpanContent); nil != content {
+			// 使用 + 透传代码内容，避免其中的标记被解析
+			r.WriteString(mark + "+" + util.BytesToStr(content.Tokens) + "+" + mark)
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeInlineMathContent); nil != content {
+			r.WriteString("stem:[" + strings.ReplaceAll(strings.TrimSpace(util.BytesToStr(content.Tokens)), "]", "\\]") + "]")
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString(" +\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte('\n')
+	}
+	return ast.WalkContinue
+}
+
+// linkDest 返回链接或者图片 node 的地址。
+func (r *AsciiDocRenderer) linkDest(node *ast.Node) string {
+	if dest := node.ChildByType(ast.NodeLinkDest); nil != dest {
+		return util.BytesToStr(r.LinkPath(dest.Tokens))
+	}
+	return ""
+}
+
+// alt 返回图片 node 的替代文本。
+func (r *AsciiDocRenderer) alt(node *ast.Node) string {
+	if text := node.ChildByType(ast.NodeLinkText); nil != text {
+		return strings.ReplaceAll(util.BytesToStr(text.Tokens), "]", "\\]")
+	}
+	return ""
+}
+
+// bareURL 判断链接 node 是否为链接文本和地址相同的自动链接。
+func (r *AsciiDocRenderer) bareURL(node *ast.Node) bool {
+	text, dest := node.ChildByType(ast.NodeLinkText), node.ChildByType(ast.NodeLinkDest)
+	return nil != text && nil != dest && util.BytesToStr(text.Tokens) == util.BytesToStr(dest.Tokens) && strings.Contains(util.BytesToStr(dest.Tokens), "://")
+}
+
+func (r *AsciiDocRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	if r.bareURL(node) {
+		if entering {
+			r.WriteString(r.linkDest(node))
+		}
+		return ast.WalkSkipChildren
+	}
+	if !entering {
+		if strings.HasPrefix(r.linkDest(node), "#") {
+			r.WriteString(">>")
+		} else {
+			r.WriteString("]")
+		}
+		return ast.WalkContinue
+	}
+
+	dest := r.linkDest(node)
+	if path, anchor, ok := crossDocLink(dest); ok {
+		r.WriteString("xref:" + path + ".adoc")
+		if "" != anchor {
+			r.WriteString("#" + anchor)
+		}
+		r.WriteString("[")
+	} else if strings.HasPrefix(dest, "#") {
+		// 文档内锚点
+		r.WriteString("<<" + dest[1:] + ",")
+	} else if strings.Contains(dest, "://") || strings.HasPrefix(dest, "mailto:") {
+		r.WriteString(dest + "[")
+	} else {
+		r.WriteString("link:" + dest + "[")
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("image:" + r.linkDest(node) + "[" + r.alt(node) + "]")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderBlockRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteString(">>")
+		return ast.WalkContinue
+	}
+	r.WriteString("<<")
+	if id := node.ChildByType(ast.NodeBlockRefID); nil != id {
+		r.Write(id.Tokens)
+	}
+	if nil != node.ChildByType(ast.NodeBlockRefText) {
+		r.WriteString(",")
+	}
+	return ast.WalkContinue
+}
+
+// renderFootnotesRef 将脚注引用渲染为内联的 footnote 宏，重复引用同一个脚注时只引用脚注 ID。
+func (r *AsciiDocRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	pos, def := r.Tree.FindFootnotesDef(node.Tokens)
+	if nil == def {
+		r.WriteString("[" + util.BytesToStr(node.Tokens) + "]")
+		return ast.WalkContinue
+	}
+	id := "fn" + strconv.Itoa(pos)
+	if r.footnotes[id] {
+		r.WriteString("footnote:" + id + "[]")
+		return ast.WalkContinue
+	}
+	r.footnotes[id] = true
+	var paragraphs []string
+	for c := def.FirstChild; nil != c; c = c.Next {
+		if content := strings.TrimSpace(markupRenderChildren(r.BaseRenderer, c)); "" != content {
+			paragraphs = append(paragraphs, content)
+		}
+	}
+	r.WriteString("footnote:" + id + "[" + strings.Join(paragraphs, " ") + "]")
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Warnings = append(r.Warnings, "unsupported node ["+node.Type.String()+"] is rendered as raw passthrough")
+		r.WriteString("pass:[" + strings.ReplaceAll(util.BytesToStr(node.Tokens), "]", "\\]") + "]")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	if node == r.title {
+		return ast.WalkSkipChildren
+	}
+	if !entering {
+		r.WriteByte('\n')
+		r.blockEnd(node)
+		return ast.WalkContinue
+	}
+
+	if id := node.ChildByType(ast.NodeHeadingID); nil != id {
+		r.WriteString("[#" + strings.TrimPrefix(util.BytesToStr(id.Tokens), "#") + "]\n")
+	}
+	// 有文档标题时一级标题对应 =，否则从 == 开始
+	level := node.HeadingLevel
+	if nil == r.title {
+		level++
+	}
+	if 6 < level {
+		level = 6
+	}
+	r.WriteString(strings.Repeat("=", level) + " ")
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("'''\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	// 嵌套的分隔块需要使用不同长度的分隔符
+	depth := 0
+	for p := node.Parent; nil != p; p = p.Parent {
+		if ast.NodeBlockquote == p.Type {
+			depth++
+		}
+	}
+
+	kind, content := admonition(node)
+	if "" == kind {
+		fence := strings.Repeat("_", 4+depth*2)
+		if entering {
+			r.WriteString(fence + "\n")
+		} else {
+			r.Newline()
+			r.WriteString(fence + "\n")
+			r.blockEnd(node)
+		}
+		return ast.WalkContinue
+	}
+
+	if entering {
+		fence := strings.Repeat("=", 4+depth*2)
+		r.WriteString("[" + kind + "]\n" + fence + "\n")
+		r.WriteString(markupRenderChildren(r.BaseRenderer, content))
+		r.Newline()
+		r.WriteString(fence + "\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		if 1 == node.ListData.Typ && 1 != node.ListData.Start {
+			r.WriteString("[start=" + strconv.Itoa(node.ListData.Start) + "]\n")
+		}
+	} else {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.Newline()
+		return ast.WalkContinue
+	}
+
+	depth := 0
+	for p := node.Parent; nil != p; p = p.Parent {
+		if ast.NodeList == p.Type {
+			depth++
+		}
+	}
+	marker := "*"
+	if 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar) {
+		marker = "."
+	}
+	r.Newline()
+	r.WriteString(strings.Repeat(marker, depth) + " ")
+	if task := node.FirstChild; nil != task && nil != task.FirstChild && ast.NodeTaskListItemMarker == task.FirstChild.Type {
+		if task.FirstChild.TaskListItemChecked {
+			r.WriteString("[x] ")
+		} else {
+			r.WriteString("[ ] ")
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	code := ""
+	if content := node.ChildByType(ast.NodeCodeBlockCode); nil != content {
+		code = strings.TrimRight(util.BytesToStr(content.Tokens), "\n")
+	}
+	if info := strings.Fields(util.BytesToStr(node.CodeBlockInfo)); 0 < len(info) {
+		r.WriteString("[source," + info[0] + "]\n")
+	}
+	fence := markupFence(code, '-')
+	r.WriteString(fence + "\n" + code + "\n" + fence + "\n")
+	r.blockEnd(node)
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		content := ""
+		if c := node.ChildByType(ast.NodeMathBlockContent); nil != c {
+			content = strings.TrimSpace(util.BytesToStr(c.Tokens))
+		}
+		r.WriteString("[stem]\n++++\n" + content + "\n++++\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *AsciiDocRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteString("|===\n")
+		r.blockEnd(node)
+		return ast.WalkContinue
+	}
+
+	var cols []string
+	for _, align := range node.TableAligns {
+		switch align {
+		case 2:
+			cols = append(cols, "^")
+		case 3:
+			cols = append(cols, ">")
+		default:
+			cols = append(cols, "<")
+		}
+	}
+	r.WriteString("[cols=\"" + strings.Join(cols, ",") + "\",options=\"header\"]\n|===\n")
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteByte('\n')
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if nil != node.Previous {
+			r.WriteByte(' ')
+		}
+		r.WriteByte('|')
+	}
+	return ast.WalkContinue
+}
+
+func (r *AsciiDocRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("toc::[]\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
@@ -0,0 +1,518 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// ConfluenceRenderer 描述了 Confluence 存储格式（storage format）渲染器，渲染结果可以直接作为 Confluence REST API 中页面的 body.storage.value。
+//
+// 代码块渲染为 code 宏，[!NOTE] 等提示块渲染为 info、tip、note 和 warning 宏，任务列表渲染为 ac:task-list，
+// 本地图片渲染为页面附件，脚注按照引用顺序编号后在页面末尾输出。
+type ConfluenceRenderer struct {
+	*BaseRenderer
+	taskID       int            // 任务列表项序号，页面内唯一
+	footnotes    []*ast.Node    // 引用过的脚注定义
+	footnoteNums map[string]int // 脚注标签到脚注序号的映射
+}
+
+// confluenceMacros 定义了提示类型到 Confluence 提示宏的映射。
+var confluenceMacros = map[string]string{
+	"NOTE": "info", "TIP": "tip", "IMPORTANT": "note", "WARNING": "warning", "CAUTION": "warning",
+}
+
+// confluenceLanguages 定义了常用代码语言别名到 Confluence code 宏语言名的映射。
+var confluenceLanguages = map[string]string{
+	"js": "javascript", "ts": "typescript", "py": "python", "sh": "bash", "shell": "bash", "zsh": "bash", "yml": "yaml", "golang": "go",
+	"c++": "cpp", "cs": "c#", "csharp": "c#", "html": "xml", "xhtml": "xml", "rb": "ruby", "kt": "kotlin",
+}
+
+// confluenceSkippedBlocks 定义了不输出的块，Confluence 默认禁用 HTML 宏，所以 HTML 块也不输出。
+var confluenceSkippedBlocks = map[ast.NodeType]bool{
+	ast.NodeHTMLBlock: true, ast.NodeYamlFrontMatter: true, ast.NodeLinkRefDefBlock: true, ast.NodeFootnotesDefBlock: true, ast.NodeKramdownBlockIAL: true,
+}
+
+// NewConfluenceRenderer 创建一个 Confluence 存储格式渲染器。
+func NewConfluenceRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &ConfluenceRenderer{BaseRenderer: NewBaseRenderer(tree, options)}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBlockRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeFileAnnotationRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBackslashContent] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiAlias] = ret.renderText
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderHTMLEntity
+	ret.RendererFuncs[ast.NodeEmojiUnicode] = ret.renderEmojiUnicode
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeKbd] = ret.renderTag("code", "")
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderTag("em", "")
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderTag("strong", "")
+	ret.RendererFuncs[ast.NodeUnderline] = ret.renderTag("u", "")
+	ret.RendererFuncs[ast.NodeSup] = ret.renderTag("sup", "")
+	ret.RendererFuncs[ast.NodeSub] = ret.renderTag("sub", "")
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderTag("span", "text-decoration: line-through;")
+	ret.RendererFuncs[ast.NodeMark] = ret.renderTag("span", "background-color: rgb(255,250,230);")
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeBr] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeTableRow] = ret.renderTableRow
+	ret.RendererFuncs[ast.NodeTableCell] = ret.renderTableCell
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeToC] = ret.renderToC
+	ret.RendererFuncs[ast.NodeTaskListItemMarker] = ret.renderSkip
+	for typ := range confluenceSkippedBlocks {
+		ret.RendererFuncs[typ] = ret.renderSkip
+	}
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeKramdownSpanIAL] = ret.renderSkip
+	// 标记符、链接地址等节点不输出
+	ret.DefaultRendererFunc = func(n *ast.Node, entering bool) ast.WalkStatus { return ast.WalkContinue }
+	return ret
+}
+
+func (r *ConfluenceRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.taskID, r.footnotes, r.footnoteNums = 0, nil, map[string]int{}
+	} else {
+		r.renderFootnotes()
+	}
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderSkip(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren
+}
+
+// renderNode 使用渲染器函数渲染单个节点，用于手动遍历子树。
+func (r *ConfluenceRenderer) renderNode(n *ast.Node, entering bool) ast.WalkStatus {
+	if render := r.RendererFuncs[n.Type]; nil != render {
+		return render(n, entering)
+	}
+	return r.DefaultRendererFunc(n, entering)
+}
+
+// confluenceEscape 转义 XHTML 文本和属性值。
+func confluenceEscape(text string) string {
+	return html.EscapeHTMLStr(strings.Map(xmlChar, text))
+}
+
+// confluenceCDATA 返回 CDATA 节，内容中的 ]]> 需要拆分到两个 CDATA 节中。
+func confluenceCDATA(text string) string {
+	return "<![CDATA[" + strings.ReplaceAll(strings.Map(xmlChar, text), "]]>", "]]]]><![CDATA[>") + "]]>"
+}
+
+// inlineParagraph 判断段落 node 是否不需要使用 <p> 包裹：紧凑列表项和任务列表项中的第一个段落直接输出行级内容。
+func (r *ConfluenceRenderer) inlineParagraph(node *ast.Node) bool {
+	item := node.Parent
+	if nil == item || ast.NodeListItem != item.Type {
+		return false
+	}
+	if 3 == item.ListData.Typ {
+		return node == item.FirstChild
+	}
+	return nil != item.Parent && nil != item.Parent.ListData && item.Parent.ListData.Tight
+}
+
+func (r *ConfluenceRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	if r.inlineParagraph(node) {
+		return ast.WalkContinue
+	}
+	if entering {
+		r.Newline()
+		r.WriteString("<p>")
+	} else {
+		r.WriteString("</p>\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		text := util.BytesToStr(node.Tokens)
+		if prev := node.Previous; nil != prev && ast.NodeTaskListItemMarker == prev.Type {
+			text = strings.TrimLeft(text, " ")
+		}
+		r.WriteString(confluenceEscape(text))
+	}
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderHTMLEntity(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString(confluenceEscape(html.UnescapeString(util.BytesToStr(node.Tokens))))
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderEmojiUnicode(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString(util.BytesToStr(node.Tokens))
+	}
+	return ast.WalkSkipChildren
+}
+
+// renderTag 返回一个使用标签 tag 包裹节点内容的渲染函数，style 不为空时作为标签的 style 属性。
+func (r *ConfluenceRenderer) renderTag(tag, style string) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			r.WriteString("</" + tag + ">")
+		} else if "" != style {
+			r.WriteString("<" + tag + " style=\"" + style + "\">")
+		} else {
+			r.WriteString("<" + tag + ">")
+		}
+		return ast.WalkContinue
+	}
+}
+
+func (r *ConfluenceRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeCodeSpanContent); nil != content {
+			r.WriteString("<code>" + confluenceEscape(util.BytesToStr(content.Tokens)) + "</code>")
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeInlineMathContent); nil != content {
+			r.WriteString("<code>" + confluenceEscape(util.BytesToStr(content.Tokens)) + "</code>")
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	tag := "h" + strconv.Itoa(node.HeadingLevel)
+	if entering {
+		r.Newline()
+		r.WriteString("<" + tag + ">")
+	} else {
+		r.WriteString("</" + tag + ">\n")
+	}
+	return ast.WalkContinue
+}
+
+// renderBlockquote 将提示块渲染为 Confluence 提示宏，其他引述渲染为 <blockquote>。
+func (r *ConfluenceRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	kind, content := admonition(node)
+	if "" == kind {
+		if entering {
+			r.Newline()
+			r.WriteString("<blockquote>\n")
+		} else {
+			r.Newline()
+			r.WriteString("</blockquote>\n")
+		}
+		return ast.WalkContinue
+	}
+
+	if entering {
+		r.Newline()
+		r.WriteString("<ac:structured-macro ac:name=\"" + confluenceMacros[kind] + "\"><ac:rich-text-body>\n")
+		for c := content.FirstChild; nil != c; c = c.Next {
+			ast.Walk(c, r.renderNode)
+		}
+		r.Newline()
+		r.WriteString("</ac:rich-text-body></ac:structured-macro>\n")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	tag := "ul"
+	switch node.ListData.Typ {
+	case 1:
+		tag = "ol"
+	case 3:
+		tag = "ac:task-list"
+	}
+	if !entering {
+		r.Newline()
+		r.WriteString("</" + tag + ">\n")
+		return ast.WalkContinue
+	}
+
+	r.Newline()
+	if 1 == node.ListData.Typ && 1 != node.ListData.Start {
+		r.WriteString("<ol start=\"" + strconv.Itoa(node.ListData.Start) + "\">\n")
+	} else {
+		r.WriteString("<" + tag + ">\n")
+	}
+	return ast.WalkContinue
+}
+
+// renderListItem 渲染列表项，任务列表项渲染为 ac:task，任务序号在页面内递增。
+func (r *ConfluenceRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if 3 != node.ListData.Typ {
+		if entering {
+			r.Newline()
+			r.WriteString("<li>")
+		} else {
+			r.WriteString("</li>\n")
+		}
+		return ast.WalkContinue
+	}
+
+	if !entering {
+		r.WriteString("</ac:task-body>\n</ac:task>\n")
+		return ast.WalkContinue
+	}
+	r.taskID++
+	status := "incomplete"
+	if node.ListData.Checked {
+		status = "complete"
+	}
+	r.Newline()
+	r.WriteString("<ac:task>\n<ac:task-id>" + strconv.Itoa(r.taskID) + "</ac:task-id>\n<ac:task-status>" + status + "</ac:task-status>\n<ac:task-body>")
+	return ast.WalkContinue
+}
+
+// writeCodeMacro 输出 code 宏，language 为空时不设置语言参数。
+func (r *ConfluenceRenderer) writeCodeMacro(language, code string) {
+	r.Newline()
+	r.WriteString("<ac:structured-macro ac:name=\"code\">")
+	if "" != language {
+		r.WriteString("<ac:parameter ac:name=\"language\">" + confluenceEscape(language) + "</ac:parameter>")
+	}
+	r.WriteString("<ac:plain-text-body>" + confluenceCDATA(strings.TrimSuffix(code, "\n")) + "</ac:plain-text-body></ac:structured-macro>\n")
+}
+
+func (r *ConfluenceRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	language := ""
+	if info := node.ChildByType(ast.NodeCodeBlockFenceInfoMarker); nil != info && 0 < len(info.CodeBlockInfo) {
+		language = strings.ToLower(strings.Fields(util.BytesToStr(info.CodeBlockInfo))[0])
+		if alias, ok := confluenceLanguages[language]; ok {
+			language = alias
+		}
+	}
+	code := ""
+	if content := node.ChildByType(ast.NodeCodeBlockCode); nil != content {
+		code = util.BytesToStr(content.Tokens)
+	}
+	r.writeCodeMacro(language, code)
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		content := ""
+		if c := node.ChildByType(ast.NodeMathBlockContent); nil != c {
+			content = strings.TrimSpace(util.BytesToStr(c.Tokens))
+		}
+		r.writeCodeMacro("", content)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.WriteString("<hr />\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("<br />")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("\n")
+	}
+	return ast.WalkContinue
+}
+
+// renderLink 渲染链接，页面内锚点使用 ac:link 以便 Confluence 解析到标题锚点。
+func (r *ConfluenceRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	dest := ""
+	if d := node.ChildByType(ast.NodeLinkDest); nil != d {
+		dest = util.BytesToStr(r.LinkPath(d.Tokens))
+	}
+	if 2 == node.LinkType {
+		if entering {
+			r.WriteString("<a href=\"" + confluenceEscape(dest) + "\">" + confluenceEscape(node.Text()) + "</a>")
+		}
+		return ast.WalkSkipChildren
+	}
+
+	if strings.HasPrefix(dest, "#") {
+		if entering {
+			r.WriteString("<ac:link ac:anchor=\"" + confluenceEscape(dest[1:]) + "\"><ac:link-body>")
+		} else {
+			r.WriteString("</ac:link-body></ac:link>")
+		}
+		return ast.WalkContinue
+	}
+	if entering {
+		r.WriteString("<a href=\"" + confluenceEscape(dest) + "\">")
+	} else {
+		r.WriteString("</a>")
+	}
+	return ast.WalkContinue
+}
+
+// renderImage 渲染图片，远程图片使用 ri:url，本地图片作为页面附件使用 ri:attachment 引用。
+func (r *ConfluenceRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	src, alt, title := "", "", ""
+	if d := node.ChildByType(ast.NodeLinkDest); nil != d {
+		src = util.BytesToStr(r.LinkPath(d.Tokens))
+	}
+	if t := node.ChildByType(ast.NodeLinkText); nil != t {
+		alt = util.BytesToStr(t.Tokens)
+	}
+	if t := node.ChildByType(ast.NodeLinkTitle); nil != t {
+		title = util.BytesToStr(t.Tokens)
+	}
+
+	r.WriteString("<ac:image")
+	if "" != alt {
+		r.WriteString(" ac:alt=\"" + confluenceEscape(alt) + "\"")
+	}
+	if "" != title {
+		r.WriteString(" ac:title=\"" + confluenceEscape(title) + "\"")
+	}
+	r.WriteString(">")
+	if strings.Contains(src, "://") || strings.HasPrefix(src, "data:") {
+		r.WriteString("<ri:url ri:value=\"" + confluenceEscape(src) + "\" />")
+	} else {
+		if unescaped, err := util.PathUnescape(src); nil == err {
+			src = unescaped
+		}
+		r.WriteString("<ri:attachment ri:filename=\"" + confluenceEscape(path.Base(src)) + "\" />")
+	}
+	r.WriteString("</ac:image>")
+	return ast.WalkSkipChildren
+}
+
+func (r *ConfluenceRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	r.Newline()
+	if entering {
+		r.WriteString("<table>\n<tbody>\n")
+	} else {
+		r.WriteString("</tbody>\n</table>\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("<tr>")
+	} else {
+		r.WriteString("</tr>\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	tag := "td"
+	if ast.NodeTableHead == node.Parent.Parent.Type {
+		tag = "th"
+	}
+	if !entering {
+		r.WriteString("</" + tag + ">")
+		return ast.WalkContinue
+	}
+
+	r.WriteString("<" + tag)
+	switch node.TableCellAlign {
+	case 2:
+		r.WriteString(" style=\"text-align: center;\"")
+	case 3:
+		r.WriteString(" style=\"text-align: right;\"")
+	}
+	r.WriteString(">")
+	return ast.WalkContinue
+}
+
+// renderFootnotesRef 将脚注引用渲染为上标 [n]，脚注内容在页面末尾输出。
+func (r *ConfluenceRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	label := strings.ToLower(util.BytesToStr(node.Tokens))
+	num, ok := r.footnoteNums[label]
+	if !ok {
+		_, def := r.Tree.FindFootnotesDef(node.Tokens)
+		if nil == def {
+			r.WriteString(confluenceEscape("[" + util.BytesToStr(node.Tokens) + "]"))
+			return ast.WalkContinue
+		}
+		r.footnotes = append(r.footnotes, def)
+		num = len(r.footnotes)
+		r.footnoteNums[label] = num
+	}
+	r.WriteString("<sup>[" + strconv.Itoa(num) + "]</sup>")
+	return ast.WalkContinue
+}
+
+func (r *ConfluenceRenderer) renderFootnotes() {
+	if 1 > len(r.footnotes) {
+		return
+	}
+
+	r.Newline()
+	r.WriteString("<hr />\n<ol>\n")
+	// 脚注内容中也可能引用新的脚注，所以每次循环重新获取长度
+	for i := 0; i < len(r.footnotes); i++ {
+		r.WriteString("<li>")
+		for c := r.footnotes[i].FirstChild; nil != c; c = c.Next {
+			ast.Walk(c, r.renderNode)
+		}
+		r.Newline()
+		r.WriteString("</li>\n")
+	}
+	r.WriteString("</ol>\n")
+}
+
+// renderToC 将目录渲染为 Confluence 的 toc 宏。
+func (r *ConfluenceRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.WriteString("<ac:structured-macro ac:name=\"toc\" />\n")
+	}
+	return ast.WalkSkipChildren
+}
@@ -0,0 +1,732 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// +build !javascript
+
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// DocxRenderer 描述了 DOCX（Office Open XML）渲染器，渲染结果是可以直接使用 Word 打开的 .docx 压缩包。
+//
+// 标题使用 Word 内置的标题样式，列表使用 numbering.xml 中定义的真实编号，脚注渲染为 Word 脚注，代码使用等宽字体样式。
+// 本地图片会嵌入到文档中，相对路径基于 Options.DocxResourceDir 解析；网络图片不会下载，渲染为链接，整个渲染过程不需要网络和 LibreOffice。
+type DocxRenderer struct {
+	*BaseRenderer
+	run       docxRunProps // 当前的行级样式
+	rels      []*docxRel   // 文档关系
+	media     []*docxMedia // 嵌入的图片
+	nums      []*docxNum   // 有序列表编号实例
+	listNums  map[*ast.Node]int
+	footnotes []string // 脚注 XML
+	footnote  bool     // 是否正在渲染脚注内容
+	drawingID int      // 图片绘图对象 ID
+	bookmarks int      // 书签 ID
+}
+
+// docxRunProps 描述了行级样式计数，大于 0 表示当前文本应用了该样式。
+type docxRunProps struct {
+	bold, italic, strike, underline, mark, sup, sub, code, link int
+}
+
+// docxRel 描述了 word/_rels/document.xml.rels 中的一个关系。
+type docxRel struct {
+	id, typ, target string
+	external        bool
+}
+
+// docxMedia 描述了嵌入文档的图片。
+type docxMedia struct {
+	name string
+	data []byte
+}
+
+// docxNum 描述了一个有序列表编号实例，每个有序列表使用单独的实例以便从列表起始序号重新编号。
+type docxNum struct {
+	level, start int
+}
+
+const (
+	docxNamespaces = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	docxRelsNS     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/"
+
+	docxBulletNumID = 1    // 无序列表编号实例 ID
+	docxIndent      = 720  // 每级缩进（缇）
+	docxTextWidth   = 9026 // A4 纸张去掉页边距后的版心宽度（缇）
+	docxEMUPerTwip  = 635
+	docxEMUPerPixel = 9525 // 按照 96 DPI 计算
+)
+
+var docxEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// docxEscape 转义 XML 文本和属性值，XML 1.0 中不允许出现的字符会被去掉。
+func docxEscape(text string) string {
+	return docxEscaper.Replace(strings.Map(xmlChar, text))
+}
+
+// NewDocxRenderer 创建一个 DOCX 渲染器。
+func NewDocxRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &DocxRenderer{BaseRenderer: NewBaseRenderer(tree, options)}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBlockRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeFileAnnotationRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBackslashContent] = ret.renderText
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiAlias] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiUnicode] = ret.renderEmojiUnicode
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderRunProp(func(p *docxRunProps) *int { return &p.italic })
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderRunProp(func(p *docxRunProps) *int { return &p.bold })
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderRunProp(func(p *docxRunProps) *int { return &p.strike })
+	ret.RendererFuncs[ast.NodeUnderline] = ret.renderRunProp(func(p *docxRunProps) *int { return &p.underline })
+	ret.RendererFuncs[ast.NodeMark] = ret.renderRunProp(func(p *docxRunProps) *int { return &p.mark })
+	ret.RendererFuncs[ast.NodeSup] = ret.renderRunProp(func(p *docxRunProps) *int { return &p.sup })
+	ret.RendererFuncs[ast.NodeSub] = ret.renderRunProp(func(p *docxRunProps) *int { return &p.sub })
+	ret.RendererFuncs[ast.NodeKbd] = ret.renderRunProp(func(p *docxRunProps) *int { return &p.code })
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeBr] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderContainer
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderContainer
+	ret.RendererFuncs[ast.NodeSuperBlock] = ret.renderContainer
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeTableHead] = ret.renderContainer
+	ret.RendererFuncs[ast.NodeTableRow] = ret.renderTableRow
+	ret.RendererFuncs[ast.NodeTableCell] = ret.renderTableCell
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeKramdownSpanIAL] = ret.renderSkip
+	// 其他块（比如 HTML 块、YAML Front Matter、脚注定义块）不输出，其他行级节点只渲染子节点
+	ret.DefaultRendererFunc = func(n *ast.Node, entering bool) ast.WalkStatus {
+		if n.IsBlock() {
+			return ast.WalkSkipChildren
+		}
+		return ast.WalkContinue
+	}
+	return ret
+}
+
+func init() {
+	Register("docx", NewDocxRenderer)
+}
+
+// Render 渲染 .docx 压缩包。
+func (r *DocxRenderer) Render() (output []byte) {
+	r.run = docxRunProps{}
+	r.rels, r.media, r.nums, r.footnotes = nil, nil, nil, nil
+	r.listNums = map[*ast.Node]int{}
+	r.drawingID, r.bookmarks = 0, 0
+	r.rel("styles", "styles.xml", false)
+	r.rel("numbering", "numbering.xml", false)
+	r.rel("footnotes", "footnotes.xml", false)
+	r.rel("settings", "settings.xml", false)
+
+	body := string(r.BaseRenderer.Render())
+	if "" == body {
+		body = "<w:p/>"
+	}
+
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+	parts := []struct {
+		name, content string
+	}{
+		{"[Content_Types].xml", r.contentTypes()},
+		{"_rels/.rels", docxXMLHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="` + docxRelsNS + `officeDocument" Target="word/document.xml"/></Relationships>`},
+		{"word/document.xml", docxXMLHeader + `<w:document ` + docxNamespaces + ` xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><w:body>` +
+			body + `<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="708" w:footer="708" w:gutter="0"/></w:sectPr></w:body></w:document>`},
+		{"word/_rels/document.xml.rels", r.documentRels()},
+		{"word/styles.xml", docxStyles},
+		{"word/numbering.xml", r.numbering()},
+		{"word/footnotes.xml", docxXMLHeader + `<w:footnotes ` + docxNamespaces + `><w:footnote w:type="separator" w:id="-1"><w:p><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr><w:r><w:separator/></w:r></w:p></w:footnote><w:footnote w:type="continuationSeparator" w:id="0"><w:p><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr><w:r><w:continuationSeparator/></w:r></w:p></w:footnote>` +
+			strings.Join(r.footnotes, "") + `</w:footnotes>`},
+		{"word/settings.xml", docxXMLHeader + `<w:settings ` + docxNamespaces + `><w:footnotePr><w:footnote w:id="-1"/><w:footnote w:id="0"/></w:footnotePr></w:settings>`},
+	}
+	for _, part := range parts {
+		writer, _ := archive.Create(part.name)
+		writer.Write([]byte(part.content))
+	}
+	for _, media := range r.media {
+		writer, _ := archive.Create("word/media/" + media.name)
+		writer.Write(media.data)
+	}
+	archive.Close()
+	return buf.Bytes()
+}
+
+const docxXMLHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// rel 添加一个文档关系，返回关系 ID。
+func (r *DocxRenderer) rel(typ, target string, external bool) string {
+	id := "rId" + strconv.Itoa(len(r.rels)+1)
+	r.rels = append(r.rels, &docxRel{id: id, typ: typ, target: target, external: external})
+	return id
+}
+
+func (r *DocxRenderer) documentRels() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString(docxXMLHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for _, rel := range r.rels {
+		buf.WriteString(`<Relationship Id="` + rel.id + `" Type="` + docxRelsNS + rel.typ + `" Target="` + docxEscape(rel.target) + `"`)
+		if rel.external {
+			buf.WriteString(` TargetMode="External"`)
+		}
+		buf.WriteString("/>")
+	}
+	buf.WriteString("</Relationships>")
+	return buf.String()
+}
+
+func (r *DocxRenderer) contentTypes() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString(docxXMLHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	buf.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/>`)
+	buf.WriteString(`<Default Extension="png" ContentType="image/png"/><Default Extension="jpeg" ContentType="image/jpeg"/><Default Extension="gif" ContentType="image/gif"/>`)
+	for _, part := range []string{"document.main", "styles", "numbering", "footnotes", "settings"} {
+		name := part
+		if "document.main" == part {
+			name = "document"
+		}
+		buf.WriteString(`<Override PartName="/word/` + name + `.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.` + part + `+xml"/>`)
+	}
+	buf.WriteString("</Types>")
+	return buf.String()
+}
+
+// numbering 返回 numbering.xml，抽象编号 0 用于无序列表，1 用于有序列表，每个有序列表使用单独的编号实例。
+func (r *DocxRenderer) numbering() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString(docxXMLHeader + `<w:numbering ` + docxNamespaces + `>`)
+	bullets := []string{"•", "◦", "▪"}
+	formats := []string{"decimal", "lowerLetter", "lowerRoman"}
+	for abstract := 0; abstract < 2; abstract++ {
+		buf.WriteString(`<w:abstractNum w:abstractNumId="` + strconv.Itoa(abstract) + `"><w:multiLevelType w:val="hybridMultilevel"/>`)
+		for level := 0; level < 9; level++ {
+			format, text := "bullet", bullets[level%len(bullets)]
+			if 1 == abstract {
+				format, text = formats[level%len(formats)], "%"+strconv.Itoa(level+1)+"."
+			}
+			buf.WriteString(`<w:lvl w:ilvl="` + strconv.Itoa(level) + `"><w:start w:val="1"/><w:numFmt w:val="` + format + `"/><w:lvlText w:val="` + text + `"/><w:lvlJc w:val="left"/>`)
+			buf.WriteString(`<w:pPr><w:ind w:left="` + strconv.Itoa(docxIndent*(level+1)) + `" w:hanging="360"/></w:pPr></w:lvl>`)
+		}
+		buf.WriteString(`</w:abstractNum>`)
+	}
+	buf.WriteString(`<w:num w:numId="` + strconv.Itoa(docxBulletNumID) + `"><w:abstractNumId w:val="0"/></w:num>`)
+	for i, num := range r.nums {
+		buf.WriteString(`<w:num w:numId="` + strconv.Itoa(docxBulletNumID+1+i) + `"><w:abstractNumId w:val="1"/>`)
+		buf.WriteString(`<w:lvlOverride w:ilvl="` + strconv.Itoa(num.level) + `"><w:startOverride w:val="` + strconv.Itoa(num.start) + `"/></w:lvlOverride></w:num>`)
+	}
+	buf.WriteString(`</w:numbering>`)
+	return buf.String()
+}
+
+func (r *DocxRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkContinue
+}
+
+func (r *DocxRenderer) renderSkip(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren
+}
+
+func (r *DocxRenderer) renderContainer(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkContinue
+}
+
+// listLevel 返回节点 node 所在列表的层级，从 0 开始，不在列表中时返回 -1。
+func (r *DocxRenderer) listLevel(node *ast.Node) (ret int) {
+	ret = -1
+	for p := node.Parent; nil != p; p = p.Parent {
+		if ast.NodeList == p.Type {
+			ret++
+		}
+	}
+	return
+}
+
+// paragraphProperties 返回段落 node 的段落属性，根据所在的列表、引述和脚注确定样式、编号和缩进。
+func (r *DocxRenderer) paragraphProperties(node *ast.Node) string {
+	style, numPr, indent := "", "", 0
+	quoteDepth := 0
+	for p := node.Parent; nil != p; p = p.Parent {
+		if ast.NodeBlockquote == p.Type {
+			quoteDepth++
+		}
+	}
+	if item := node.Parent; ast.NodeListItem == item.Type {
+		level := r.listLevel(node)
+		style = "ListParagraph"
+		if 3 != item.ListData.Typ && node == item.FirstChild {
+			numID := docxBulletNumID
+			if id, ok := r.listNums[item.Parent]; ok {
+				numID = id
+			}
+			numPr = `<w:numPr><w:ilvl w:val="` + strconv.Itoa(level) + `"/><w:numId w:val="` + strconv.Itoa(numID) + `"/></w:numPr>`
+		} else {
+			indent = docxIndent * (level + 1)
+		}
+	} else if 0 < quoteDepth {
+		style = "BlockText"
+		indent = docxIndent * quoteDepth
+	}
+	if r.footnote {
+		style = "FootnoteText"
+	}
+
+	buf := &bytes.Buffer{}
+	if "" != style {
+		buf.WriteString(`<w:pStyle w:val="` + style + `"/>`)
+	}
+	buf.WriteString(numPr)
+	if 0 < indent {
+		buf.WriteString(`<w:ind w:left="` + strconv.Itoa(indent) + `"/>`)
+	}
+	if 1 > buf.Len() {
+		return ""
+	}
+	return "<w:pPr>" + buf.String() + "</w:pPr>"
+}
+
+func (r *DocxRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteString("</w:p>")
+		return ast.WalkContinue
+	}
+
+	r.WriteString("<w:p>" + r.paragraphProperties(node))
+	if r.footnote && node == node.Parent.FirstChild {
+		r.WriteString(`<w:r><w:rPr><w:rStyle w:val="FootnoteReference"/></w:rPr><w:footnoteRef/></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r>`)
+	}
+	if item := node.Parent; ast.NodeListItem == item.Type && 3 == item.ListData.Typ && node == item.FirstChild {
+		if item.ListData.Checked {
+			r.writeRun("☒ ")
+		} else {
+			r.writeRun("☐ ")
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *DocxRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	id := node.ChildByType(ast.NodeHeadingID)
+	if !entering {
+		if nil != id {
+			r.WriteString(`<w:bookmarkEnd w:id="` + strconv.Itoa(r.bookmarks) + `"/>`)
+		}
+		r.WriteString("</w:p>")
+		return ast.WalkContinue
+	}
+
+	r.WriteString(`<w:p><w:pPr><w:pStyle w:val="Heading` + strconv.Itoa(node.HeadingLevel) + `"/></w:pPr>`)
+	if nil != id {
+		// 使用标题 ID 作为书签，文档内的锚点链接可以跳转到该标题
+		r.bookmarks++
+		name := strings.TrimPrefix(util.BytesToStr(id.Tokens), "#")
+		r.WriteString(`<w:bookmarkStart w:id="` + strconv.Itoa(r.bookmarks) + `" w:name="` + docxEscape(name) + `"/>`)
+	}
+	return ast.WalkContinue
+}
+
+// runProperties 返回当前行级样式对应的文本属性。
+func (r *DocxRenderer) runProperties() string {
+	buf := &bytes.Buffer{}
+	if 0 < r.run.code {
+		buf.WriteString(`<w:rStyle w:val="VerbatimChar"/>`)
+	} else if 0 < r.run.link {
+		buf.WriteString(`<w:rStyle w:val="Hyperlink"/>`)
+	}
+	if 0 < r.run.bold {
+		buf.WriteString("<w:b/>")
+	}
+	if 0 < r.run.italic {
+		buf.WriteString("<w:i/>")
+	}
+	if 0 < r.run.strike {
+		buf.WriteString("<w:strike/>")
+	}
+	if 0 < r.run.mark {
+		buf.WriteString(`<w:highlight w:val="yellow"/>`)
+	}
+	if 0 < r.run.underline {
+		buf.WriteString(`<w:u w:val="single"/>`)
+	}
+	if 0 < r.run.sup {
+		buf.WriteString(`<w:vertAlign w:val="superscript"/>`)
+	} else if 0 < r.run.sub {
+		buf.WriteString(`<w:vertAlign w:val="subscript"/>`)
+	}
+	if 1 > buf.Len() {
+		return ""
+	}
+	return "<w:rPr>" + buf.String() + "</w:rPr>"
+}
+
+// writeRun 使用当前行级样式输出文本 text，换行符输出为换行，制表符输出为制表位。
+func (r *DocxRenderer) writeRun(text string) {
+	if "" == text {
+		return
+	}
+	r.WriteString("<w:r>" + r.runProperties())
+	for i, line := range strings.Split(text, "\n") {
+		if 0 < i {
+			r.WriteString("<w:br/>")
+		}
+		for j, segment := range strings.Split(line, "\t") {
+			if 0 < j {
+				r.WriteString("<w:tab/>")
+			}
+			if "" != segment {
+				r.WriteString(`<w:t xml:space="preserve">` + docxEscape(segment) + "</w:t>")
+			}
+		}
+	}
+	r.WriteString("</w:r>")
+}
+
+func (r *DocxRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		text := util.BytesToStr(node.Tokens)
+		if prev := node.Previous; nil != prev && ast.NodeTaskListItemMarker == prev.Type {
+			text = strings.TrimLeft(text, " ")
+		}
+		r.writeRun(text)
+	}
+	return ast.WalkContinue
+}
+
+func (r *DocxRenderer) renderEmojiUnicode(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.writeRun(util.BytesToStr(node.Tokens))
+	}
+	return ast.WalkSkipChildren
+}
+
+// renderRunProp 返回一个在节点范围内启用行级样式的渲染函数，prop 返回该样式的计数。
+func (r *DocxRenderer) renderRunProp(prop func(p *docxRunProps) *int) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			*prop(&r.run)++
+		} else {
+			*prop(&r.run)--
+		}
+		return ast.WalkContinue
+	}
+}
+
+func (r *DocxRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeCodeSpanContent); nil != content {
+			r.run.code++
+			r.writeRun(util.BytesToStr(content.Tokens))
+			r.run.code--
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *DocxRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeInlineMathContent); nil != content {
+			r.run.italic++
+			r.writeRun(strings.TrimSpace(util.BytesToStr(content.Tokens)))
+			r.run.italic--
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *DocxRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("<w:r><w:br/></w:r>")
+	}
+	return ast.WalkContinue
+}
+
+func (r *DocxRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.writeRun(" ")
+	}
+	return ast.WalkContinue
+}
+
+// linkDest 返回链接或者图片 node 的地址。
+func (r *DocxRenderer) linkDest(node *ast.Node) string {
+	if dest := node.ChildByType(ast.NodeLinkDest); nil != dest {
+		return util.BytesToStr(r.LinkPath(dest.Tokens))
+	}
+	return ""
+}
+
+func (r *DocxRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.run.link--
+		r.WriteString("</w:hyperlink>")
+		return ast.WalkContinue
+	}
+
+	dest := r.linkDest(node)
+	if strings.HasPrefix(dest, "#") {
+		r.WriteString(`<w:hyperlink w:anchor="` + docxEscape(dest[1:]) + `">`)
+	} else {
+		r.WriteString(`<w:hyperlink r:id="` + r.rel("hyperlink", dest, true) + `">`)
+	}
+	r.run.link++
+	return ast.WalkContinue
+}
+
+// renderImage 嵌入本地图片，网络图片渲染为链接，无法读取的图片渲染为替代文本。
+func (r *DocxRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	dest, alt := r.linkDest(node), node.Text()
+	if strings.Contains(dest, "://") {
+		r.WriteString(`<w:hyperlink r:id="` + r.rel("hyperlink", dest, true) + `">`)
+		r.run.link++
+		if "" == alt {
+			alt = dest
+		}
+		r.writeRun(alt)
+		r.run.link--
+		r.WriteString("</w:hyperlink>")
+		return ast.WalkSkipChildren
+	}
+
+	path := dest
+	if unescaped, err := url.PathUnescape(path); nil == err {
+		path = unescaped
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.Options.DocxResourceDir, path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if nil != err {
+		r.writeRun(alt)
+		return ast.WalkSkipChildren
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if nil != err {
+		r.writeRun(alt)
+		return ast.WalkSkipChildren
+	}
+
+	name := "image" + strconv.Itoa(len(r.media)+1) + "." + format
+	r.media = append(r.media, &docxMedia{name: name, data: data})
+	id := r.rel("image", "media/"+name, false)
+	cx, cy := config.Width*docxEMUPerPixel, config.Height*docxEMUPerPixel
+	if maxWidth := docxTextWidth * docxEMUPerTwip; cx > maxWidth {
+		cx, cy = maxWidth, cy*maxWidth/cx
+	}
+	r.drawingID++
+	drawingID, extent := strconv.Itoa(r.drawingID), `cx="`+strconv.Itoa(cx)+`" cy="`+strconv.Itoa(cy)+`"`
+	r.WriteString(`<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0"><wp:extent ` + extent + `/>`)
+	r.WriteString(`<wp:docPr id="` + drawingID + `" name="Picture ` + drawingID + `" descr="` + docxEscape(alt) + `"/>`)
+	r.WriteString(`<wp:cNvGraphicFramePr><a:graphicFrameLocks noChangeAspect="1"/></wp:cNvGraphicFramePr>`)
+	r.WriteString(`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture"><pic:pic>`)
+	r.WriteString(`<pic:nvPicPr><pic:cNvPr id="` + drawingID + `" name="` + name + `"/><pic:cNvPicPr/></pic:nvPicPr>`)
+	r.WriteString(`<pic:blipFill><a:blip r:embed="` + id + `"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`)
+	r.WriteString(`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext ` + extent + `/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`)
+	r.WriteString(`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r>`)
+	return ast.WalkSkipChildren
+}
+
+// renderFootnotesRef 渲染脚注引用，脚注内容取自脚注定义，每次引用都生成一个 Word 脚注。
+func (r *DocxRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	_, def := r.Tree.FindFootnotesDef(node.Tokens)
+	if nil == def || r.footnote {
+		r.writeRun("[" + util.BytesToStr(node.Tokens) + "]")
+		return ast.WalkContinue
+	}
+
+	id := strconv.Itoa(len(r.footnotes) + 1)
+	r.WriteString(`<w:r><w:rPr><w:rStyle w:val="FootnoteReference"/></w:rPr><w:footnoteReference w:id="` + id + `"/></w:r>`)
+
+	// 将脚注定义的内容渲染到单独的缓冲中
+	writer, run := r.Writer, r.run
+	r.Writer, r.run, r.footnote = &bytes.Buffer{}, docxRunProps{}, true
+	for c := def.FirstChild; nil != c; c = c.Next {
+		ast.Walk(c, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if render := r.RendererFuncs[n.Type]; nil != render {
+				return render(n, entering)
+			}
+			return r.DefaultRendererFunc(n, entering)
+		})
+	}
+	r.footnotes = append(r.footnotes, `<w:footnote w:id="`+id+`">`+r.Writer.String()+`</w:footnote>`)
+	r.Writer, r.run, r.footnote = writer, run, false
+	return ast.WalkContinue
+}
+
+func (r *DocxRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering && 1 == node.ListData.Typ {
+		r.nums = append(r.nums, &docxNum{level: r.listLevel(node) + 1, start: node.ListData.Start})
+		r.listNums[node] = docxBulletNumID + len(r.nums)
+	}
+	return ast.WalkContinue
+}
+
+func (r *DocxRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString(`<w:p><w:pPr><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="1" w:color="auto"/></w:pBdr></w:pPr></w:p>`)
+	}
+	return ast.WalkContinue
+}
+
+func (r *DocxRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		code := ""
+		if content := node.ChildByType(ast.NodeCodeBlockCode); nil != content {
+			code = strings.TrimRight(util.BytesToStr(content.Tokens), "\n")
+		}
+		r.WriteString(`<w:p><w:pPr><w:pStyle w:val="SourceCode"/></w:pPr>`)
+		r.writeRun(code)
+		r.WriteString("</w:p>")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *DocxRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		content := ""
+		if c := node.ChildByType(ast.NodeMathBlockContent); nil != c {
+			content = strings.TrimSpace(util.BytesToStr(c.Tokens))
+		}
+		r.WriteString(`<w:p><w:pPr><w:jc w:val="center"/></w:pPr>`)
+		r.run.italic++
+		r.writeRun(content)
+		r.run.italic--
+		r.WriteString("</w:p>")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *DocxRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteString("</w:tbl>")
+		return ast.WalkContinue
+	}
+
+	r.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="Table"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr><w:tblGrid>`)
+	if cols := len(node.TableAligns); 0 < cols {
+		for i := 0; i < cols; i++ {
+			r.WriteString(`<w:gridCol w:w="` + strconv.Itoa(docxTextWidth/cols) + `"/>`)
+		}
+	}
+	r.WriteString("</w:tblGrid>")
+	return ast.WalkContinue
+}
+
+func (r *DocxRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteString("</w:tr>")
+		return ast.WalkContinue
+	}
+
+	r.WriteString("<w:tr>")
+	if ast.NodeTableHead == node.Parent.Type {
+		// 跨页时重复表头
+		r.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+	}
+	return ast.WalkContinue
+}
+
+func (r *DocxRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	header := ast.NodeTableHead == node.Parent.Parent.Type
+	if !entering {
+		if header {
+			r.run.bold--
+		}
+		r.WriteString("</w:p></w:tc>")
+		return ast.WalkContinue
+	}
+
+	width := docxTextWidth
+	if table := node.Parent.Parent; nil != table {
+		for ; nil != table && ast.NodeTable != table.Type; table = table.Parent {
+		}
+		if nil != table && 0 < len(table.TableAligns) {
+			width /= len(table.TableAligns)
+		}
+	}
+	r.WriteString(`<w:tc><w:tcPr><w:tcW w:w="` + strconv.Itoa(width) + `" w:type="dxa"/></w:tcPr><w:p>`)
+	switch node.TableCellAlign {
+	case 2:
+		r.WriteString(`<w:pPr><w:jc w:val="center"/></w:pPr>`)
+	case 3:
+		r.WriteString(`<w:pPr><w:jc w:val="right"/></w:pPr>`)
+	}
+	if header {
+		r.run.bold++
+	}
+	return ast.WalkContinue
+}
+
+// docxStyles 定义了渲染时使用的段落、字符和表格样式，标题样式使用 Word 内置的样式名以便生成目录和导航窗格。
+var docxStyles = docxXMLHeader + `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:eastAsia="Microsoft YaHei" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="22"/><w:szCs w:val="22"/><w:lang w:val="en-US" w:eastAsia="zh-CN"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="160" w:line="276" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>` +
+	docxHeadingStyles() +
+	`<w:style w:type="paragraph" w:styleId="ListParagraph"><w:name w:val="List Paragraph"/><w:basedOn w:val="Normal"/><w:qFormat/><w:pPr><w:spacing w:after="60"/><w:contextualSpacing/></w:pPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="BlockText"><w:name w:val="Block Text"/><w:basedOn w:val="Normal"/><w:qFormat/><w:pPr><w:pBdr><w:left w:val="single" w:sz="18" w:space="8" w:color="D0D7DE"/></w:pBdr></w:pPr><w:rPr><w:color w:val="57606A"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="SourceCode"><w:name w:val="Source Code"/><w:basedOn w:val="Normal"/><w:qFormat/><w:pPr><w:shd w:val="clear" w:color="auto" w:fill="F6F8FA"/><w:spacing w:line="240" w:lineRule="auto"/></w:pPr><w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas" w:cs="Consolas"/><w:sz w:val="20"/><w:szCs w:val="20"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="FootnoteText"><w:name w:val="footnote text"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr><w:rPr><w:sz w:val="18"/><w:szCs w:val="18"/></w:rPr></w:style>` +
+	`<w:style w:type="character" w:default="1" w:styleId="DefaultParagraphFont"><w:name w:val="Default Paragraph Font"/><w:uiPriority w:val="1"/><w:semiHidden/><w:unhideWhenUsed/></w:style>` +
+	`<w:style w:type="character" w:styleId="VerbatimChar"><w:name w:val="Verbatim Char"/><w:basedOn w:val="DefaultParagraphFont"/><w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas" w:cs="Consolas"/><w:sz w:val="20"/><w:szCs w:val="20"/><w:shd w:val="clear" w:color="auto" w:fill="F3F3F3"/></w:rPr></w:style>` +
+	`<w:style w:type="character" w:styleId="Hyperlink"><w:name w:val="Hyperlink"/><w:basedOn w:val="DefaultParagraphFont"/><w:rPr><w:color w:val="0563C1"/><w:u w:val="single"/></w:rPr></w:style>` +
+	`<w:style w:type="character" w:styleId="FootnoteReference"><w:name w:val="footnote reference"/><w:basedOn w:val="DefaultParagraphFont"/><w:rPr><w:vertAlign w:val="superscript"/></w:rPr></w:style>` +
+	`<w:style w:type="table" w:default="1" w:styleId="TableNormal"><w:name w:val="Normal Table"/><w:uiPriority w:val="99"/><w:semiHidden/><w:unhideWhenUsed/><w:tblPr><w:tblInd w:w="0" w:type="dxa"/><w:tblCellMar><w:top w:w="0" w:type="dxa"/><w:left w:w="108" w:type="dxa"/><w:bottom w:w="0" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>` +
+	`<w:style w:type="table" w:styleId="Table"><w:name w:val="Table"/><w:basedOn w:val="TableNormal"/><w:pPr><w:spacing w:before="60" w:after="60"/></w:pPr><w:tblPr><w:tblBorders>` +
+	`<w:top w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:left w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:bottom w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:right w:val="single" w:sz="4" w:space="0" w:color="auto"/>` +
+	`<w:insideH w:val="single" w:sz="4" w:space="0" w:color="auto"/><w:insideV w:val="single" w:sz="4" w:space="0" w:color="auto"/></w:tblBorders></w:tblPr></w:style>` +
+	`</w:styles>`
+
+func docxHeadingStyles() string {
+	buf := &bytes.Buffer{}
+	sizes := []int{36, 32, 28, 26, 24, 22}
+	for i, size := range sizes {
+		level := strconv.Itoa(i + 1)
+		buf.WriteString(`<w:style w:type="paragraph" w:styleId="Heading` + level + `"><w:name w:val="heading ` + level + `"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:uiPriority w:val="9"/><w:qFormat/>`)
+		buf.WriteString(`<w:pPr><w:keepNext/><w:keepLines/><w:spacing w:before="240" w:after="120"/><w:outlineLvl w:val="` + strconv.Itoa(i) + `"/></w:pPr>`)
+		buf.WriteString(`<w:rPr><w:b/><w:bCs/><w:sz w:val="` + strconv.Itoa(size) + `"/><w:szCs w:val="` + strconv.Itoa(size) + `"/></w:rPr></w:style>`)
+	}
+	return buf.String()
+}
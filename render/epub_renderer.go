@@ -0,0 +1,558 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// +build !javascript
+
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/html/atom"
+	"github.com/88250/lute/parse"
+	chromahtml "github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/styles"
+)
+
+// EPUBRenderer 描述了 EPUB 3 渲染器，将一个或多个语法树打包为 .epub 电子书。
+//
+// 每个语法树使用 HtmlRenderer 渲染后生成 XHTML 章节，打开 Options.EPUBSplitHeading 时还会在一级标题处拆分章节。
+// 目录 nav.xhtml 根据所有章节的标题大纲生成，元数据取自 YAML Front Matter（多个语法树时先出现的字段优先）。
+// 本地图片会嵌入到电子书中，相对路径基于语法树 Path 所在目录解析；网络图片渲染为链接。
+type EPUBRenderer struct {
+	Trees   []*parse.Tree // 待打包的语法树
+	Options *Options      // 渲染选项
+
+	chapters []*epubChapter
+	media    []*epubMedia
+	images   map[string]*epubMedia // 本地图片路径到嵌入图片的映射
+}
+
+// epubChapter 描述了一个 XHTML 章节。
+type epubChapter struct {
+	doc      int        // 所属语法树下标
+	file     string     // 包内文件名
+	body     *html.Node // 章节内容
+	headings []*epubNavItem
+}
+
+// epubMedia 描述了嵌入电子书的图片。
+type epubMedia struct {
+	file, mediaType string
+	data            []byte
+}
+
+// epubNavItem 描述了目录中的一项。
+type epubNavItem struct {
+	level       int
+	title, href string
+	children    []*epubNavItem
+}
+
+var epubImageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".svg":  "image/svg+xml",
+	".webp": "image/webp",
+}
+
+// NewEPUBRenderer 创建一个 EPUB 渲染器，trees 中的每个语法树按顺序生成章节。
+func NewEPUBRenderer(trees []*parse.Tree, options *Options) *EPUBRenderer {
+	return &EPUBRenderer{Trees: trees, Options: options}
+}
+
+func init() {
+	Register("epub", func(tree *parse.Tree, options *Options) Renderer {
+		return NewEPUBRenderer([]*parse.Tree{tree}, options)
+	})
+}
+
+// Render 渲染 .epub 压缩包。
+func (r *EPUBRenderer) Render() (output []byte) {
+	r.chapters, r.media, r.images = nil, nil, map[string]*epubMedia{}
+	meta := map[string]interface{}{}
+	for i, tree := range r.Trees {
+		r.mergeMetadata(meta, tree)
+		r.renderChapters(i, tree)
+	}
+	ids := r.chapterIDs()
+	for _, chapter := range r.chapters {
+		r.rewrite(chapter, ids)
+	}
+
+	lang := frontMatterValue(meta, "lang", "language")
+	if "" == lang {
+		lang = "en"
+	}
+	title := frontMatterValue(meta, "title")
+	if "" == title {
+		if 0 < len(r.chapters) && 0 < len(r.chapters[0].headings) {
+			title = r.chapters[0].headings[0].title
+		} else {
+			title = "Untitled"
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+	// mimetype 必须是第一个文件，并且不能压缩
+	writer, _ := archive.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	writer.Write([]byte("application/epub+zip"))
+	files := []struct {
+		name, content string
+	}{
+		{"META-INF/container.xml", `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container"><rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`},
+		{"OEBPS/content.opf", r.packageDocument(meta, title, lang)},
+		{"OEBPS/nav.xhtml", r.nav(title, lang)},
+		{"OEBPS/css/style.css", r.css()},
+	}
+	for _, chapter := range r.chapters {
+		files = append(files, struct{ name, content string }{"OEBPS/" + chapter.file, r.xhtml(chapter, title, lang)})
+	}
+	for _, file := range files {
+		writer, _ = archive.Create(file.name)
+		writer.Write([]byte(file.content))
+	}
+	for _, media := range r.media {
+		writer, _ = archive.Create("OEBPS/" + media.file)
+		writer.Write(media.data)
+	}
+	archive.Close()
+	return buf.Bytes()
+}
+
+// renderChapters 使用 HtmlRenderer 渲染语法树 tree，然后将结果拆分为章节。
+func (r *EPUBRenderer) renderChapters(doc int, tree *parse.Tree) {
+	options := *r.Options
+	options.HeadingID = true
+	options.HeadingAnchor = false
+	renderer := NewHtmlRenderer(tree, &options)
+	renderer.RendererFuncs[ast.NodeYamlFrontMatter] = func(node *ast.Node, entering bool) ast.WalkStatus {
+		return ast.WalkSkipChildren
+	}
+	output := renderer.Render()
+
+	nodes, _ := html.ParseFragment(bytes.NewReader(output), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	var chapter *epubChapter
+	for _, n := range nodes {
+		split := r.Options.EPUBSplitHeading && atom.H1 == n.DataAtom && nil != chapter && !epubBlank(chapter.body)
+		if nil == chapter || split {
+			chapter = &epubChapter{doc: doc, file: "chapter" + strconv.Itoa(len(r.chapters)+1) + ".xhtml", body: &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}}
+			r.chapters = append(r.chapters, chapter)
+		}
+		chapter.body.AppendChild(n)
+	}
+	if nil == chapter {
+		r.chapters = append(r.chapters, &epubChapter{doc: doc, file: "chapter" + strconv.Itoa(len(r.chapters)+1) + ".xhtml", body: &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}})
+	}
+}
+
+// chapterIDs 返回每个语法树中元素 ID 到所在章节文件的映射，用于改写跨章节的锚点链接。
+func (r *EPUBRenderer) chapterIDs() (ret []map[string]string) {
+	ret = make([]map[string]string, len(r.Trees))
+	for i := range ret {
+		ret[i] = map[string]string{}
+	}
+	for _, chapter := range r.chapters {
+		epubWalk(chapter.body, func(n *html.Node) {
+			if id := epubAttr(n, "id"); "" != id {
+				ret[chapter.doc][id] = chapter.file
+			}
+			if level := epubHeadingLevel(n); 0 < level && "" != epubAttr(n, "id") {
+				chapter.headings = append(chapter.headings, &epubNavItem{level: level, title: epubText(n), href: chapter.file + "#" + epubAttr(n, "id")})
+			}
+		})
+	}
+	return
+}
+
+// rewrite 改写章节中的图片和链接：嵌入本地图片，网络图片改为链接，锚点和跨文档链接指向对应的章节文件。
+func (r *EPUBRenderer) rewrite(chapter *epubChapter, ids []map[string]string) {
+	tree := r.Trees[chapter.doc]
+	var images []*html.Node
+	epubWalk(chapter.body, func(n *html.Node) {
+		switch n.DataAtom {
+		case atom.Img:
+			images = append(images, n)
+		case atom.A:
+			href := epubAttr(n, "href")
+			if strings.HasPrefix(href, "#") {
+				if id, file := epubLookupID(ids[chapter.doc], href[1:]); "" != file && file != chapter.file {
+					epubSetAttr(n, "href", file+"#"+id)
+				}
+				return
+			}
+			if strings.Contains(href, "://") || strings.HasPrefix(href, "mailto:") || "" == tree.Path {
+				return
+			}
+			link, fragment := href, ""
+			if idx := strings.Index(link, "#"); 0 <= idx {
+				link, fragment = link[:idx], link[idx+1:]
+			}
+			if unescaped, err := url.PathUnescape(link); nil == err {
+				link = unescaped
+			}
+			target := filepath.Clean(filepath.Join(filepath.Dir(tree.Path), link))
+			for doc, t := range r.Trees {
+				if "" == t.Path || filepath.Clean(t.Path) != target {
+					continue
+				}
+				if id, file := epubLookupID(ids[doc], fragment); "" != fragment && "" != file {
+					epubSetAttr(n, "href", file+"#"+id)
+				} else {
+					epubSetAttr(n, "href", r.firstChapter(doc))
+				}
+				break
+			}
+		}
+	})
+
+	for _, img := range images {
+		src := epubAttr(img, "src")
+		if strings.Contains(src, "://") {
+			// EPUB 不允许引用网络图片，改为链接
+			a := &html.Node{Type: html.ElementNode, Data: "a", DataAtom: atom.A, Attr: []*html.Attribute{{Key: "href", Val: src}}}
+			alt := epubAttr(img, "alt")
+			if "" == alt {
+				alt = src
+			}
+			a.AppendChild(&html.Node{Type: html.TextNode, Data: alt})
+			img.InsertBefore(a)
+			img.Unlink()
+			continue
+		}
+		if media := r.image(tree, src); nil != media {
+			epubSetAttr(img, "src", media.file)
+		}
+	}
+}
+
+// image 嵌入语法树 tree 中引用的本地图片 src，图片不存在或者类型不支持时返回 nil。
+func (r *EPUBRenderer) image(tree *parse.Tree, src string) *epubMedia {
+	if strings.HasPrefix(src, "data:") {
+		return nil
+	}
+	p := src
+	if unescaped, err := url.PathUnescape(p); nil == err {
+		p = unescaped
+	}
+	if !filepath.IsAbs(p) && "" != tree.Path {
+		p = filepath.Join(filepath.Dir(tree.Path), p)
+	}
+	p = filepath.Clean(p)
+	if media := r.images[p]; nil != media {
+		return media
+	}
+	mediaType := epubImageTypes[strings.ToLower(filepath.Ext(p))]
+	if "" == mediaType {
+		return nil
+	}
+	data, err := ioutil.ReadFile(p)
+	if nil != err {
+		return nil
+	}
+	media := &epubMedia{file: "images/image" + strconv.Itoa(len(r.media)+1) + path.Ext(strings.ToLower(filepath.ToSlash(p))), mediaType: mediaType, data: data}
+	r.media = append(r.media, media)
+	r.images[p] = media
+	return media
+}
+
+// epubLookupID 在元素 ID 映射 ids 中查找 id，找不到时忽略大小写查找，返回匹配的 ID 和所在章节文件。
+func epubLookupID(ids map[string]string, id string) (string, string) {
+	if file := ids[id]; "" != file {
+		return id, file
+	}
+	for k, file := range ids {
+		if strings.EqualFold(k, id) {
+			return k, file
+		}
+	}
+	return id, ""
+}
+
+func (r *EPUBRenderer) firstChapter(doc int) string {
+	for _, chapter := range r.chapters {
+		if doc == chapter.doc {
+			return chapter.file
+		}
+	}
+	return ""
+}
+
+// mergeMetadata 将语法树 tree 的 YAML Front Matter 合并到元数据 meta 中，已经存在的字段不会被覆盖。
+func (r *EPUBRenderer) mergeMetadata(meta map[string]interface{}, tree *parse.Tree) {
+	for k, v := range frontMatter(tree) {
+		if _, ok := meta[k]; !ok {
+			meta[k] = v
+		}
+	}
+}
+
+// packageDocument 返回包文档 content.opf。
+func (r *EPUBRenderer) packageDocument(meta map[string]interface{}, title, lang string) string {
+	identifier := frontMatterValue(meta, "identifier", "isbn", "uuid")
+	if "" == identifier {
+		// 没有指定标识符时根据章节内容生成稳定的 UUID
+		hash := sha1.New()
+		for _, chapter := range r.chapters {
+			hash.Write([]byte(epubRender(chapter.body)))
+		}
+		sum := hash.Sum(nil)
+		sum[6], sum[8] = sum[6]&0x0f|0x50, sum[8]&0x3f|0x80
+		identifier = fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="` + epubEscape(lang) + `">` + "\n")
+	buf.WriteString(`<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	buf.WriteString(`<dc:identifier id="book-id">` + epubEscape(identifier) + "</dc:identifier>\n")
+	buf.WriteString("<dc:title>" + epubEscape(title) + "</dc:title>\n")
+	buf.WriteString("<dc:language>" + epubEscape(lang) + "</dc:language>\n")
+	elements := []struct {
+		element string
+		keys    []string
+	}{
+		{"creator", []string{"author", "authors", "creator"}},
+		{"contributor", []string{"contributor", "contributors"}},
+		{"publisher", []string{"publisher"}},
+		{"date", []string{"date"}},
+		{"description", []string{"description", "summary"}},
+		{"rights", []string{"rights", "copyright", "license"}},
+		{"subject", []string{"subject", "tags", "keywords"}},
+	}
+	for _, e := range elements {
+		for _, value := range frontMatterValues(meta, e.keys...) {
+			buf.WriteString("<dc:" + e.element + ">" + epubEscape(value) + "</dc:" + e.element + ">\n")
+		}
+	}
+	buf.WriteString(`<meta property="dcterms:modified">` + time.Now().UTC().Format("2006-01-02T15:04:05Z") + "</meta>\n")
+	buf.WriteString("</metadata>\n<manifest>\n")
+	buf.WriteString(`<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` + "\n")
+	buf.WriteString(`<item id="css" href="css/style.css" media-type="text/css"/>` + "\n")
+	for _, chapter := range r.chapters {
+		buf.WriteString(`<item id="` + strings.TrimSuffix(chapter.file, ".xhtml") + `" href="` + chapter.file + `" media-type="application/xhtml+xml"`)
+		if epubHasElement(chapter.body, atom.Svg) {
+			buf.WriteString(` properties="svg"`)
+		}
+		buf.WriteString("/>\n")
+	}
+	for _, media := range r.media {
+		buf.WriteString(`<item id="` + strings.TrimSuffix(path.Base(media.file), path.Ext(media.file)) + `" href="` + media.file + `" media-type="` + media.mediaType + `"/>` + "\n")
+	}
+	buf.WriteString("</manifest>\n<spine>\n")
+	for _, chapter := range r.chapters {
+		buf.WriteString(`<itemref idref="` + strings.TrimSuffix(chapter.file, ".xhtml") + `"/>` + "\n")
+	}
+	buf.WriteString("</spine>\n</package>\n")
+	return buf.String()
+}
+
+// nav 返回导航文档 nav.xhtml，目录按照所有章节的标题层级嵌套，没有标题的章节使用书名加序号。
+func (r *EPUBRenderer) nav(title, lang string) string {
+	root := &epubNavItem{}
+	stack := []*epubNavItem{root}
+	for i, chapter := range r.chapters {
+		headings := chapter.headings
+		if 1 > len(headings) {
+			headings = []*epubNavItem{{level: 1, title: title + " " + strconv.Itoa(i+1), href: chapter.file}}
+		}
+		for _, heading := range headings {
+			item := &epubNavItem{level: heading.level, title: heading.title, href: heading.href}
+			for 1 < len(stack) && stack[len(stack)-1].level >= item.level {
+				stack = stack[:len(stack)-1]
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, item)
+			stack = append(stack, item)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(epubXHTMLHeader(title, lang, false))
+	buf.WriteString(`<nav epub:type="toc" id="toc">` + "\n<h1>" + epubEscape(title) + "</h1>\n")
+	epubRenderNav(buf, root.children)
+	buf.WriteString("</nav>\n</body>\n</html>\n")
+	return buf.String()
+}
+
+func epubRenderNav(buf *bytes.Buffer, items []*epubNavItem) {
+	if 1 > len(items) {
+		return
+	}
+	buf.WriteString("<ol>\n")
+	for _, item := range items {
+		buf.WriteString(`<li><a href="` + epubEscape(item.href) + `">` + epubEscape(item.title) + "</a>")
+		if 0 < len(item.children) {
+			buf.WriteString("\n")
+			epubRenderNav(buf, item.children)
+		}
+		buf.WriteString("</li>\n")
+	}
+	buf.WriteString("</ol>\n")
+}
+
+// css 返回电子书样式，包含默认样式、代码块语法高亮样式和 Options.EPUBCSS。
+func (r *EPUBRenderer) css() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString(epubCSS)
+	if r.Options.CodeSyntaxHighlight && !r.Options.CodeSyntaxHighlightInlineStyle {
+		formatter := chromahtml.New(chromahtml.WithClasses(true), chromahtml.ClassPrefix("highlight-"))
+		formatter.WriteCSS(buf, styles.Get(r.Options.CodeSyntaxHighlightStyleName))
+	}
+	if "" != r.Options.EPUBCSS {
+		buf.WriteString(r.Options.EPUBCSS)
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func (r *EPUBRenderer) xhtml(chapter *epubChapter, title, lang string) string {
+	if 0 < len(chapter.headings) {
+		title = chapter.headings[0].title
+	}
+	body := epubRender(chapter.body)
+	if "" != body && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return epubXHTMLHeader(title, lang, true) + body + "</body>\n</html>\n"
+}
+
+func epubXHTMLHeader(title, lang string, stylesheet bool) string {
+	ret := `<?xml version="1.0" encoding="UTF-8"?>` + "\n<!DOCTYPE html>\n"
+	ret += `<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="` + epubEscape(lang) + `" lang="` + epubEscape(lang) + `">` + "\n"
+	ret += "<head>\n<meta charset=\"UTF-8\"/>\n<title>" + epubEscape(title) + "</title>\n"
+	if stylesheet {
+		ret += `<link rel="stylesheet" type="text/css" href="css/style.css"/>` + "\n"
+	}
+	return ret + "</head>\n<body>\n"
+}
+
+// epubRender 将 body 的子节点渲染为 XHTML，Lute HTML 解析器渲染的结果总是闭合标签并且转义文本和属性值，
+// 但不会去掉 XML 1.0 中不允许出现的字符（比如控制字符），这里需要再过滤一次。
+func epubRender(body *html.Node) string {
+	buf := &bytes.Buffer{}
+	for c := body.FirstChild; nil != c; c = c.NextSibling {
+		html.Render(buf, c)
+	}
+	return strings.Map(xmlChar, buf.String())
+}
+
+var epubEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// epubEscape 转义 XML 文本和属性值，XML 1.0 中不允许出现的字符会被去掉。
+func epubEscape(str string) string {
+	return epubEscaper.Replace(strings.Map(xmlChar, str))
+}
+
+func epubWalk(n *html.Node, visitor func(n *html.Node)) {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if html.ElementNode == c.Type {
+			visitor(c)
+		}
+		epubWalk(c, visitor)
+	}
+}
+
+func epubHasElement(n *html.Node, a atom.Atom) (ret bool) {
+	epubWalk(n, func(c *html.Node) {
+		if a == c.DataAtom {
+			ret = true
+		}
+	})
+	return
+}
+
+// epubBlank 判断 n 是否只包含空白文本。
+func epubBlank(n *html.Node) bool {
+	for c := n.FirstChild; nil != c; c = c.NextSibling {
+		if html.TextNode != c.Type || "" != strings.TrimSpace(c.Data) {
+			return false
+		}
+	}
+	return true
+}
+
+func epubHeadingLevel(n *html.Node) int {
+	switch n.DataAtom {
+	case atom.H1:
+		return 1
+	case atom.H2:
+		return 2
+	case atom.H3:
+		return 3
+	case atom.H4:
+		return 4
+	case atom.H5:
+		return 5
+	case atom.H6:
+		return 6
+	}
+	return 0
+}
+
+func epubText(n *html.Node) string {
+	buf := &bytes.Buffer{}
+	var text func(n *html.Node)
+	text = func(n *html.Node) {
+		for c := n.FirstChild; nil != c; c = c.NextSibling {
+			if html.TextNode == c.Type {
+				buf.WriteString(c.Data)
+			}
+			text(c)
+		}
+	}
+	text(n)
+	return strings.TrimSpace(buf.String())
+}
+
+func epubAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if key == attr.Key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func epubSetAttr(n *html.Node, key, val string) {
+	for _, attr := range n.Attr {
+		if key == attr.Key {
+			attr.Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, &html.Attribute{Key: key, Val: val})
+}
+
+const epubCSS = `body { font-family: serif; line-height: 1.6; margin: 0 5%; }
+h1, h2, h3, h4, h5, h6 { font-family: sans-serif; line-height: 1.3; page-break-after: avoid; }
+pre { white-space: pre-wrap; word-wrap: break-word; background-color: #f6f8fa; padding: .6em; font-size: .85em; }
+code { font-family: monospace; }
+blockquote { margin: 1em 0; padding: 0 1em; border-left: .25em solid #d0d7de; color: #57606a; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #d0d7de; padding: .3em .6em; }
+img { max-width: 100%; }
+.footnotes-defs-div { font-size: .85em; }
+`
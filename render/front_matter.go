@@ -0,0 +1,80 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatter 解析语法树 tree 的 YAML Front Matter，字段名转换为小写，没有 Front Matter 或者解析失败时返回 nil。
+func frontMatter(tree *parse.Tree) (ret map[string]interface{}) {
+	frontMatter := tree.Root.ChildByType(ast.NodeYamlFrontMatter)
+	if nil == frontMatter {
+		return
+	}
+	content := frontMatter.ChildByType(ast.NodeYamlFrontMatterContent)
+	if nil == content {
+		return
+	}
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(content.Tokens, &m); nil != err {
+		return
+	}
+	ret = map[string]interface{}{}
+	for k, v := range m {
+		ret[strings.ToLower(k)] = v
+	}
+	return
+}
+
+// frontMatterValues 返回元数据 meta 中第一个存在的字段 keys 的值，字段值为列表时返回所有元素，日期格式化为 2006-01-02。
+func frontMatterValues(meta map[string]interface{}, keys ...string) (ret []string) {
+	for _, key := range keys {
+		value, ok := meta[key]
+		if !ok {
+			continue
+		}
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		for _, v := range values {
+			var str string
+			switch v := v.(type) {
+			case nil:
+				continue
+			case time.Time:
+				str = v.Format("2006-01-02")
+			default:
+				str = fmt.Sprint(v)
+			}
+			if str = strings.TrimSpace(str); "" != str {
+				ret = append(ret, str)
+			}
+		}
+		return
+	}
+	return
+}
+
+// frontMatterValue 返回元数据 meta 中第一个存在的字段 keys 的第一个值。
+func frontMatterValue(meta map[string]interface{}, keys ...string) string {
+	if values := frontMatterValues(meta, keys...); 0 < len(values) {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,197 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+)
+
+// HtmlDiffRenderer 描述了差异 HTML 渲染器，用于将两棵语法树的差异合并渲染为 HTML。
+//
+// 有变化的块使用 <div class="lute-diff lute-diff--{insert|delete|update|move}"> 包裹，修改的块中新增和删除的文本分别使用 <ins> 和 <del> 标记。
+type HtmlDiffRenderer struct {
+	*HtmlRenderer               // 新树渲染器
+	old           *HtmlRenderer // 旧树渲染器，用于渲染删除的块
+	diff          *parse.TreeDiff
+	pieces        []*parse.TextDiff // 正在渲染的修改块中尚未输出的文本差异
+	textNodes     int               // 正在渲染的修改块中尚未输出的文本节点数
+}
+
+// NewHtmlDiffRenderer 创建一个差异 HTML 渲染器。
+func NewHtmlDiffRenderer(diff *parse.TreeDiff, options *Options) *HtmlDiffRenderer {
+	ret := &HtmlDiffRenderer{HtmlRenderer: NewHtmlRenderer(diff.NewTree, options), old: NewHtmlRenderer(diff.OldTree, options), diff: diff}
+	for _, nodeType := range []ast.NodeType{ast.NodeText, ast.NodeLinkText, ast.NodeHTMLEntity} {
+		render := ret.RendererFuncs[nodeType]
+		ret.RendererFuncs[nodeType] = func(node *ast.Node, entering bool) ast.WalkStatus {
+			return ret.renderDiffText(node, entering, render)
+		}
+	}
+	return ret
+}
+
+// Render 渲染差异 HTML。
+func (r *HtmlDiffRenderer) Render() (output []byte) {
+	buf := &bytes.Buffer{}
+	r.Writer, r.old.Writer = buf, buf
+	r.LastOut = lex.ItemNewline
+	for _, op := range r.diff.Ops {
+		switch op.Type {
+		case parse.DiffEqual:
+			r.renderBlock(r.HtmlRenderer, op.New)
+		case parse.DiffInsert:
+			r.renderChange(op.Type, func() { r.renderBlock(r.HtmlRenderer, op.New) })
+		case parse.DiffDelete:
+			r.renderChange(op.Type, func() { r.renderBlock(r.old, op.Old) })
+		default:
+			if !r.hasTextChanges(op) {
+				if nil == op.Text {
+					// 仅移动
+					r.renderChange(op.Type, func() { r.renderBlock(r.HtmlRenderer, op.New) })
+					break
+				}
+
+				// 文本以外的内容（比如代码、链接地址）有修改时渲染删除的旧块和新增的新块
+				r.renderChange(op.Type, func() {
+					r.renderChange(parse.DiffDelete, func() { r.renderBlock(r.old, op.Old) })
+					r.renderChange(parse.DiffInsert, func() { r.renderBlock(r.HtmlRenderer, op.New) })
+				})
+				break
+			}
+
+			r.pieces = op.Text
+			r.textNodes = 0
+			ast.Walk(op.New, func(n *ast.Node, entering bool) ast.WalkStatus {
+				if entering && r.isDiffText(n) {
+					r.textNodes++
+				}
+				return ast.WalkContinue
+			})
+			r.renderChange(op.Type, func() { r.renderBlock(r.HtmlRenderer, op.New) })
+			r.pieces = nil
+		}
+	}
+	output = buf.Bytes()
+	return
+}
+
+// hasTextChanges 判断 op 的块内文本差异是否可以在新块中标记出来。
+func (r *HtmlDiffRenderer) hasTextChanges(op *parse.DiffOp) bool {
+	if "" == parse.DiffBlockText(op.New) {
+		return false
+	}
+	for _, piece := range op.Text {
+		if parse.DiffEqual != piece.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *HtmlDiffRenderer) renderChange(typ parse.DiffType, renderBlock func()) {
+	r.Newline()
+	r.WriteString("<div class=\"lute-diff lute-diff--" + typ.String() + "\">")
+	r.Newline()
+	renderBlock()
+	r.WriteString("</div>")
+	r.Newline()
+}
+
+// renderBlock 使用 renderer 渲染块 block。
+func (r *HtmlDiffRenderer) renderBlock(renderer *HtmlRenderer, block *ast.Node) {
+	renderer.LastOut = r.lastOut()
+	renderer.RenderingFootnotes = ast.NodeFootnotesDefBlock == block.Type
+	ast.Walk(block, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if render := renderer.RendererFuncs[n.Type]; nil != render {
+			return render(n, entering)
+		}
+		return renderer.renderDefault(n, entering)
+	})
+	renderer.RenderingFootnotes = false
+	r.LastOut = r.lastOut()
+}
+
+func (r *HtmlDiffRenderer) lastOut() byte {
+	if buf := r.Writer.Bytes(); 0 < len(buf) {
+		return buf[len(buf)-1]
+	}
+	return r.LastOut
+}
+
+// isDiffText 判断 n 是否参与块内文本差异比较，和 parse.DiffBlockText 一致。
+func (r *HtmlDiffRenderer) isDiffText(n *ast.Node) bool {
+	switch n.Type {
+	case ast.NodeText, ast.NodeHTMLEntity:
+		return !n.ParentIs(ast.NodeImage)
+	case ast.NodeLinkText:
+		return ast.NodeImage != n.Parent.Type
+	}
+	return false
+}
+
+// renderDiffText 渲染修改块中的文本节点，将文本节点对应的文本差异使用 <ins> 和 <del> 标记输出，每段文本仍然使用 render 渲染。
+func (r *HtmlDiffRenderer) renderDiffText(node *ast.Node, entering bool, render RendererFunc) ast.WalkStatus {
+	if nil == r.pieces || !r.isDiffText(node) {
+		return render(node, entering)
+	}
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	r.textNodes--
+	remaining := len(node.Tokens)
+	for 0 < len(r.pieces) {
+		piece := r.pieces[0]
+		if parse.DiffDelete == piece.Type {
+			if 1 > remaining && 0 < r.textNodes {
+				// 删除的文本位于下一个文本节点开头
+				break
+			}
+			r.renderPiece("del", ast.NodeText, piece.Text, r.HtmlRenderer.renderText)
+			r.pieces = r.pieces[1:]
+			continue
+		}
+		if 1 > remaining {
+			break
+		}
+
+		text := piece.Text
+		if len(text) > remaining {
+			text = text[:remaining]
+			r.pieces[0] = &parse.TextDiff{Type: piece.Type, Text: piece.Text[remaining:]}
+		} else {
+			r.pieces = r.pieces[1:]
+		}
+		remaining -= len(text)
+		tag := ""
+		if parse.DiffInsert == piece.Type {
+			tag = "ins"
+		}
+		r.renderPiece(tag, node.Type, text, render)
+	}
+	return ast.WalkContinue
+}
+
+// renderPiece 将文本片段 text 作为 typ 类型的节点使用 render 渲染，tag 不为空时使用 tag 包裹。
+func (r *HtmlDiffRenderer) renderPiece(tag string, typ ast.NodeType, text string, render RendererFunc) {
+	if "" != tag {
+		r.WriteString("<" + tag + ">")
+	}
+	piece := &ast.Node{Type: typ, Tokens: []byte(text)}
+	render(piece, true)
+	render(piece, false)
+	if "" != tag {
+		r.WriteString("</" + tag + ">")
+	}
+}
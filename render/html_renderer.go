@@ -873,6 +873,9 @@ func (r *HtmlRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkStatus
 		if r.Options.Sanitize {
 			tokens = sanitize(tokens)
 		}
+		if r.Options.GFMTagFilter {
+			tokens = tagFilter(tokens)
+		}
 		tokens = r.tagSrcPath(tokens)
 		r.Write(tokens)
 		r.Newline()
@@ -886,6 +889,9 @@ func (r *HtmlRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.WalkS
 		if r.Options.Sanitize {
 			tokens = sanitize(tokens)
 		}
+		if r.Options.GFMTagFilter {
+			tokens = tagFilter(tokens)
+		}
 		r.Write(tokens)
 	}
 	return ast.WalkContinue
@@ -1197,7 +1203,7 @@ func (r *HtmlRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkSt
 		if r.Options.SoftBreak2HardBreak {
 			r.Tag("br", nil, true)
 			r.Newline()
-		} else {
+		} else if !r.Options.JoinCJKSoftBreak || !node.IsCJKSoftBreak() { // 中日韩字符之间的软换行直接去掉，避免浏览器显示多余的空格
 			r.Newline()
 		}
 	}
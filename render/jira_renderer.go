@@ -0,0 +1,537 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/html"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// JiraRenderer 描述了 Jira 文本格式（wiki markup）渲染器，渲染结果可以直接作为 Jira 问题的描述和评论。
+//
+// 列表使用 * 和 # 按照嵌套层级叠加标记，任务列表项使用 (/) 和 (x) 图标表示完成状态，表头使用 ||，
+// [!NOTE] 等提示块渲染为带标题的 {panel}，脚注按照引用顺序编号后在末尾输出。
+type JiraRenderer struct {
+	*BaseRenderer
+	footnotes    []*ast.Node    // 引用过的脚注定义
+	footnoteNums map[string]int // 脚注标签到脚注序号的映射
+}
+
+// jiraLineStart 用于匹配位于行首时会被解析为列表标记或者分隔线的文本。
+var jiraLineStart = regexp.MustCompile(`^(?:[*#-]+\s|-{4,}\s*$)`)
+
+// jiraDotLineStart 用于匹配位于行首时会被解析为标题或者引述的文本，比如 h1. 和 bq.。
+var jiraDotLineStart = regexp.MustCompile(`^(?:h[1-6]|bq)\.\s`)
+
+// jiraSkippedBlocks 定义了不输出的块。
+var jiraSkippedBlocks = map[ast.NodeType]bool{
+	ast.NodeHTMLBlock: true, ast.NodeYamlFrontMatter: true, ast.NodeLinkRefDefBlock: true, ast.NodeFootnotesDefBlock: true, ast.NodeKramdownBlockIAL: true, ast.NodeToC: true,
+}
+
+// NewJiraRenderer 创建一个 Jira 文本格式渲染器。
+func NewJiraRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &JiraRenderer{BaseRenderer: NewBaseRenderer(tree, options)}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBlockRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeFileAnnotationRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBackslashContent] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiAlias] = ret.renderText
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderHTMLEntity
+	ret.RendererFuncs[ast.NodeEmojiUnicode] = ret.renderEmojiUnicode
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeKbd] = ret.renderWrapped("{{", "}}")
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderQuoted("_")
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderQuoted("*")
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderQuoted("-")
+	ret.RendererFuncs[ast.NodeUnderline] = ret.renderQuoted("+")
+	ret.RendererFuncs[ast.NodeSup] = ret.renderQuoted("^")
+	ret.RendererFuncs[ast.NodeSub] = ret.renderQuoted("~")
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeBr] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeTableRow] = ret.renderTableRow
+	ret.RendererFuncs[ast.NodeTableCell] = ret.renderTableCell
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeTaskListItemMarker] = ret.renderSkip
+	for typ := range jiraSkippedBlocks {
+		ret.RendererFuncs[typ] = ret.renderSkip
+	}
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeKramdownSpanIAL] = ret.renderSkip
+	// 标记等 Jira 不支持的样式只输出文本，标记符、链接地址等节点不输出
+	ret.DefaultRendererFunc = func(n *ast.Node, entering bool) ast.WalkStatus { return ast.WalkContinue }
+	return ret
+}
+
+func (r *JiraRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.footnotes, r.footnoteNums = nil, map[string]int{}
+	} else {
+		r.renderFootnotes()
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+func (r *JiraRenderer) renderSkip(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren
+}
+
+// renderNode 使用渲染器函数渲染单个节点，用于手动遍历子树。
+func (r *JiraRenderer) renderNode(n *ast.Node, entering bool) ast.WalkStatus {
+	if render := r.RendererFuncs[n.Type]; nil != render {
+		return render(n, entering)
+	}
+	return r.DefaultRendererFunc(n, entering)
+}
+
+// inItem 判断块 node 是否位于列表项或者脚注定义中，Jira 的列表项必须连续成行，所以其中的块之间不能有空行。
+func (r *JiraRenderer) inItem(node *ast.Node) bool {
+	return markupInside(node, ast.NodeListItem) || markupInside(node, ast.NodeFootnotesDef)
+}
+
+// blockStart 在块 node 之前换行，不是容器中的第一个块时使用空行和前一个块分隔。
+func (r *JiraRenderer) blockStart(node *ast.Node) {
+	r.Newline()
+	if r.inItem(node) || 1 > r.Writer.Len() || bytes.HasSuffix(r.Writer.Bytes(), []byte("\n\n")) {
+		return
+	}
+	if node != markupFirstBlock(node.Parent) {
+		r.WriteByte('\n')
+	}
+}
+
+// lastRune 返回已经输出的最后一个字符。
+func (r *JiraRenderer) lastRune() (ret rune) {
+	ret, _ = utf8.DecodeLastRune(r.Writer.Bytes())
+	return
+}
+
+// jiraEscape 转义文本 text 中可能被解析为 Jira 标记的字符，prev 和 next 为文本前后紧邻的字符。
+//
+// 花括号、方括号和竖线总是转义；*、_ 等成对标记符只有在可以作为开始标记时才转义，比如 well-known 中的连字符不需要转义。
+func jiraEscape(text string, prev, next rune) string {
+	buf := &strings.Builder{}
+	runes := []rune(text)
+	for i, c := range runes {
+		after := next
+		if i+1 < len(runes) {
+			after = runes[i+1]
+		}
+		switch c {
+		case '{', '}', '[', ']', '|':
+			buf.WriteByte('\\')
+		case '*', '_', '-', '+', '^', '~', '!':
+			// 后面没有相同的标记符时不会被当作开始标记，比如 Hello! 中的感叹号
+			if !markupWordChar(prev) && 0 != after && !unicode.IsSpace(after) && strings.ContainsRune(string(runes[i+1:]), c) {
+				buf.WriteByte('\\')
+			}
+		}
+		buf.WriteRune(c)
+		prev = c
+	}
+	return buf.String()
+}
+
+// writeText 转义并输出文本节点 node 的文本 text，位于行首时还需要转义列表标记、标题标记等块标记。
+func (r *JiraRenderer) writeText(node *ast.Node, text string) {
+	if "" == text {
+		return
+	}
+
+	escaped := jiraEscape(text, r.lastRune(), markupNextRune(node))
+	out := r.Writer.Bytes()
+	line := util.BytesToStr(out[bytes.LastIndexByte(out, '\n')+1:]) // 当前行已经输出的内容
+	full := line + text + jiraFollowingText(node)
+	if "" == line && jiraLineStart.MatchString(full) && !strings.HasPrefix(escaped, "\\") {
+		escaped = "\\" + escaped
+	} else if jiraDotLineStart.MatchString(full) && 2 >= len(line) && 2 < len(line)+len(text) {
+		// h1. 和 bq. 中的点可能位于后续的文本节点中，比如 h1\. 中转义的点
+		escaped = strings.Replace(escaped, ".", "\\.", 1)
+	}
+	r.WriteString(escaped)
+}
+
+// jiraFollowingText 返回文本节点 node 之后紧邻的文本，比如 \- foo 中转义的连字符之后的 " foo"。
+func jiraFollowingText(node *ast.Node) string {
+	if nil == node.Next && ast.NodeBackslash == node.Parent.Type {
+		node = node.Parent
+	}
+	buf := &strings.Builder{}
+	for n := node.Next; nil != n && (ast.NodeText == n.Type || ast.NodeBackslash == n.Type); n = n.Next {
+		buf.WriteString(n.Text())
+	}
+	return buf.String()
+}
+
+func (r *JiraRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		text := util.BytesToStr(node.Tokens)
+		if prev := node.Previous; nil != prev && ast.NodeTaskListItemMarker == prev.Type {
+			text = strings.TrimLeft(text, " ")
+		}
+		r.writeText(node, text)
+	}
+	return ast.WalkContinue
+}
+
+func (r *JiraRenderer) renderHTMLEntity(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.writeText(node, html.UnescapeString(util.BytesToStr(node.Tokens)))
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *JiraRenderer) renderEmojiUnicode(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Write(node.Tokens)
+	}
+	return ast.WalkSkipChildren
+}
+
+// renderQuoted 返回一个使用成对标记 mark 渲染行级节点的渲染函数，紧邻字母或者数字时使用 {*} 形式的标记。
+func (r *JiraRenderer) renderQuoted(mark string) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if markupWordChar(markupPrevRune(node)) || markupWordChar(markupNextRune(node)) {
+			r.WriteString("{" + mark + "}")
+		} else {
+			r.WriteString(mark)
+		}
+		return ast.WalkContinue
+	}
+}
+
+// renderWrapped 返回一个使用 open 和 close 包裹行级节点的渲染函数。
+func (r *JiraRenderer) renderWrapped(open, close string) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			r.WriteString(open)
+		} else {
+			r.WriteString(close)
+		}
+		return ast.WalkContinue
+	}
+}
+
+// writeMonospaced 使用 {{ 和 }} 输出代码，Jira 会解析等宽文本中的标记，所以内容同样需要转义。
+func (r *JiraRenderer) writeMonospaced(code string) {
+	r.WriteString("{{" + jiraEscape(code, '{', '}') + "}}")
+}
+
+func (r *JiraRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeCodeSpanContent); nil != content {
+			r.writeMonospaced(util.BytesToStr(content.Tokens))
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *JiraRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeInlineMathContent); nil != content {
+			r.writeMonospaced(util.BytesToStr(content.Tokens))
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *JiraRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+	if r.inItem(node) {
+		// 列表项的第一个段落紧跟在列表标记之后，后续段落另起一行
+		if node != markupFirstBlock(node.Parent) {
+			r.Newline()
+		}
+		return ast.WalkContinue
+	}
+	r.blockStart(node)
+	return ast.WalkContinue
+}
+
+func (r *JiraRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.blockStart(node)
+		r.WriteString("h" + strconv.Itoa(node.HeadingLevel) + ". ")
+	} else {
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+// renderBlockquote 将引述渲染为 {quote}，提示块渲染为以提示类型为标题的 {panel}。
+func (r *JiraRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	kind, content := admonition(node)
+	if "" == kind {
+		if entering {
+			r.blockStart(node)
+			r.WriteString("{quote}\n")
+		} else {
+			r.Newline()
+			r.WriteString("{quote}\n")
+		}
+		return ast.WalkContinue
+	}
+
+	if entering {
+		r.blockStart(node)
+		r.WriteString("{panel:title=" + kind[:1] + strings.ToLower(kind[1:]) + "}\n")
+		for c := content.FirstChild; nil != c; c = c.Next {
+			ast.Walk(c, r.renderNode)
+		}
+		r.Newline()
+		r.WriteString("{panel}\n")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *JiraRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.blockStart(node)
+	} else {
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+// renderListItem 输出列表标记，标记由所有外层列表的 *（无序）或者 #（有序）叠加而成，比如 #* 表示有序列表中嵌套的无序列表。
+func (r *JiraRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.Newline()
+		return ast.WalkContinue
+	}
+
+	var marker []byte
+	for p := node.Parent; nil != p; p = p.Parent {
+		if ast.NodeList == p.Type {
+			if 1 == p.ListData.Typ {
+				marker = append([]byte{'#'}, marker...)
+			} else {
+				marker = append([]byte{'*'}, marker...)
+			}
+		}
+	}
+	r.Newline()
+	r.WriteString(string(marker) + " ")
+	if 3 == node.ListData.Typ {
+		if node.ListData.Checked {
+			r.WriteString("(/) ")
+		} else {
+			r.WriteString("(x) ")
+		}
+	}
+	return ast.WalkContinue
+}
+
+// renderCodeBlock 将代码块渲染为 {code:language}，没有语言时使用 {code}。
+func (r *JiraRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	language := ""
+	if info := node.ChildByType(ast.NodeCodeBlockFenceInfoMarker); nil != info && 0 < len(info.CodeBlockInfo) {
+		language = strings.ToLower(strings.Fields(util.BytesToStr(info.CodeBlockInfo))[0])
+	}
+	code := ""
+	if content := node.ChildByType(ast.NodeCodeBlockCode); nil != content {
+		code = util.BytesToStr(content.Tokens)
+	}
+	r.blockStart(node)
+	if "" != language {
+		r.WriteString("{code:" + language + "}\n")
+	} else {
+		r.WriteString("{code}\n")
+	}
+	r.WriteString(strings.TrimSuffix(code, "\n") + "\n{code}\n")
+	return ast.WalkSkipChildren
+}
+
+// renderMathBlock 将数学公式块渲染为 {noformat}。
+func (r *JiraRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		content := ""
+		if c := node.ChildByType(ast.NodeMathBlockContent); nil != c {
+			content = strings.TrimSpace(util.BytesToStr(c.Tokens))
+		}
+		r.blockStart(node)
+		r.WriteString("{noformat}\n" + content + "\n{noformat}\n")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *JiraRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.blockStart(node)
+		r.WriteString("----\n")
+	}
+	return ast.WalkContinue
+}
+
+// renderHardBreak 在段落中使用换行表示强制换行，列表项和单元格中的换行会结束当前项，所以使用 \\。
+func (r *JiraRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if r.inItem(node) || markupInside(node, ast.NodeTableCell) {
+			r.WriteString("\\\\ ")
+		} else {
+			r.WriteString("\n")
+		}
+	}
+	return ast.WalkContinue
+}
+
+// renderSoftBreak 将软换行渲染为空格，Jira 会将段落中的换行渲染为强制换行。
+func (r *JiraRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte(' ')
+	}
+	return ast.WalkContinue
+}
+
+// renderLink 将链接渲染为 [text|url]，链接文本和地址相同时渲染为 [url]。
+func (r *JiraRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	dest := ""
+	if d := node.ChildByType(ast.NodeLinkDest); nil != d {
+		dest = util.BytesToStr(r.LinkPath(d.Tokens))
+	}
+	if 2 == node.LinkType || dest == node.Text() || "" == node.Text() {
+		if entering {
+			r.WriteString("[" + dest + "]")
+		}
+		return ast.WalkSkipChildren
+	}
+	if entering {
+		r.WriteString("[")
+	} else {
+		r.WriteString("|" + dest + "]")
+	}
+	return ast.WalkContinue
+}
+
+// renderImage 将图片渲染为 !src!，有替代文本时渲染为 !src|alt=text!。
+func (r *JiraRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	src, alt := "", ""
+	if d := node.ChildByType(ast.NodeLinkDest); nil != d {
+		src = util.BytesToStr(r.LinkPath(d.Tokens))
+	}
+	if t := node.ChildByType(ast.NodeLinkText); nil != t {
+		alt = strings.NewReplacer("!", "", "|", "", ",", "").Replace(util.BytesToStr(t.Tokens))
+	}
+	if "" != alt {
+		r.WriteString("!" + src + "|alt=" + alt + "!")
+	} else {
+		r.WriteString("!" + src + "!")
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *JiraRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.blockStart(node)
+	} else {
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+// renderTableRow 输出行结尾的分隔符，表头使用 ||。
+func (r *JiraRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		if ast.NodeTableHead == node.Parent.Type {
+			r.WriteString("||\n")
+		} else {
+			r.WriteString("|\n")
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *JiraRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if ast.NodeTableHead == node.Parent.Parent.Type {
+			r.WriteString("||")
+		} else {
+			r.WriteString("|")
+		}
+	} else if nil == node.FirstChild {
+		// 空单元格需要内容才能保持列数
+		r.WriteByte(' ')
+	}
+	return ast.WalkContinue
+}
+
+// renderFootnotesRef 将脚注引用渲染为 [n]，脚注内容在末尾以有序列表输出。
+func (r *JiraRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	label := strings.ToLower(util.BytesToStr(node.Tokens))
+	num, ok := r.footnoteNums[label]
+	if !ok {
+		_, def := r.Tree.FindFootnotesDef(node.Tokens)
+		if nil == def {
+			r.WriteString("\\[" + util.BytesToStr(node.Tokens) + "\\]")
+			return ast.WalkContinue
+		}
+		r.footnotes = append(r.footnotes, def)
+		num = len(r.footnotes)
+		r.footnoteNums[label] = num
+	}
+	r.WriteString("\\[" + strconv.Itoa(num) + "\\]")
+	return ast.WalkContinue
+}
+
+func (r *JiraRenderer) renderFootnotes() {
+	if 1 > len(r.footnotes) {
+		return
+	}
+
+	r.Newline()
+	r.WriteString("\n----\n")
+	// 脚注内容中也可能引用新的脚注，所以每次循环重新获取长度
+	for i := 0; i < len(r.footnotes); i++ {
+		r.Newline()
+		r.WriteString("# ")
+		for c := r.footnotes[i].FirstChild; nil != c; c = c.Next {
+			ast.Walk(c, r.renderNode)
+		}
+	}
+}
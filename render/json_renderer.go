@@ -318,15 +318,19 @@ func (r *JSONRenderer) renderCodeBlockInfoMarker(node *ast.Node, entering bool)
 }
 
 func (r *JSONRenderer) renderCodeBlockCode(node *ast.Node, entering bool) ast.WalkStatus {
+	var info []byte
+	if nil != node.Previous { // 缩进代码块没有开始标记符
+		info = node.Previous.CodeBlockInfo
+	}
 	var language string
-	if 0 < len(node.Previous.CodeBlockInfo) {
-		infoWords := lex.Split(node.Previous.CodeBlockInfo, lex.ItemSpace)
+	if 0 < len(info) {
+		infoWords := lex.Split(info, lex.ItemSpace)
 		language = util.BytesToStr(infoWords[0])
 	}
 	if entering {
 		r.openObj()
 		tokens := node.Tokens
-		if 0 < len(node.Previous.CodeBlockInfo) {
+		if 0 < len(info) {
 			r.language(ast.NodeCodeBlock, util.BytesToStr(tokens), language)
 
 			if "mindmap" == language {
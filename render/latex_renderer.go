@@ -0,0 +1,546 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// LaTeXRenderer 描述了 LaTeX 渲染器，用于导出可以直接编译的 LaTeX 文档。
+//
+// 数学公式原样输出，脚注渲染为 \footnote，表格使用 tabularx（设置 Options.LaTeXLongTable 时使用可以跨页的 longtable），
+// 代码块使用 listings（设置 Options.LaTeXMinted 时使用 minted，编译时需要 -shell-escape）。
+// 文档类和导言区通过 Options.LaTeXDocumentClass、Options.LaTeXDocumentClassOptions 和 Options.LaTeXPreamble 配置，
+// 设置 Options.LaTeXXeCJK 时引入 xeCJK 以支持中日韩文字，此时需要使用 XeLaTeX 编译。
+type LaTeXRenderer struct {
+	*BaseRenderer
+	footnotes map[string]int // 脚注标签到脚注序号的映射，重复引用同一个脚注时使用 \footnotemark
+}
+
+// latexEscaper 用于转义 LaTeX 文本中的特殊字符。
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `$`, `\$`, `&`, `\&`, `#`, `\#`, `%`, `\%`, `_`, `\_`,
+	`^`, `\textasciicircum{}`, `~`, `\textasciitilde{}`)
+
+// latexURLEscaper 用于转义 \href、\url 和 \includegraphics 参数中的特殊字符。
+var latexURLEscaper = strings.NewReplacer(`#`, `\#`, `%`, `\%`)
+
+// latexChapterClasses 定义了支持 \chapter 的文档类，使用这些文档类时一级标题渲染为 \chapter。
+var latexChapterClasses = map[string]bool{"book": true, "report": true, "memoir": true, "ctexbook": true, "ctexrep": true, "scrbook": true, "scrreprt": true}
+
+// latexListingsLanguages 定义了代码块语言到 listings 语言的映射，listings 加载不支持的语言时会编译出错，所以只映射支持的语言。
+var latexListingsLanguages = map[string]string{
+	"c": "C", "cpp": "C++", "c++": "C++", "java": "Java", "python": "Python", "py": "Python", "ruby": "Ruby", "rb": "Ruby",
+	"bash": "bash", "sh": "sh", "shell": "sh", "sql": "SQL", "html": "HTML", "xml": "XML", "php": "PHP", "perl": "Perl",
+	"tex": "TeX", "latex": "TeX", "haskell": "Haskell", "lisp": "Lisp", "matlab": "Matlab", "r": "R", "fortran": "Fortran",
+	"pascal": "Pascal", "make": "make", "makefile": "make",
+}
+
+// latexMintedLanguage 用于校验 minted 语言名，避免代码块信息中的特殊字符破坏文档结构。
+var latexMintedLanguage = regexp.MustCompile(`^[A-Za-z0-9+#._-]+$`)
+
+// latexSkippedBlocks 定义了不输出的块。
+var latexSkippedBlocks = map[ast.NodeType]bool{
+	ast.NodeHTMLBlock: true, ast.NodeYamlFrontMatter: true, ast.NodeLinkRefDefBlock: true, ast.NodeFootnotesDefBlock: true, ast.NodeKramdownBlockIAL: true,
+}
+
+// NewLaTeXRenderer 创建一个 LaTeX 渲染器。
+func NewLaTeXRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &LaTeXRenderer{BaseRenderer: NewBaseRenderer(tree, options), footnotes: map[string]int{}}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBlockRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeFileAnnotationRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBackslashContent] = ret.renderText
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiAlias] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiUnicode] = ret.renderEmojiUnicode
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderCommand("emph")
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderCommand("textbf")
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderCommand("sout")
+	ret.RendererFuncs[ast.NodeUnderline] = ret.renderCommand("uline")
+	ret.RendererFuncs[ast.NodeMark] = ret.renderCommand("hl")
+	ret.RendererFuncs[ast.NodeSup] = ret.renderCommand("textsuperscript")
+	ret.RendererFuncs[ast.NodeSub] = ret.renderCommand("textsubscript")
+	ret.RendererFuncs[ast.NodeKbd] = ret.renderCommand("texttt")
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeBr] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeTableHead] = ret.renderTableHead
+	ret.RendererFuncs[ast.NodeTableRow] = ret.renderTableRow
+	ret.RendererFuncs[ast.NodeTableCell] = ret.renderTableCell
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeToC] = ret.renderToC
+	ret.RendererFuncs[ast.NodeSuperBlock] = ret.renderSuperBlock
+	for typ := range latexSkippedBlocks {
+		ret.RendererFuncs[typ] = ret.renderSkip
+	}
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeKramdownSpanIAL] = ret.renderSkip
+	// 标记符、链接地址等节点不输出，只渲染它们的子节点
+	ret.DefaultRendererFunc = func(n *ast.Node, entering bool) ast.WalkStatus { return ast.WalkContinue }
+	return ret
+}
+
+func (r *LaTeXRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.footnotes = map[string]int{}
+		r.WriteString(r.preamble())
+		r.WriteString("\\begin{document}\n\n")
+	} else {
+		r.Newline()
+		r.WriteString("\n\\end{document}\n")
+	}
+	return ast.WalkContinue
+}
+
+// preamble 返回文档类声明和导言区。
+func (r *LaTeXRenderer) preamble() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString("\\documentclass")
+	if "" != r.Options.LaTeXDocumentClassOptions {
+		buf.WriteString("[" + r.Options.LaTeXDocumentClassOptions + "]")
+	}
+	buf.WriteString("{" + r.documentClass() + "}\n")
+	if r.Options.LaTeXXeCJK {
+		buf.WriteString("\\usepackage{xeCJK}\n")
+	} else {
+		buf.WriteString("\\usepackage[utf8]{inputenc}\n\\usepackage[T1]{fontenc}\n")
+	}
+	buf.WriteString("\\usepackage{amsmath,amssymb}\n")
+	buf.WriteString("\\usepackage{graphicx}\n")
+	buf.WriteString("\\usepackage{xcolor}\n")
+	buf.WriteString("\\usepackage{soul}\n")
+	buf.WriteString("\\usepackage[normalem]{ulem}\n")
+	buf.WriteString("\\usepackage{array,booktabs}\n")
+	if r.Options.LaTeXLongTable {
+		buf.WriteString("\\usepackage{longtable}\n")
+	} else {
+		buf.WriteString("\\usepackage{tabularx}\n")
+	}
+	if r.Options.LaTeXMinted {
+		buf.WriteString("\\usepackage{minted}\n")
+		buf.WriteString("\\setminted{breaklines=true,fontsize=\\small}\n")
+	} else {
+		buf.WriteString("\\usepackage{listings}\n")
+		buf.WriteString("\\lstset{basicstyle=\\ttfamily\\small,breaklines=true,columns=fullflexible,frame=single}\n")
+	}
+	// 图片宽度不超过版心宽度
+	buf.WriteString("\\makeatletter\n\\def\\maxwidth{\\ifdim\\Gin@nat@width>\\linewidth\\linewidth\\else\\Gin@nat@width\\fi}\n\\makeatother\n")
+	buf.WriteString("\\usepackage{hyperref}\n")
+	if preamble := strings.TrimSpace(r.Options.LaTeXPreamble); "" != preamble {
+		buf.WriteString(preamble + "\n")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+func (r *LaTeXRenderer) documentClass() string {
+	if "" == r.Options.LaTeXDocumentClass {
+		return "article"
+	}
+	return r.Options.LaTeXDocumentClass
+}
+
+// blockEnd 在块 node 结束后换行，非紧凑列表中的相邻块之间使用空行分隔。
+func (r *LaTeXRenderer) blockEnd(node *ast.Node) {
+	r.Newline()
+	next := node.Next
+	for nil != next && latexSkippedBlocks[next.Type] {
+		next = next.Next
+	}
+	if nil == next {
+		return
+	}
+	if parent := node.Parent; ast.NodeListItem == parent.Type && parent.Parent.ListData.Tight {
+		return
+	}
+	r.WriteByte('\n')
+}
+
+func (r *LaTeXRenderer) renderSkip(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren
+}
+
+func (r *LaTeXRenderer) renderSuperBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		text := util.BytesToStr(node.Tokens)
+		if prev := node.Previous; nil != prev && ast.NodeTaskListItemMarker == prev.Type {
+			text = strings.TrimLeft(text, " ")
+		}
+		r.WriteString(latexEscaper.Replace(text))
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderEmojiUnicode(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Write(node.Tokens)
+	}
+	return ast.WalkSkipChildren
+}
+
+// renderCommand 返回一个将行级节点渲染为 LaTeX 命令 \name{...} 的渲染函数。
+func (r *LaTeXRenderer) renderCommand(name string) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			r.WriteString("\\" + name + "{")
+		} else {
+			r.WriteString("}")
+		}
+		return ast.WalkContinue
+	}
+}
+
+func (r *LaTeXRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeCodeSpanContent); nil != content {
+			r.WriteString("\\texttt{" + latexEscaper.Replace(util.BytesToStr(content.Tokens)) + "}")
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *LaTeXRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeInlineMathContent); nil != content {
+			r.WriteString("$" + strings.TrimSpace(util.BytesToStr(content.Tokens)) + "$")
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *LaTeXRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeMathBlockContent); nil != content {
+			r.WriteString("\\[\n" + strings.TrimSpace(util.BytesToStr(content.Tokens)) + "\n\\]\n")
+		}
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *LaTeXRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	code := ""
+	if content := node.ChildByType(ast.NodeCodeBlockCode); nil != content {
+		code = strings.TrimRight(util.BytesToStr(content.Tokens), "\n")
+	}
+	language := ""
+	if info := strings.Fields(util.BytesToStr(node.CodeBlockInfo)); 0 < len(info) {
+		language = strings.ToLower(info[0])
+	}
+	if r.Options.LaTeXMinted {
+		if !latexMintedLanguage.MatchString(language) {
+			language = "text"
+		}
+		r.WriteString("\\begin{minted}{" + language + "}\n" + code + "\n\\end{minted}\n")
+	} else {
+		r.WriteString("\\begin{lstlisting}")
+		if lang := latexListingsLanguages[language]; "" != lang {
+			r.WriteString("[language=" + lang + "]")
+		}
+		r.WriteString("\n" + code + "\n\\end{lstlisting}\n")
+	}
+	r.blockEnd(node)
+	return ast.WalkSkipChildren
+}
+
+func (r *LaTeXRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.WriteString("\\begin{quote}\n")
+	} else {
+		r.Newline()
+		r.WriteString("\\end{quote}\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		commands := []string{"section", "subsection", "subsubsection", "paragraph", "subparagraph", "subparagraph"}
+		if latexChapterClasses[r.documentClass()] {
+			commands = []string{"chapter", "section", "subsection", "subsubsection", "paragraph", "subparagraph"}
+		}
+		r.WriteString("\\" + commands[node.HeadingLevel-1] + "{")
+	} else {
+		r.WriteString("}\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	env := "itemize"
+	if r.ordered(node) {
+		env = "enumerate"
+	}
+	if entering {
+		r.Newline()
+		r.WriteString("\\begin{" + env + "}\n")
+		if "enumerate" == env && 1 < node.ListData.Start {
+			// enumerate 最多嵌套四层，计数器依次为 enumi、enumii、enumiii 和 enumiv
+			depth := 0
+			for p := node.Parent; nil != p; p = p.Parent {
+				if ast.NodeList == p.Type && r.ordered(p) {
+					depth++
+				}
+			}
+			if counters := []string{"enumi", "enumii", "enumiii", "enumiv"}; depth < len(counters) {
+				r.WriteString("\\setcounter{" + counters[depth] + "}{" + strconv.Itoa(node.ListData.Start-1) + "}\n")
+			}
+		}
+	} else {
+		r.Newline()
+		r.WriteString("\\end{" + env + "}\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+// ordered 判断列表 list 是否是有序列表（包括有序任务列表）。
+func (r *LaTeXRenderer) ordered(list *ast.Node) bool {
+	return 1 == list.ListData.Typ || (3 == list.ListData.Typ && 0 == list.ListData.BulletChar)
+}
+
+func (r *LaTeXRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.WriteString("\\item")
+		if task := node.FirstChild; nil != task && nil != task.FirstChild && ast.NodeTaskListItemMarker == task.FirstChild.Type {
+			if task.FirstChild.TaskListItemChecked {
+				r.WriteString("[$\\boxtimes$]")
+			} else {
+				r.WriteString("[$\\square$]")
+			}
+		}
+		r.WriteString(" ")
+	} else {
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("\\begin{center}\\rule{0.5\\linewidth}{0.5pt}\\end{center}\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("\\newline\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte('\n')
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteString("}")
+		return ast.WalkContinue
+	}
+
+	dest := ""
+	if d := node.ChildByType(ast.NodeLinkDest); nil != d {
+		dest = latexURLEscaper.Replace(util.BytesToStr(r.LinkPath(d.Tokens)))
+	}
+	if text, d := node.ChildByType(ast.NodeLinkText), node.ChildByType(ast.NodeLinkDest); nil != text && nil != d && bytes.Equal(text.Tokens, d.Tokens) {
+		// 自动链接
+		r.WriteString("\\url{" + dest)
+		return ast.WalkSkipChildren
+	}
+	r.WriteString("\\href{" + dest + "}{")
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	dest := ""
+	if d := node.ChildByType(ast.NodeLinkDest); nil != d {
+		dest = util.BytesToStr(r.LinkPath(d.Tokens))
+	}
+	if strings.HasPrefix(dest, "http://") || strings.HasPrefix(dest, "https://") {
+		// LaTeX 不能引用网络图片，渲染为链接
+		alt := ""
+		if text := node.ChildByType(ast.NodeLinkText); nil != text {
+			alt = latexEscaper.Replace(util.BytesToStr(text.Tokens))
+		}
+		if "" == alt {
+			alt = "\\nolinkurl{" + latexURLEscaper.Replace(dest) + "}"
+		}
+		r.WriteString("\\href{" + latexURLEscaper.Replace(dest) + "}{" + alt + "}")
+		return ast.WalkSkipChildren
+	}
+	r.WriteString("\\includegraphics[width=\\maxwidth]{" + latexURLEscaper.Replace(dest) + "}")
+	return ast.WalkSkipChildren
+}
+
+func (r *LaTeXRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	env := "tabularx"
+	if r.Options.LaTeXLongTable {
+		env = "longtable"
+	}
+	if !entering {
+		r.WriteString("\\bottomrule\n\\end{" + env + "}\n")
+		r.blockEnd(node)
+		return ast.WalkContinue
+	}
+
+	spec := &bytes.Buffer{}
+	for _, align := range node.TableAligns {
+		if r.Options.LaTeXLongTable {
+			switch align {
+			case 2:
+				spec.WriteString("c")
+			case 3:
+				spec.WriteString("r")
+			default:
+				spec.WriteString("l")
+			}
+			continue
+		}
+		switch align {
+		case 2:
+			spec.WriteString(">{\\centering\\arraybackslash}X")
+		case 3:
+			spec.WriteString(">{\\raggedleft\\arraybackslash}X")
+		default:
+			spec.WriteString(">{\\raggedright\\arraybackslash}X")
+		}
+	}
+	if r.Options.LaTeXLongTable {
+		r.WriteString("\\begin{longtable}{" + spec.String() + "}\n")
+	} else {
+		r.WriteString("\\noindent\n\\begin{tabularx}{\\linewidth}{" + spec.String() + "}\n")
+	}
+	r.WriteString("\\toprule\n")
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderTableHead(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteString("\\midrule\n")
+		if r.Options.LaTeXLongTable {
+			// 跨页时重复表头
+			r.WriteString("\\endhead\n")
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteString(" \\\\\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering && nil != node.Previous {
+		r.WriteString(" & ")
+	}
+	return ast.WalkContinue
+}
+
+// renderFootnotesRef 将脚注引用渲染为 \footnote，脚注内容取自脚注定义，重复引用同一个脚注时渲染为 \footnotemark。
+func (r *LaTeXRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	label := strings.ToLower(util.BytesToStr(node.Tokens))
+	if num, ok := r.footnotes[label]; ok {
+		r.WriteString("\\footnotemark[" + strconv.Itoa(num) + "]")
+		return ast.WalkContinue
+	}
+	_, def := r.Tree.FindFootnotesDef(node.Tokens)
+	if nil == def {
+		r.WriteString(latexEscaper.Replace("[" + util.BytesToStr(node.Tokens) + "]"))
+		return ast.WalkContinue
+	}
+	r.footnotes[label] = len(r.footnotes) + 1
+
+	// 将脚注定义的内容渲染到单独的缓冲中，然后去掉末尾的空行
+	writer := r.Writer
+	r.Writer = &bytes.Buffer{}
+	for c := def.FirstChild; nil != c; c = c.Next {
+		ast.Walk(c, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if render := r.RendererFuncs[n.Type]; nil != render {
+				return render(n, entering)
+			}
+			return r.DefaultRendererFunc(n, entering)
+		})
+	}
+	content := strings.TrimSpace(r.Writer.String())
+	r.Writer = writer
+	r.WriteString("\\footnote{" + content + "}")
+	return ast.WalkContinue
+}
+
+func (r *LaTeXRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("\\tableofcontents\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
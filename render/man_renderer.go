@@ -0,0 +1,582 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// ManRenderer 描述了 man(7) roff 渲染器，用于将 Markdown 编写的命令行工具手册渲染为 man 手册页。
+//
+// 第一个一级标题渲染为 .TH，标题形如 lute(1) -- a markdown engine 时解析出名称、章节并生成 NAME 节；其他一级标题和二级标题渲染为 .SH，更低级的标题渲染为 .SS。
+// 首行只有代码或者加粗的无序列表项渲染为 .TP 选项列表，其他列表项渲染为 .IP，代码块使用 .nf/.fi，表格使用 tbl 预处理器。
+// .TH 的章节、日期、来源和手册名取自 YAML Front Matter 中的 section、date、source 和 manual 字段。
+type ManRenderer struct {
+	*BaseRenderer
+	fonts        []string       // 行级字体栈
+	itemStart    bool           // 是否刚输出列表项宏，此时段落不需要再输出段落宏
+	title        *ast.Node      // 渲染为 .TH 的一级标题
+	footnotes    []*ast.Node    // 引用过的脚注定义，在文档末尾渲染为 NOTES 节
+	footnoteNums map[string]int // 脚注标签到脚注序号的映射
+}
+
+// manTitle 用于解析 ronn 风格的手册标题 name(section) -- description。
+var manTitle = regexp.MustCompile(`^([^\s()]+)\(([0-9A-Za-z]+)\)(?:\s+-{1,2}\s+(.*))?$`)
+
+// manEscaper 用于转义 roff 文本中的反斜杠和连字符，连字符转义后才能在终端中复制出 ASCII 减号。
+var manEscaper = strings.NewReplacer(`\`, `\e`, `-`, `\-`)
+
+// manSkippedBlocks 定义了不输出的块。
+var manSkippedBlocks = map[ast.NodeType]bool{
+	ast.NodeHTMLBlock: true, ast.NodeYamlFrontMatter: true, ast.NodeLinkRefDefBlock: true, ast.NodeFootnotesDefBlock: true, ast.NodeKramdownBlockIAL: true, ast.NodeToC: true,
+}
+
+// NewManRenderer 创建一个 man 渲染器。
+func NewManRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &ManRenderer{BaseRenderer: NewBaseRenderer(tree, options)}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBlockRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeFileAnnotationRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBackslashContent] = ret.renderText
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiAlias] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiUnicode] = ret.renderEmojiUnicode
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderFont("I")
+	ret.RendererFuncs[ast.NodeUnderline] = ret.renderFont("I")
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderFont("B")
+	ret.RendererFuncs[ast.NodeKbd] = ret.renderFont("B")
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeBr] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeTableRow] = ret.renderTableRow
+	ret.RendererFuncs[ast.NodeTableCell] = ret.renderTableCell
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeTaskListItemMarker] = ret.renderSkip
+	for typ := range manSkippedBlocks {
+		ret.RendererFuncs[typ] = ret.renderSkip
+	}
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeKramdownSpanIAL] = ret.renderSkip
+	// 删除线、标记、上下标等 roff 不支持的样式只输出文本，标记符、链接地址等节点不输出
+	ret.DefaultRendererFunc = func(n *ast.Node, entering bool) ast.WalkStatus { return ast.WalkContinue }
+	return ret
+}
+
+func (r *ManRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.renderFootnotes()
+		r.Newline()
+		return ast.WalkContinue
+	}
+
+	r.fonts, r.itemStart, r.footnotes, r.footnoteNums = nil, false, nil, map[string]int{}
+	r.title = nil
+	for c := node.FirstChild; nil != c; c = c.Next {
+		if ast.NodeHeading == c.Type && 1 == c.HeadingLevel {
+			r.title = c
+			break
+		}
+	}
+
+	meta := frontMatter(r.Tree)
+	name, section, description := "", frontMatterValue(meta, "section"), ""
+	if nil != r.title {
+		name = strings.TrimSpace(r.title.Text())
+		if m := manTitle.FindStringSubmatch(name); nil != m {
+			name, description = m[1], m[3]
+			if "" == section {
+				section = m[2]
+			}
+		}
+	}
+	if title := frontMatterValue(meta, "title", "name"); "" != title {
+		name = title
+	}
+	if "" == name {
+		name = r.Tree.Name
+	}
+	if "" == section {
+		section = "1"
+	}
+
+	if nil != node.ChildByType(ast.NodeTable) {
+		// 告诉 man 使用 tbl 预处理，必须是文件第一行
+		r.WriteString("'\\\" t\n")
+	}
+	r.WriteString(".TH " + manQuote(strings.ToUpper(name)) + " " + manQuote(section) + " " + manQuote(frontMatterValue(meta, "date")) + " " +
+		manQuote(frontMatterValue(meta, "source")) + " " + manQuote(frontMatterValue(meta, "manual")) + "\n")
+	if "" != description {
+		r.WriteString(".SH NAME\n" + manEscaper.Replace(name) + " \\- " + r.line(manEscaper.Replace(description)) + "\n")
+	}
+	return ast.WalkContinue
+}
+
+// manQuote 返回 roff 宏的引号参数。
+func manQuote(arg string) string {
+	return "\"" + strings.ReplaceAll(manEscaper.Replace(arg), "\"", "\\(dq") + "\""
+}
+
+// line 转义行首的控制字符 . 和 '，避免文本被当作 roff 请求。
+func (r *ManRenderer) line(text string) string {
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		return "\\&" + text
+	}
+	return text
+}
+
+func (r *ManRenderer) renderSkip(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren
+}
+
+// inItem 判断块 node 是否直接位于列表项或者脚注定义中，此时后续段落需要使用 .IP 保持缩进。
+func (r *ManRenderer) inItem(node *ast.Node) bool {
+	for p := node.Parent; nil != p; p = p.Parent {
+		switch p.Type {
+		case ast.NodeListItem, ast.NodeFootnotesDef:
+			return true
+		case ast.NodeBlockquote, ast.NodeDocument:
+			return false
+		}
+	}
+	return false
+}
+
+// blockStart 输出块的段落宏。
+func (r *ManRenderer) blockStart(node *ast.Node) {
+	r.Newline()
+	if r.itemStart {
+		r.itemStart = false
+	} else if r.inItem(node) {
+		r.WriteString(".IP\n")
+	} else {
+		r.WriteString(".PP\n")
+	}
+}
+
+func (r *ManRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.blockStart(node)
+	} else {
+		r.Newline()
+	}
+	return ast.WalkContinue
+}
+
+func (r *ManRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+	if node == r.title {
+		return ast.WalkSkipChildren
+	}
+
+	r.Newline()
+	text := strings.TrimSpace(node.Text())
+	if id := node.ChildByType(ast.NodeHeadingID); nil != id {
+		text = strings.TrimSpace(strings.TrimSuffix(text, util.BytesToStr(id.Tokens)))
+	}
+	if 3 > node.HeadingLevel {
+		r.WriteString(".SH " + manQuote(strings.ToUpper(text)) + "\n")
+	} else {
+		r.WriteString(".SS " + manQuote(text) + "\n")
+	}
+	r.itemStart = false
+	return ast.WalkSkipChildren
+}
+
+func (r *ManRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.writeText(util.BytesToStr(node.Tokens))
+	}
+	return ast.WalkContinue
+}
+
+// writeText 转义并输出文本，位于行首时去掉前导空格，避免 roff 将其当作换行。
+func (r *ManRenderer) writeText(text string) {
+	if lex.ItemNewline == r.LastOut {
+		text = strings.TrimLeft(text, " ")
+		r.WriteString(r.line(manEscaper.Replace(text)))
+		return
+	}
+	r.WriteString(manEscaper.Replace(text))
+}
+
+func (r *ManRenderer) renderEmojiUnicode(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.writeText(util.BytesToStr(node.Tokens))
+	}
+	return ast.WalkSkipChildren
+}
+
+// font 返回当前字体栈对应的 roff 字体。
+func (r *ManRenderer) font() string {
+	bold, italic := false, false
+	for _, f := range r.fonts {
+		bold = bold || "B" == f
+		italic = italic || "I" == f
+	}
+	switch {
+	case bold && italic:
+		return "\\f(BI"
+	case bold:
+		return "\\fB"
+	case italic:
+		return "\\fI"
+	}
+	return "\\fR"
+}
+
+// renderFont 返回一个在节点范围内切换到字体 font 的渲染函数，退出时恢复外层字体。
+func (r *ManRenderer) renderFont(font string) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			r.fonts = append(r.fonts, font)
+		} else {
+			r.fonts = r.fonts[:len(r.fonts)-1]
+		}
+		r.WriteString(r.font())
+		return ast.WalkContinue
+	}
+}
+
+// renderCodeSpan 按照 man 手册的惯例使用粗体渲染代码。
+func (r *ManRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeCodeSpanContent); nil != content {
+			r.fonts = append(r.fonts, "B")
+			r.WriteString(r.font())
+			r.writeText(util.BytesToStr(content.Tokens))
+			r.fonts = r.fonts[:len(r.fonts)-1]
+			r.WriteString(r.font())
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ManRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeInlineMathContent); nil != content {
+			r.writeText(util.BytesToStr(content.Tokens))
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ManRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("\n.br\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ManRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("\n")
+	}
+	return ast.WalkContinue
+}
+
+// renderLink 输出链接文本和尖括号中的地址，链接文本和地址相同时只输出地址，\% 禁止地址断字。
+func (r *ManRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	dest := ""
+	if d := node.ChildByType(ast.NodeLinkDest); nil != d {
+		dest = util.BytesToStr(r.LinkPath(d.Tokens))
+	}
+	if 2 == node.LinkType || strings.TrimPrefix(dest, "mailto:") == node.Text() {
+		if entering {
+			r.WriteString("\\%" + manEscaper.Replace(node.Text()))
+		}
+		return ast.WalkSkipChildren
+	}
+	if !entering && "" != dest && !strings.HasPrefix(dest, "#") {
+		r.WriteString(" <\\%" + manEscaper.Replace(dest) + ">")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ManRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if text := node.ChildByType(ast.NodeLinkText); nil != text {
+			r.writeText(util.BytesToStr(text.Tokens))
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ManRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	r.Newline()
+	if entering {
+		r.itemStart = false
+		r.WriteString(".RS 4\n")
+	} else {
+		r.WriteString(".RE\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ManRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	if !r.inItem(node) {
+		return ast.WalkContinue
+	}
+
+	// 嵌套列表使用 .RS 将左边距移动到外层列表项的缩进处
+	r.Newline()
+	if entering {
+		r.itemStart = false
+		r.WriteString(".RS\n")
+	} else {
+		r.WriteString(".RE\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ManRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.itemStart = false
+		return ast.WalkContinue
+	}
+
+	r.Newline()
+	if term := r.term(node); nil != term {
+		// 选项列表：首行作为 .TP 的标签，其余内容作为描述
+		r.WriteString(".TP\n")
+		r.itemStart = false
+		for c := node.FirstChild.FirstChild; c != term; c = c.Next {
+			if c.Next == term && ast.NodeText == c.Type {
+				// 去掉标签结尾的冒号
+				r.writeText(strings.TrimRight(util.BytesToStr(c.Tokens), ": "))
+				continue
+			}
+			ast.Walk(c, r.renderNode)
+		}
+		r.WriteString("\n")
+		for c := term.Next; nil != c; {
+			next := c.Next
+			ast.Walk(c, r.renderNode)
+			c = next
+		}
+		r.Newline()
+		for c := node.FirstChild.Next; nil != c; c = c.Next {
+			ast.Walk(c, r.renderNode)
+		}
+		return ast.WalkSkipChildren
+	}
+
+	switch {
+	case 3 == node.ListData.Typ && node.ListData.Checked:
+		r.WriteString(".IP [x] 4\n")
+	case 3 == node.ListData.Typ:
+		r.WriteString(".IP [\\ ] 4\n")
+	case 1 == node.ListData.Typ:
+		r.WriteString(".IP " + strconv.Itoa(node.ListData.Num) + string(node.ListData.Delimiter) + " 4\n")
+	default:
+		r.WriteString(".IP \\(bu 2\n")
+	}
+	r.itemStart = true
+	return ast.WalkContinue
+}
+
+// term 判断无序列表项 item 是否是选项列表项：第一个段落以代码或者加粗开头，首行只有代码、加粗、强调和标点（比如 `-v`, `--verbose`:），并且还有后续行。
+// 是选项列表项时返回首行结尾的软换行。
+func (r *ManRenderer) term(item *ast.Node) *ast.Node {
+	if 0 != item.ListData.Typ {
+		return nil
+	}
+	paragraph := item.FirstChild
+	if nil == paragraph || ast.NodeParagraph != paragraph.Type {
+		return nil
+	}
+	first := paragraph.FirstChild
+	if nil == first || (ast.NodeCodeSpan != first.Type && ast.NodeStrong != first.Type) {
+		return nil
+	}
+	for c := first.Next; nil != c; c = c.Next {
+		switch c.Type {
+		case ast.NodeSoftBreak:
+			if nil == c.Next {
+				return nil
+			}
+			return c
+		case ast.NodeCodeSpan, ast.NodeStrong, ast.NodeEmphasis:
+		case ast.NodeText:
+			if "" != strings.Trim(util.BytesToStr(c.Tokens), " ,:|=[]<>") {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// renderNode 使用渲染器函数渲染单个节点，用于手动遍历子树。
+func (r *ManRenderer) renderNode(n *ast.Node, entering bool) ast.WalkStatus {
+	if render := r.RendererFuncs[n.Type]; nil != render {
+		return render(n, entering)
+	}
+	return r.DefaultRendererFunc(n, entering)
+}
+
+// writeLiteral 在 .nf/.fi 之间原样输出代码行，列表项中的代码块保持列表项缩进，其他代码块缩进 4 个空格。
+func (r *ManRenderer) writeLiteral(node *ast.Node, code string) {
+	indent := !r.itemStart && !r.inItem(node)
+	r.blockStart(node)
+	if indent {
+		r.WriteString(".RS 4\n")
+	}
+	r.WriteString(".nf\n")
+	for _, line := range strings.Split(strings.TrimRight(code, "\n"), "\n") {
+		r.WriteString(r.line(manEscaper.Replace(line)) + "\n")
+	}
+	r.WriteString(".fi\n")
+	if indent {
+		r.WriteString(".RE\n")
+	}
+}
+
+func (r *ManRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		code := ""
+		if content := node.ChildByType(ast.NodeCodeBlockCode); nil != content {
+			code = util.BytesToStr(content.Tokens)
+		}
+		r.writeLiteral(node, code)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ManRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		content := ""
+		if c := node.ChildByType(ast.NodeMathBlockContent); nil != c {
+			content = strings.TrimSpace(util.BytesToStr(c.Tokens))
+		}
+		r.writeLiteral(node, content)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *ManRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Newline()
+		r.itemStart = false
+		r.WriteString(".sp\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *ManRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	r.Newline()
+	if !entering {
+		r.WriteString(".TE\n")
+		return ast.WalkContinue
+	}
+
+	r.blockStart(node)
+	r.WriteString(".TS\nallbox;\n")
+	var head, body []string
+	for _, align := range node.TableAligns {
+		format := "l"
+		switch align {
+		case 2:
+			format = "c"
+		case 3:
+			format = "r"
+		}
+		head = append(head, format+"b")
+		body = append(body, format)
+	}
+	r.WriteString(strings.Join(head, " ") + "\n" + strings.Join(body, " ") + ".\n")
+	return ast.WalkContinue
+}
+
+func (r *ManRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.WriteString("\n")
+	}
+	return ast.WalkContinue
+}
+
+// renderTableCell 使用 T{ 和 T} 包裹单元格，单元格内容可以自动换行，列之间使用制表符分隔。
+func (r *ManRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if nil != node.Previous {
+			r.WriteString("\t")
+		}
+		r.WriteString("T{\n")
+	} else {
+		r.Newline()
+		r.WriteString("T}")
+	}
+	return ast.WalkContinue
+}
+
+// renderFootnotesRef 将脚注引用渲染为 [n]，脚注内容在文档末尾的 NOTES 节中输出。
+func (r *ManRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	label := strings.ToLower(util.BytesToStr(node.Tokens))
+	num, ok := r.footnoteNums[label]
+	if !ok {
+		_, def := r.Tree.FindFootnotesDef(node.Tokens)
+		if nil == def {
+			r.writeText("[" + util.BytesToStr(node.Tokens) + "]")
+			return ast.WalkContinue
+		}
+		r.footnotes = append(r.footnotes, def)
+		num = len(r.footnotes)
+		r.footnoteNums[label] = num
+	}
+	r.WriteString("[" + strconv.Itoa(num) + "]")
+	return ast.WalkContinue
+}
+
+func (r *ManRenderer) renderFootnotes() {
+	if 1 > len(r.footnotes) {
+		return
+	}
+
+	r.Newline()
+	r.WriteString(".SH NOTES\n")
+	// 脚注内容中也可能引用新的脚注，所以每次循环重新获取长度
+	for i := 0; i < len(r.footnotes); i++ {
+		r.Newline()
+		r.WriteString(".IP [" + strconv.Itoa(i+1) + "] 4\n")
+		r.itemStart = true
+		for c := r.footnotes[i].FirstChild; nil != c; c = c.Next {
+			ast.Walk(c, r.renderNode)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// 该文件定义了 AsciiDoc 和 reStructuredText 渲染器共用的辅助函数。
+
+// markupAdmonitions 定义了可以从引述转换为提示块的提示类型。
+var markupAdmonitions = []string{"NOTE", "TIP", "IMPORTANT", "WARNING", "CAUTION"}
+
+// markupRawBlocks 定义了内容本身就是 HTML 的块，以原始块透传时直接使用节点 tokens。
+var markupRawBlocks = map[ast.NodeType]bool{
+	ast.NodeHTMLBlock: true, ast.NodeIFrame: true, ast.NodeVideo: true, ast.NodeAudio: true, ast.NodeWidget: true,
+}
+
+// admonition 判断引述 blockquote 是否为提示块，即第一个段落以 GitHub 风格的 [!NOTE] 或者加粗的 **Note:** 开头。
+// 是提示块时返回大写的提示类型以及去掉提示标记后的引述副本，否则返回空字符串和 nil。
+func admonition(blockquote *ast.Node) (kind string, content *ast.Node) {
+	paragraph := blockquote.ChildByType(ast.NodeParagraph)
+	if nil == paragraph || paragraph != markupFirstBlock(blockquote) || nil == paragraph.FirstChild {
+		return "", nil
+	}
+
+	first := paragraph.FirstChild
+	var rest []byte // 提示标记之后同一个文本节点中的剩余内容
+	switch first.Type {
+	case ast.NodeText:
+		text := util.BytesToStr(first.Tokens)
+		if !strings.HasPrefix(text, "[!") || 0 > strings.Index(text, "]") {
+			return "", nil
+		}
+		kind = strings.ToUpper(text[2:strings.Index(text, "]")])
+		rest = first.Tokens[strings.Index(text, "]")+1:]
+	case ast.NodeStrong:
+		kind = strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(first.Text()), ":"))
+	default:
+		return "", nil
+	}
+	if !markupAdmonition(kind) {
+		return "", nil
+	}
+
+	content = blockquote.Clone(true)
+	paragraph = markupFirstBlock(content)
+	marker := paragraph.FirstChild
+	next := marker.Next
+	marker.Unlink()
+	if 0 < len(bytes.TrimSpace(rest)) {
+		paragraph.PrependChild(&ast.Node{Type: ast.NodeText, Tokens: bytes.TrimLeft(rest, " ")})
+	} else if nil != next {
+		switch next.Type {
+		case ast.NodeSoftBreak, ast.NodeHardBreak:
+			next.Unlink()
+		case ast.NodeText:
+			// **Note**: foo 中的冒号
+			next.Tokens = bytes.TrimLeft(next.Tokens, ": ")
+			if 1 > len(next.Tokens) {
+				next.Unlink()
+			}
+		}
+	}
+	if nil == paragraph.FirstChild {
+		paragraph.Unlink()
+	}
+	return
+}
+
+func markupAdmonition(kind string) bool {
+	for _, admonition := range markupAdmonitions {
+		if kind == admonition {
+			return true
+		}
+	}
+	return false
+}
+
+// markupFirstBlock 返回容器块 container 中第一个非标记符的子块。
+func markupFirstBlock(container *ast.Node) *ast.Node {
+	for c := container.FirstChild; nil != c; c = c.Next {
+		if !c.IsMarker() && ast.NodeBlockquoteMarker != c.Type {
+			return c
+		}
+	}
+	return nil
+}
+
+// crossDocLink 判断链接地址 dest 是否指向另一个 Markdown 文档，比如 guide/install.md#linux。
+// 是则返回去掉 .md 扩展名的文档路径和锚点。
+func crossDocLink(dest string) (path, anchor string, ok bool) {
+	if strings.Contains(dest, "://") || strings.HasPrefix(dest, "#") || strings.HasPrefix(dest, "mailto:") {
+		return
+	}
+	path = dest
+	if i := strings.Index(path, "#"); 0 <= i {
+		path, anchor = path[:i], path[i+1:]
+	}
+	lower := strings.ToLower(path)
+	for _, ext := range []string{".markdown", ".md"} {
+		if strings.HasSuffix(lower, ext) {
+			return path[:len(path)-len(ext)], anchor, "" != path[:len(path)-len(ext)]
+		}
+	}
+	return "", "", false
+}
+
+// markupRaw 返回不支持的块 node 以原始块透传时的内容：HTML 类的块直接使用 tokens，其他块使用格式化后的 Markdown。
+func markupRaw(tree *parse.Tree, node *ast.Node, options *Options) string {
+	if markupRawBlocks[node.Type] {
+		return strings.TrimSpace(util.BytesToStr(node.Tokens))
+	}
+	root := &ast.Node{Type: ast.NodeDocument}
+	root.AppendChild(node.Clone(true))
+	renderer := NewFormatRenderer(&parse.Tree{Root: root, Context: tree.Context}, options)
+	return strings.TrimSpace(util.BytesToStr(renderer.Render()))
+}
+
+// markupWordChar 判断 r 是否为字母或者数字，行级标记紧邻这些字符时需要使用非受限形式或者转义。
+func markupWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// markupPrevRune 返回行级节点 node 之前紧邻的最后一个字符，不是文本时返回 0。
+func markupPrevRune(node *ast.Node) (ret rune) {
+	if prev := node.Previous; nil != prev && ast.NodeText == prev.Type {
+		ret, _ = utf8.DecodeLastRune(prev.Tokens)
+	}
+	return
+}
+
+// markupNextRune 返回行级节点 node 之后紧接着输出的第一个字符，不是文本时返回 0。
+func markupNextRune(node *ast.Node) (ret rune) {
+	if next := node.Next; nil != next && ast.NodeText == next.Type {
+		ret, _ = utf8.DecodeRune(next.Tokens)
+	}
+	return
+}
+
+// markupIndent 为 text 的每个非空行添加前缀 prefix。
+func markupIndent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if "" != line {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markupNextBlock 返回块 node 之后第一个会输出内容的兄弟块，skipped 中的块不输出。
+func markupNextBlock(node *ast.Node, skipped map[ast.NodeType]bool) (ret *ast.Node) {
+	for ret = node.Next; nil != ret && skipped[ret.Type]; ret = ret.Next {
+	}
+	return
+}
+
+// markupRenderChildren 使用渲染器 r 渲染 node 的所有子节点，返回渲染结果，用于将脚注定义、标题等内容渲染到单独的缓冲中。
+func markupRenderChildren(r *BaseRenderer, node *ast.Node) string {
+	writer := r.Writer
+	r.Writer = &bytes.Buffer{}
+	for c := node.FirstChild; nil != c; c = c.Next {
+		ast.Walk(c, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if render := r.RendererFuncs[n.Type]; nil != render {
+				return render(n, entering)
+			}
+			return r.DefaultRendererFunc(n, entering)
+		})
+	}
+	ret := r.Writer.String()
+	r.Writer = writer
+	return ret
+}
+
+// markupInside 判断 node 是否位于类型为 typ 的节点中。
+func markupInside(node *ast.Node, typ ast.NodeType) bool {
+	for p := node.Parent; nil != p; p = p.Parent {
+		if typ == p.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// markupFence 返回由字符 c 组成的分隔线，长度至少为 4 并且比 content 中只由 c 组成的行都长。
+func markupFence(content string, c byte) string {
+	length := 4
+	for _, line := range strings.Split(content, "\n") {
+		if 0 < len(line) && len(line) >= length && strings.Count(line, string(c)) == len(line) {
+			length = len(line) + 1
+		}
+	}
+	return strings.Repeat(string(c), length)
+}
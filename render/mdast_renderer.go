@@ -0,0 +1,510 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/lex"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// MdastRenderer 描述了 mdast（https://github.com/syntax-tree/mdast）JSON 渲染器。
+type MdastRenderer struct {
+	*BaseRenderer
+	root    *ast.MdastNode     // mdast 根节点
+	stack   []*ast.MdastNode   // 正在渲染的 mdast 节点栈
+	customs map[*ast.Node]bool // 使用自定义类型渲染的 Lute 特有节点
+}
+
+// NewMdastRenderer 创建一个 mdast JSON 渲染器。
+func NewMdastRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &MdastRenderer{BaseRenderer: NewBaseRenderer(tree, options), customs: map[*ast.Node]bool{}}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderText
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderEmphasis
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderStrong
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderStrikethrough
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeHeadingID] = ret.renderHeadingID
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeHTMLBlock] = ret.renderHTML
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderHTML
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeTableHead] = ret.renderTableHead
+	ret.RendererFuncs[ast.NodeTableRow] = ret.renderTableRow
+	ret.RendererFuncs[ast.NodeTableCell] = ret.renderTableCell
+	ret.RendererFuncs[ast.NodeEmoji] = ret.renderEmoji
+	ret.RendererFuncs[ast.NodeBackslash] = ret.renderBackslash
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderHtmlEntity
+	ret.RendererFuncs[ast.NodeFootnotesDefBlock] = ret.renderFootnotesDefBlock
+	ret.RendererFuncs[ast.NodeFootnotesDef] = ret.renderFootnotesDef
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderLinkRefDefBlock
+	ret.RendererFuncs[ast.NodeLinkRefDef] = ret.renderLinkRefDef
+	ret.RendererFuncs[ast.NodeYamlFrontMatter] = ret.renderYamlFrontMatter
+	ret.RendererFuncs[ast.NodeKramdownBlockIAL] = ret.renderKramdownBlockIAL
+	for _, marker := range []ast.NodeType{ast.NodeHeadingC8hMarker, ast.NodeBlockquoteMarker, ast.NodeTaskListItemMarker,
+		ast.NodeEmA6kOpenMarker, ast.NodeEmA6kCloseMarker, ast.NodeEmU8eOpenMarker, ast.NodeEmU8eCloseMarker,
+		ast.NodeStrongA6kOpenMarker, ast.NodeStrongA6kCloseMarker, ast.NodeStrongU8eOpenMarker, ast.NodeStrongU8eCloseMarker,
+		ast.NodeStrikethrough1OpenMarker, ast.NodeStrikethrough1CloseMarker, ast.NodeStrikethrough2OpenMarker, ast.NodeStrikethrough2CloseMarker,
+		ast.NodeBang, ast.NodeOpenBracket, ast.NodeCloseBracket, ast.NodeOpenParen, ast.NodeCloseParen,
+		ast.NodeLinkDest, ast.NodeLinkSpace, ast.NodeLinkTitle} {
+		ret.RendererFuncs[marker] = ret.renderMarker
+	}
+	ret.DefaultRendererFunc = ret.renderLuteNode
+	return ret
+}
+
+// Render 渲染 mdast JSON。
+func (r *MdastRenderer) Render() (output []byte) {
+	r.BaseRenderer.Render()
+	output, _ = json.Marshal(r.root)
+	return
+}
+
+// renderLuteNode 使用 MdastLutePrefix 前缀的自定义类型渲染 Lute 特有节点。
+func (r *MdastRenderer) renderLuteNode(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, ast.MdastLutePrefix+strings.TrimPrefix(node.Type.String(), "Node"))
+		if 0 < len(node.Tokens) {
+			n.Value = mdastStr(util.BytesToStr(node.Tokens))
+		}
+		r.push(n)
+		r.customs[node] = true
+	} else {
+		r.pop()
+	}
+	return ast.WalkContinue
+}
+
+// renderKramdownBlockIAL 渲染块级 IAL 节点，块的 IAL 和文档 IAL 都已经记录在 data.kramdownIAL 中，不再输出单独的节点。
+func (r *MdastRenderer) renderKramdownBlockIAL(node *ast.Node, entering bool) ast.WalkStatus {
+	if ast.NodeDocument == node.Parent.Type && util.IsDocIAL(node.Tokens) {
+		if entering {
+			r.data(r.root, "kramdownIAL", parse.Tokens2IAL(node.Tokens))
+		}
+		return ast.WalkSkipChildren
+	}
+	if nil != node.Previous && 0 < len(node.Previous.KramdownIAL) {
+		return ast.WalkSkipChildren
+	}
+	return r.renderLuteNode(node, entering)
+}
+
+func (r *MdastRenderer) renderMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if r.customs[node.Parent] {
+		// 自定义类型节点下的标记符需要保留，否则无法还原
+		return r.renderLuteNode(node, entering)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.root = r.newNode(node, "root")
+		r.stack = []*ast.MdastNode{r.root}
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, "paragraph")
+}
+
+func (r *MdastRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		tokens := node.Tokens
+		if nil != node.Previous && ast.NodeTaskListItemMarker == node.Previous.Type {
+			// 任务列表项标记符之后的空格不属于文本内容
+			tokens = bytes.TrimPrefix(tokens, []byte{lex.ItemSpace})
+		}
+		r.appendText(util.BytesToStr(tokens))
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.appendText("\n")
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.append(r.newNode(node, "break"))
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderBackslash(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeBackslashContent); nil != content {
+			r.appendText(util.BytesToStr(content.Tokens))
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) renderHtmlEntity(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.appendText(util.BytesToStr(node.Tokens))
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderEmoji(node *ast.Node, entering bool) ast.WalkStatus {
+	if unicode := node.ChildByType(ast.NodeEmojiUnicode); nil != unicode {
+		if entering {
+			r.appendText(util.BytesToStr(unicode.Tokens))
+		}
+		return ast.WalkSkipChildren
+	}
+	return r.renderLuteNode(node, entering)
+}
+
+func (r *MdastRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "inlineCode")
+		n.Value = mdastStr(r.childText(node, ast.NodeCodeSpanContent))
+		r.append(n)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "code")
+		if info := strings.TrimSpace(util.BytesToStr(node.CodeBlockInfo)); "" != info {
+			lang, meta := info, ""
+			if idx := strings.IndexAny(info, " \t"); 0 < idx {
+				lang, meta = info[:idx], strings.TrimSpace(info[idx:])
+			}
+			n.Lang = mdastStr(lang)
+			if "" != meta {
+				n.Meta = mdastStr(meta)
+			}
+		}
+		n.Value = mdastStr(strings.TrimSuffix(r.childText(node, ast.NodeCodeBlockCode), "\n"))
+		r.append(n)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "math")
+		n.Value = mdastStr(r.childText(node, ast.NodeMathBlockContent))
+		r.append(n)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "inlineMath")
+		n.Value = mdastStr(r.childText(node, ast.NodeInlineMathContent))
+		r.append(n)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) renderYamlFrontMatter(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "yaml")
+		n.Value = mdastStr(r.childText(node, ast.NodeYamlFrontMatterContent))
+		r.append(n)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) renderEmphasis(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, "emphasis")
+}
+
+func (r *MdastRenderer) renderStrong(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, "strong")
+}
+
+func (r *MdastRenderer) renderStrikethrough(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, "delete")
+}
+
+func (r *MdastRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, "blockquote")
+}
+
+func (r *MdastRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "heading")
+		n.Depth = node.HeadingLevel
+		r.push(n)
+	} else {
+		r.pop()
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderHeadingID(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.data(r.top(), "id", util.BytesToStr(node.Tokens))
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "list")
+		ordered := 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar)
+		n.Ordered = &ordered
+		if ordered {
+			start := node.ListData.Start
+			n.Start = &start
+		}
+		n.Spread = mdastBool(!node.ListData.Tight)
+		r.push(n)
+	} else {
+		r.pop()
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "listItem")
+		n.Spread = mdastBool(!node.ListData.Tight)
+		if 3 == node.ListData.Typ {
+			checked := node.ListData.Checked
+			if marker := node.FirstChild; nil != marker && nil != marker.FirstChild && ast.NodeTaskListItemMarker == marker.FirstChild.Type {
+				checked = marker.FirstChild.TaskListItemChecked
+			}
+			n.Checked = &checked
+		}
+		r.push(n)
+	} else {
+		r.pop()
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.append(r.newNode(node, "thematicBreak"))
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "html")
+		n.Value = mdastStr(util.BytesToStr(node.Tokens))
+		r.append(n)
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "link")
+		n.URL = mdastStr(r.childText(node, ast.NodeLinkDest))
+		if title := node.ChildByType(ast.NodeLinkTitle); nil != title {
+			n.Title = mdastStr(util.BytesToStr(title.Tokens))
+		}
+		r.push(n)
+	} else {
+		r.pop()
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "image")
+		n.URL = mdastStr(r.childText(node, ast.NodeLinkDest))
+		if title := node.ChildByType(ast.NodeLinkTitle); nil != title {
+			n.Title = mdastStr(util.BytesToStr(title.Tokens))
+		}
+		n.Alt = mdastStr(node.Text())
+		r.append(n)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "table")
+		n.Align = []*string{}
+		for _, align := range node.TableAligns {
+			var a *string
+			switch align {
+			case 1:
+				a = mdastStr("left")
+			case 2:
+				a = mdastStr("center")
+			case 3:
+				a = mdastStr("right")
+			}
+			n.Align = append(n.Align, a)
+		}
+		r.push(n)
+	} else {
+		r.pop()
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderTableHead(node *ast.Node, entering bool) ast.WalkStatus {
+	// mdast 中表头行是表的第一行，不需要单独的节点
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, "tableRow")
+}
+
+func (r *MdastRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, "tableCell")
+}
+
+func (r *MdastRenderer) renderFootnotesDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	// mdast 中脚注定义直接作为根节点的子节点
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderFootnotesDef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "footnoteDefinition")
+		n.Label = strings.TrimPrefix(util.BytesToStr(node.Tokens), "^")
+		n.Identifier = strings.ToLower(n.Label)
+		r.push(n)
+	} else {
+		r.pop()
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "footnoteReference")
+		n.Label = strings.TrimPrefix(util.BytesToStr(node.FootnotesRefLabel), "^")
+		n.Identifier = strings.ToLower(n.Label)
+		r.append(n)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) renderLinkRefDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) renderLinkRefDef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		n := r.newNode(node, "definition")
+		n.Label = util.BytesToStr(node.Tokens)
+		n.Identifier = strings.ToLower(n.Label)
+		if link := node.ChildByType(ast.NodeLink); nil != link {
+			n.URL = mdastStr(r.childText(link, ast.NodeLinkDest))
+			if title := link.ChildByType(ast.NodeLinkTitle); nil != title {
+				n.Title = mdastStr(util.BytesToStr(title.Tokens))
+			}
+		}
+		r.append(n)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *MdastRenderer) container(node *ast.Node, entering bool, typ string) ast.WalkStatus {
+	if entering {
+		r.push(r.newNode(node, typ))
+	} else {
+		r.pop()
+	}
+	return ast.WalkContinue
+}
+
+func (r *MdastRenderer) newNode(node *ast.Node, typ string) (ret *ast.MdastNode) {
+	ret = &ast.MdastNode{Type: typ}
+	if 0 < len(node.KramdownIAL) {
+		r.data(ret, "kramdownIAL", node.KramdownIAL)
+	}
+	return
+}
+
+func (r *MdastRenderer) data(n *ast.MdastNode, key string, value interface{}) {
+	if nil == n.Data {
+		n.Data = map[string]interface{}{}
+	}
+	n.Data[key] = value
+}
+
+func (r *MdastRenderer) childText(node *ast.Node, childType ast.NodeType) string {
+	if child := node.ChildByType(childType); nil != child {
+		return util.BytesToStr(child.Tokens)
+	}
+	return ""
+}
+
+func (r *MdastRenderer) top() *ast.MdastNode {
+	return r.stack[len(r.stack)-1]
+}
+
+func (r *MdastRenderer) append(n *ast.MdastNode) {
+	top := r.top()
+	top.Children = append(top.Children, n)
+}
+
+// appendText 添加文本节点，相邻的文本节点会被合并。
+func (r *MdastRenderer) appendText(text string) {
+	top := r.top()
+	if length := len(top.Children); 0 < length {
+		if last := top.Children[length-1]; "text" == last.Type && nil == last.Data {
+			*last.Value += text
+			return
+		}
+	}
+	n := &ast.MdastNode{Type: "text", Value: mdastStr(text)}
+	top.Children = append(top.Children, n)
+}
+
+func (r *MdastRenderer) push(n *ast.MdastNode) {
+	r.append(n)
+	r.stack = append(r.stack, n)
+}
+
+func (r *MdastRenderer) pop() {
+	r.stack = r.stack[:len(r.stack)-1]
+}
+
+func mdastStr(s string) *string {
+	return &s
+}
+
+func mdastBool(b bool) *bool {
+	return &b
+}
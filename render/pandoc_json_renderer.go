@@ -0,0 +1,593 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// PandocJSONRenderer 描述了 Pandoc JSON AST（pandoc -t json）渲染器。
+type PandocJSONRenderer struct {
+	*BaseRenderer
+	frames    []*pandocFrame     // 正在渲染的节点的子元素栈
+	customs   map[*ast.Node]bool // 使用 Div/Span 渲染的 Lute 特有节点
+	footnotes map[*ast.Node]bool // 正在渲染的脚注定义，用于避免脚注循环引用
+}
+
+// pandocElement 描述了 Pandoc JSON AST 中的块级或者行级元素。
+type pandocElement struct {
+	T string      `json:"t"`
+	C interface{} `json:"c,omitempty"`
+}
+
+// pandocFrame 用于收集一个节点渲染得到的子元素。
+type pandocFrame struct {
+	elements []interface{}
+}
+
+// pandocTableHead 用于在渲染表时区分表头行。
+type pandocTableHead struct {
+	rows []interface{}
+}
+
+// NewPandocJSONRenderer 创建一个 Pandoc JSON AST 渲染器。
+func NewPandocJSONRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &PandocJSONRenderer{BaseRenderer: NewBaseRenderer(tree, options), customs: map[*ast.Node]bool{}, footnotes: map[*ast.Node]bool{}}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderText
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderInlineMath
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderEmphasis
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderStrong
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderStrikethrough
+	ret.RendererFuncs[ast.NodeSup] = ret.renderSup
+	ret.RendererFuncs[ast.NodeSub] = ret.renderSub
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeTaskListItemMarker] = ret.renderTaskListItemMarker
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderHardBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeHTMLBlock] = ret.renderHTMLBlock
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderInlineHTML
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeTableHead] = ret.renderTableHead
+	ret.RendererFuncs[ast.NodeTableRow] = ret.renderTableRow
+	ret.RendererFuncs[ast.NodeTableCell] = ret.renderTableCell
+	ret.RendererFuncs[ast.NodeEmoji] = ret.renderEmoji
+	ret.RendererFuncs[ast.NodeBackslash] = ret.renderBackslash
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderHtmlEntity
+	ret.RendererFuncs[ast.NodeFootnotesDefBlock] = ret.renderFootnotesDefBlock
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeLinkRefDefBlock] = ret.renderLinkRefDefBlock
+	for _, marker := range []ast.NodeType{ast.NodeHeadingC8hMarker, ast.NodeHeadingID, ast.NodeBlockquoteMarker,
+		ast.NodeEmA6kOpenMarker, ast.NodeEmA6kCloseMarker, ast.NodeEmU8eOpenMarker, ast.NodeEmU8eCloseMarker,
+		ast.NodeStrongA6kOpenMarker, ast.NodeStrongA6kCloseMarker, ast.NodeStrongU8eOpenMarker, ast.NodeStrongU8eCloseMarker,
+		ast.NodeStrikethrough1OpenMarker, ast.NodeStrikethrough1CloseMarker, ast.NodeStrikethrough2OpenMarker, ast.NodeStrikethrough2CloseMarker,
+		ast.NodeSupOpenMarker, ast.NodeSupCloseMarker, ast.NodeSubOpenMarker, ast.NodeSubCloseMarker,
+		ast.NodeBang, ast.NodeOpenBracket, ast.NodeCloseBracket, ast.NodeOpenParen, ast.NodeCloseParen,
+		ast.NodeLinkDest, ast.NodeLinkSpace, ast.NodeLinkTitle} {
+		ret.RendererFuncs[marker] = ret.renderMarker
+	}
+	ret.DefaultRendererFunc = ret.renderLuteNode
+	return ret
+}
+
+// Render 渲染 Pandoc JSON AST。
+func (r *PandocJSONRenderer) Render() (output []byte) {
+	r.BaseRenderer.Render()
+	var blocks []interface{}
+	if 0 < len(r.frames) {
+		blocks = r.blocks(r.frames[0].elements)
+	}
+	output, _ = json.Marshal(map[string]interface{}{
+		"pandoc-api-version": ast.PandocAPIVersion,
+		"meta":               map[string]interface{}{},
+		"blocks":             blocks,
+	})
+	return
+}
+
+// renderLuteNode 使用 Div（块级节点）或者 Span（行级节点）渲染 Lute 特有节点。
+func (r *PandocJSONRenderer) renderLuteNode(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.customs[node] = true
+		r.push()
+		return ast.WalkContinue
+	}
+
+	kvs := [][]string{{ast.PandocLuteTypeKey, strings.TrimPrefix(node.Type.String(), "Node")}}
+	if 0 < len(node.Tokens) {
+		kvs = append(kvs, []string{ast.PandocLuteTokensKey, util.BytesToStr(node.Tokens)})
+	}
+	attr := pandocAttr(node.ID, []string{ast.PandocLuteClass}, kvs)
+	children := r.pop()
+	if node.IsBlock() {
+		r.emit(node, &pandocElement{"Div", []interface{}{attr, r.blocks(children)}})
+	} else {
+		r.emit(node, &pandocElement{"Span", []interface{}{attr, r.inlines(children)}})
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if r.customs[node.Parent] {
+		// Lute 特有节点下的标记符需要保留，否则无法还原
+		return r.renderLuteNode(node, entering)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.frames = nil
+		r.push()
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		typ := "Para"
+		if parent := node.Parent; nil != parent && ast.NodeListItem == parent.Type && parent.ListData.Tight {
+			typ = "Plain"
+		}
+		return &pandocElement{typ, r.inlines(children)}
+	})
+}
+
+func (r *PandocJSONRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.appendText(util.BytesToStr(node.Tokens))
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.append(&pandocElement{T: "SoftBreak"})
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderHardBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.append(&pandocElement{T: "LineBreak"})
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderBackslash(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeBackslashContent); nil != content {
+			r.appendText(util.BytesToStr(content.Tokens))
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) renderHtmlEntity(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.appendText(util.BytesToStr(node.Tokens))
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderEmoji(node *ast.Node, entering bool) ast.WalkStatus {
+	if unicode := node.ChildByType(ast.NodeEmojiUnicode); nil != unicode {
+		if entering {
+			r.appendText(util.BytesToStr(unicode.Tokens))
+		}
+		return ast.WalkSkipChildren
+	}
+	return r.renderLuteNode(node, entering)
+}
+
+func (r *PandocJSONRenderer) renderTaskListItemMarker(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		// 和 Pandoc 的 task_lists 扩展一致，使用 ☒ 和 ☐ 表示任务列表项是否勾选
+		if node.TaskListItemChecked {
+			r.appendText("☒")
+		} else {
+			r.appendText("☐")
+		}
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.append(&pandocElement{"Code", []interface{}{pandocAttr("", nil, nil), r.childText(node, ast.NodeCodeSpanContent)}})
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		classes := strings.Fields(util.BytesToStr(node.CodeBlockInfo))
+		code := strings.TrimSuffix(r.childText(node, ast.NodeCodeBlockCode), "\n")
+		r.emit(node, &pandocElement{"CodeBlock", []interface{}{pandocAttr("", classes, nil), code}})
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		// Pandoc 中的块级公式是只包含 DisplayMath 的段落
+		math := &pandocElement{"Math", []interface{}{&pandocElement{T: "DisplayMath"}, r.childText(node, ast.NodeMathBlockContent)}}
+		r.emit(node, &pandocElement{"Para", []interface{}{math}})
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) renderInlineMath(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.append(&pandocElement{"Math", []interface{}{&pandocElement{T: "InlineMath"}, r.childText(node, ast.NodeInlineMathContent)}})
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) renderEmphasis(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.inlineContainer(node, entering, "Emph")
+}
+
+func (r *PandocJSONRenderer) renderStrong(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.inlineContainer(node, entering, "Strong")
+}
+
+func (r *PandocJSONRenderer) renderStrikethrough(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.inlineContainer(node, entering, "Strikeout")
+}
+
+func (r *PandocJSONRenderer) renderSup(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.inlineContainer(node, entering, "Superscript")
+}
+
+func (r *PandocJSONRenderer) renderSub(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.inlineContainer(node, entering, "Subscript")
+}
+
+func (r *PandocJSONRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		return &pandocElement{"BlockQuote", r.blocks(children)}
+	})
+}
+
+func (r *PandocJSONRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		var id string
+		if headingID := node.ChildByType(ast.NodeHeadingID); nil != headingID {
+			id = strings.TrimPrefix(util.BytesToStr(headingID.Tokens), "#")
+		}
+		return &pandocElement{"Header", []interface{}{node.HeadingLevel, pandocAttr(id, nil, nil), r.inlines(children)}}
+	})
+}
+
+func (r *PandocJSONRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		var items []interface{}
+		for _, child := range children {
+			if item, ok := child.([]interface{}); ok {
+				items = append(items, item)
+				continue
+			}
+			// 列表下的 Lute 特有节点（比如列表项的块级内联属性列表）放到前一个列表项末尾
+			if element, ok := child.(*pandocElement); ok && "Div" == element.T {
+				attr := element.C.([]interface{})[0].([]interface{})
+				attr[1] = append(attr[1].([]string), ast.PandocLuteListClass)
+			}
+			if last := len(items) - 1; 0 <= last {
+				items[last] = append(items[last].([]interface{}), child)
+			} else {
+				items = append(items, []interface{}{child})
+			}
+		}
+		if nil == items {
+			items = []interface{}{}
+		}
+
+		if 0 != node.ListData.BulletChar {
+			return &pandocElement{"BulletList", items}
+		}
+
+		delim := "Period"
+		if ')' == node.ListData.Delimiter {
+			delim = "OneParen"
+		}
+		start := node.ListData.Start
+		if 1 > start {
+			start = 1
+		}
+		listAttrs := []interface{}{start, &pandocElement{T: "Decimal"}, &pandocElement{T: delim}}
+		return &pandocElement{"OrderedList", []interface{}{listAttrs, items}}
+	})
+}
+
+func (r *PandocJSONRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		// 列表项在 Pandoc 中是块级元素数组
+		return r.blocks(children)
+	})
+}
+
+func (r *PandocJSONRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.emit(node, &pandocElement{T: "HorizontalRule"})
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderHTMLBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.emit(node, &pandocElement{"RawBlock", []interface{}{"html", util.BytesToStr(node.Tokens)}})
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.append(&pandocElement{"RawInline", []interface{}{"html", util.BytesToStr(node.Tokens)}})
+	}
+	return ast.WalkContinue
+}
+
+func (r *PandocJSONRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		target := []interface{}{r.childText(node, ast.NodeLinkDest), r.childText(node, ast.NodeLinkTitle)}
+		return &pandocElement{"Link", []interface{}{pandocAttr("", nil, nil), r.inlines(children), target}}
+	})
+}
+
+func (r *PandocJSONRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.push()
+		r.appendText(node.Text())
+		alt := r.pop()
+		target := []interface{}{r.childText(node, ast.NodeLinkDest), r.childText(node, ast.NodeLinkTitle)}
+		r.append(&pandocElement{"Image", []interface{}{pandocAttr("", nil, nil), r.inlines(alt), target}})
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		var colSpecs []interface{}
+		for _, align := range node.TableAligns {
+			colSpecs = append(colSpecs, []interface{}{&pandocElement{T: pandocAlignment(align)}, &pandocElement{T: "ColWidthDefault"}})
+		}
+
+		headRows, bodyRows := []interface{}{}, []interface{}{}
+		for _, child := range children {
+			if head, ok := child.(*pandocTableHead); ok {
+				headRows = append(headRows, head.rows...)
+				continue
+			}
+			bodyRows = append(bodyRows, child)
+		}
+		emptyAttr := pandocAttr("", nil, nil)
+		caption := []interface{}{nil, []interface{}{}}
+		head := []interface{}{emptyAttr, headRows}
+		bodies := []interface{}{[]interface{}{emptyAttr, 0, []interface{}{}, bodyRows}}
+		foot := []interface{}{emptyAttr, []interface{}{}}
+		return &pandocElement{"Table", []interface{}{emptyAttr, caption, colSpecs, head, bodies, foot}}
+	})
+}
+
+func (r *PandocJSONRenderer) renderTableHead(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		return &pandocTableHead{rows: children}
+	})
+}
+
+func (r *PandocJSONRenderer) renderTableRow(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		return []interface{}{pandocAttr("", nil, nil), children}
+	})
+}
+
+func (r *PandocJSONRenderer) renderTableCell(node *ast.Node, entering bool) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		var blocks []interface{}
+		if inlines := r.inlines(children); 0 < len(inlines) {
+			blocks = append(blocks, &pandocElement{"Plain", inlines})
+		}
+		if nil == blocks {
+			blocks = []interface{}{}
+		}
+		return []interface{}{pandocAttr("", nil, nil), &pandocElement{T: pandocAlignment(node.TableCellAlign)}, 1, 1, blocks}
+	})
+}
+
+func (r *PandocJSONRenderer) renderFootnotesDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	// 脚注定义在脚注引用处作为 Note 渲染
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkContinue
+	}
+
+	_, def := r.Tree.FindFootnotesDef(node.Tokens)
+	if nil == def || r.footnotes[def] {
+		r.appendText("[" + util.BytesToStr(node.Tokens) + "]")
+		return ast.WalkSkipChildren
+	}
+
+	r.footnotes[def] = true
+	r.push()
+	for c := def.FirstChild; nil != c; c = c.Next {
+		ast.Walk(c, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if render := r.RendererFuncs[n.Type]; nil != render {
+				return render(n, entering)
+			}
+			return r.DefaultRendererFunc(n, entering)
+		})
+	}
+	blocks := r.blocks(r.pop())
+	delete(r.footnotes, def)
+	r.append(&pandocElement{"Note", blocks})
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) renderLinkRefDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	// 链接引用在链接处已经解析，不需要输出链接引用定义
+	return ast.WalkSkipChildren
+}
+
+func (r *PandocJSONRenderer) inlineContainer(node *ast.Node, entering bool, typ string) ast.WalkStatus {
+	return r.container(node, entering, func(children []interface{}) interface{} {
+		return &pandocElement{typ, r.inlines(children)}
+	})
+}
+
+// container 在进入节点时开始收集子元素，在离开节点时使用 build 构造元素。
+func (r *PandocJSONRenderer) container(node *ast.Node, entering bool, build func(children []interface{}) interface{}) ast.WalkStatus {
+	if entering {
+		r.push()
+	} else {
+		r.emit(node, build(r.pop()))
+	}
+	return ast.WalkContinue
+}
+
+// emit 添加节点 node 渲染得到的元素，带有块级内联属性列表的块级节点使用 Div 包裹。
+func (r *PandocJSONRenderer) emit(node *ast.Node, element interface{}) {
+	if 0 < len(node.KramdownIAL) && node.IsBlock() && ast.NodeListItem != node.Type {
+		attr := pandocAttr("", []string{ast.PandocLuteIALClass}, node.KramdownIAL)
+		element = &pandocElement{"Div", []interface{}{attr, []interface{}{element}}}
+	}
+	r.append(element)
+}
+
+func (r *PandocJSONRenderer) push() {
+	r.frames = append(r.frames, &pandocFrame{})
+}
+
+func (r *PandocJSONRenderer) pop() (elements []interface{}) {
+	last := len(r.frames) - 1
+	elements = r.frames[last].elements
+	r.frames = r.frames[:last]
+	return
+}
+
+func (r *PandocJSONRenderer) append(element interface{}) {
+	frame := r.frames[len(r.frames)-1]
+	frame.elements = append(frame.elements, element)
+}
+
+// appendText 添加文本，文本中的空白转换为 Space，换行转换为 SoftBreak，相邻的 Str 会被合并。
+func (r *PandocJSONRenderer) appendText(text string) {
+	frame := r.frames[len(r.frames)-1]
+	for i, line := range strings.Split(text, "\n") {
+		if 0 < i {
+			frame.elements = append(frame.elements, &pandocElement{T: "SoftBreak"})
+		}
+		for j, word := range strings.Split(line, " ") {
+			if 0 < j {
+				if length := len(frame.elements); 0 < length {
+					if last, ok := frame.elements[length-1].(*pandocElement); ok && "Space" == last.T {
+						continue
+					}
+				}
+				frame.elements = append(frame.elements, &pandocElement{T: "Space"})
+			}
+			if "" == word {
+				continue
+			}
+			if length := len(frame.elements); 0 < length {
+				if last, ok := frame.elements[length-1].(*pandocElement); ok && "Str" == last.T {
+					last.C = last.C.(string) + word
+					continue
+				}
+			}
+			frame.elements = append(frame.elements, &pandocElement{"Str", word})
+		}
+	}
+}
+
+// blocks 将 elements 转换为块级元素数组，连续的行级元素使用 Plain 包裹。
+func (r *PandocJSONRenderer) blocks(elements []interface{}) (ret []interface{}) {
+	ret = []interface{}{}
+	var inlines []interface{}
+	for _, element := range elements {
+		if e, ok := element.(*pandocElement); ok && pandocInlines[e.T] {
+			inlines = append(inlines, e)
+			continue
+		}
+		if 0 < len(inlines) {
+			ret = append(ret, &pandocElement{"Plain", inlines})
+			inlines = nil
+		}
+		ret = append(ret, element)
+	}
+	if 0 < len(inlines) {
+		ret = append(ret, &pandocElement{"Plain", inlines})
+	}
+	return
+}
+
+// inlines 将 elements 转换为行级元素数组。
+func (r *PandocJSONRenderer) inlines(elements []interface{}) []interface{} {
+	if nil == elements {
+		return []interface{}{}
+	}
+	return elements
+}
+
+func (r *PandocJSONRenderer) childText(node *ast.Node, childType ast.NodeType) string {
+	if child := node.ChildByType(childType); nil != child {
+		return util.BytesToStr(child.Tokens)
+	}
+	return ""
+}
+
+// pandocInlines 包含了所有 Pandoc 行级元素类型。
+var pandocInlines = map[string]bool{
+	"Str": true, "Emph": true, "Underline": true, "Strong": true, "Strikeout": true, "Superscript": true, "Subscript": true,
+	"SmallCaps": true, "Quoted": true, "Cite": true, "Code": true, "Space": true, "SoftBreak": true, "LineBreak": true,
+	"Math": true, "RawInline": true, "Link": true, "Image": true, "Note": true, "Span": true,
+}
+
+func pandocAttr(id string, classes []string, kvs [][]string) []interface{} {
+	if nil == classes {
+		classes = []string{}
+	}
+	if nil == kvs {
+		kvs = [][]string{}
+	}
+	return []interface{}{id, classes, kvs}
+}
+
+func pandocAlignment(align int) string {
+	switch align {
+	case 1:
+		return "AlignLeft"
+	case 2:
+		return "AlignCenter"
+	case 3:
+		return "AlignRight"
+	}
+	return "AlignDefault"
+}
@@ -1339,7 +1339,7 @@ func (r *ProtylePreviewRenderer) renderSoftBreak(node *ast.Node, entering bool)
 		if r.Options.SoftBreak2HardBreak {
 			r.Tag("br", nil, true)
 			r.Newline()
-		} else {
+		} else if !r.Options.JoinCJKSoftBreak || !node.IsCJKSoftBreak() { // 中日韩字符之间的软换行直接去掉，避免浏览器显示多余的空格
 			r.Newline()
 		}
 	}
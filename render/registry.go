@@ -0,0 +1,98 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+// RendererFactory 描述了渲染器工厂函数，用于创建渲染语法树 tree 的渲染器。
+type RendererFactory func(tree *parse.Tree, options *Options) Renderer
+
+// ExtRenderer 描述了支持用户自定义渲染器函数的渲染器，内嵌 *BaseRenderer 的渲染器都实现了该接口。
+type ExtRenderer interface {
+	Renderer
+
+	// SetExtRendererFuncs 设置用户自定义的渲染器函数。
+	SetExtRendererFuncs(funcs map[ast.NodeType]ExtRendererFunc)
+}
+
+var (
+	factories     = map[string]RendererFactory{}
+	factoriesLock = sync.RWMutex{}
+)
+
+// Register 将渲染器工厂 factory 注册为输出格式 name，注册后可以通过 lute.Render 按照格式名渲染。
+//
+// 格式名建议使用小写字母和连字符，比如 latex、confluence-wiki。重复注册同一个格式名时后注册的工厂会覆盖之前的工厂，factory 为 nil 时取消注册。
+func Register(name string, factory RendererFactory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+
+	if nil == factory {
+		delete(factories, name)
+		return
+	}
+	factories[name] = factory
+}
+
+// Lookup 返回输出格式 name 的渲染器工厂，格式未注册时返回 nil。
+func Lookup(name string) RendererFactory {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+	return factories[name]
+}
+
+// Formats 返回所有已注册的输出格式名，按照字母顺序排列。
+func Formats() (ret []string) {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+
+	for name := range factories {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return
+}
+
+// SetExtRendererFuncs 设置用户自定义的渲染器函数，仅对使用 BaseRenderer.Render 进行渲染的渲染器生效。
+func (r *BaseRenderer) SetExtRendererFuncs(funcs map[ast.NodeType]ExtRendererFunc) {
+	for nodeType, rendererFunc := range funcs {
+		r.ExtRendererFuncs[nodeType] = rendererFunc
+	}
+}
+
+func init() {
+	Register("html", func(tree *parse.Tree, options *Options) Renderer { return NewHtmlRenderer(tree, options) })
+	Register("markdown", func(tree *parse.Tree, options *Options) Renderer { return NewFormatRenderer(tree, options) })
+	Register("text", NewTextRenderer)
+	Register("ansi", NewANSIRenderer)
+	Register("latex", NewLaTeXRenderer)
+	Register("asciidoc", NewAsciiDocRenderer)
+	Register("rst", NewRSTRenderer)
+	Register("man", NewManRenderer)
+	Register("confluence", NewConfluenceRenderer)
+	Register("jira", NewJiraRenderer)
+	Register("json", NewJSONRenderer)
+	Register("mdast", NewMdastRenderer)
+	Register("pandoc-json", NewPandocJSONRenderer)
+	Register("echarts-json", NewEChartsJSONRenderer)
+	Register("kityminder-json", NewKityMinderJSONRenderer)
+	Register("vditor-wysiwyg", func(tree *parse.Tree, options *Options) Renderer { return NewVditorRenderer(tree, options) })
+	Register("vditor-ir", func(tree *parse.Tree, options *Options) Renderer { return NewVditorIRRenderer(tree, options) })
+	Register("vditor-sv", func(tree *parse.Tree, options *Options) Renderer { return NewVditorSVRenderer(tree, options) })
+	Register("protyle", func(tree *parse.Tree, options *Options) Renderer { return NewBlockRenderer(tree, options) })
+	Register("protyle-preview", func(tree *parse.Tree, options *Options) Renderer { return NewProtylePreviewRenderer(tree, options) })
+}
@@ -94,6 +94,10 @@ type Options struct {
 	HeadingAnchor bool
 	// GFMTaskListItemClass 作为 GFM 任务列表项类名，默认为 "vditor-task"。
 	GFMTaskListItemClass string
+	// JoinCJKSoftBreak 设置是否去掉两个中日韩字符之间的软换行，西文单词之间的软换行仍然保留为空白。
+	JoinCJKSoftBreak bool
+	// GFMTagFilter 设置是否启用 GFM 禁用 HTML 标签过滤，比如 <script>、<style> 等标签的 < 会被转义。
+	GFMTagFilter bool
 	// VditorCodeBlockPreview 设置 Vditor 代码块是否需要渲染预览部分
 	VditorCodeBlockPreview bool
 	// VditorMathBlockPreview 设置 Vditor 数学公式块是否需要渲染预览部分
@@ -115,6 +119,28 @@ type Options struct {
 	KeepParagraphBeginningSpace bool
 	// NetImgMarker 设置 Protyle 是否标记网络图片
 	ProtyleMarkNetImg bool
+	// TextWrapWidth 设置纯文本渲染时自动换行的宽度（按照东亚宽度计算的列数），0 表示不自动换行
+	TextWrapWidth int
+	// ANSITrueColor 设置终端渲染时代码块语法高亮是否使用 24 位真彩色，默认使用 256 色
+	ANSITrueColor bool
+	// LaTeXDocumentClass 设置 LaTeX 渲染时使用的文档类，默认为 "article"
+	LaTeXDocumentClass string
+	// LaTeXDocumentClassOptions 设置 LaTeX 文档类选项，比如 "a4paper,12pt"
+	LaTeXDocumentClassOptions string
+	// LaTeXPreamble 设置 LaTeX 渲染时追加到导言区的内容，比如字体设置、自定义宏包
+	LaTeXPreamble string
+	// LaTeXXeCJK 设置 LaTeX 渲染时是否使用 xeCJK 支持中日韩文字，需要使用 XeLaTeX 编译
+	LaTeXXeCJK bool
+	// LaTeXMinted 设置 LaTeX 渲染代码块时是否使用 minted，默认使用 listings
+	LaTeXMinted bool
+	// LaTeXLongTable 设置 LaTeX 渲染表格时是否使用可以跨页的 longtable，默认使用 tabularx
+	LaTeXLongTable bool
+	// DocxResourceDir 设置 DOCX 渲染时解析本地图片相对路径的目录，为空时使用当前工作目录
+	DocxResourceDir string
+	// EPUBSplitHeading 设置 EPUB 渲染时是否在一级标题处拆分章节，默认每个文档一个章节
+	EPUBSplitHeading bool
+	// EPUBCSS 设置 EPUB 渲染时追加到默认样式后的 CSS
+	EPUBCSS string
 }
 
 func NewOptions() *Options {
@@ -145,6 +171,7 @@ func NewOptions() *Options {
 		NodeIndexStart:                 1,
 		ProtyleContenteditable:         true,
 		ProtyleMarkNetImg:              true,
+		LaTeXDocumentClass:             "article",
 	}
 }
 
@@ -0,0 +1,548 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// RSTRenderer 描述了 reStructuredText 渲染器，用于将 Markdown 文档迁移到 Sphinx 等基于 reStructuredText 的文档站点。
+//
+// 以 [!NOTE] 或者 **Note:** 开头的引述渲染为提示指令；指向其他 Markdown 文档的链接渲染为 :doc: 角色；
+// 表格渲染为 list-table 指令；不支持的块渲染为原始透传块（.. raw:: html），reStructuredText 中没有对应标记的行级节点渲染为纯文本，
+// 这些降级处理都会记录到 Warnings 中。
+type RSTRenderer struct {
+	*BaseRenderer
+	Warnings []string        // 渲染过程中产生的警告，比如不支持的节点
+	writers  []*bytes.Buffer // 节点输出缓冲栈，用于缩进列表项、引述等容器块的内容
+	images   []string        // 行内图片的替换定义
+	rawHTML  bool            // 是否使用了 raw-html 角色
+}
+
+// rstSkippedBlocks 定义了不输出的块。
+var rstSkippedBlocks = map[ast.NodeType]bool{
+	ast.NodeLinkRefDefBlock: true, ast.NodeKramdownBlockIAL: true,
+}
+
+// rstHeadingAdornments 定义了各级标题的装饰字符，一级标题同时使用上划线。
+var rstHeadingAdornments = []string{"=", "=", "-", "~", "^", "\""}
+
+// NewRSTRenderer 创建一个 reStructuredText 渲染器。
+func NewRSTRenderer(tree *parse.Tree, options *Options) Renderer {
+	ret := &RSTRenderer{BaseRenderer: NewBaseRenderer(tree, options)}
+	ret.RendererFuncs[ast.NodeDocument] = ret.renderDocument
+	ret.RendererFuncs[ast.NodeParagraph] = ret.renderParagraph
+	ret.RendererFuncs[ast.NodeText] = ret.renderText
+	ret.RendererFuncs[ast.NodeLinkText] = ret.renderText
+	ret.RendererFuncs[ast.NodeFileAnnotationRefText] = ret.renderText
+	ret.RendererFuncs[ast.NodeBackslashContent] = ret.renderText
+	ret.RendererFuncs[ast.NodeHTMLEntity] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiAlias] = ret.renderText
+	ret.RendererFuncs[ast.NodeEmojiUnicode] = ret.renderEmojiUnicode
+	ret.RendererFuncs[ast.NodeCodeSpan] = ret.renderCodeSpan
+	ret.RendererFuncs[ast.NodeInlineMath] = ret.renderRole("math")
+	ret.RendererFuncs[ast.NodeSup] = ret.renderRole("sup")
+	ret.RendererFuncs[ast.NodeSub] = ret.renderRole("sub")
+	ret.RendererFuncs[ast.NodeKbd] = ret.renderRole("kbd")
+	ret.RendererFuncs[ast.NodeEmphasis] = ret.renderInlineMarkup("*")
+	ret.RendererFuncs[ast.NodeStrong] = ret.renderInlineMarkup("**")
+	ret.RendererFuncs[ast.NodeStrikethrough] = ret.renderPlain
+	ret.RendererFuncs[ast.NodeMark] = ret.renderPlain
+	ret.RendererFuncs[ast.NodeUnderline] = ret.renderPlain
+	ret.RendererFuncs[ast.NodeHardBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeBr] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeSoftBreak] = ret.renderSoftBreak
+	ret.RendererFuncs[ast.NodeLink] = ret.renderLink
+	ret.RendererFuncs[ast.NodeImage] = ret.renderImage
+	ret.RendererFuncs[ast.NodeBlockRef] = ret.renderBlockRef
+	ret.RendererFuncs[ast.NodeFootnotesRef] = ret.renderFootnotesRef
+	ret.RendererFuncs[ast.NodeInlineHTML] = ret.renderInlineHTML
+	ret.RendererFuncs[ast.NodeKramdownSpanIAL] = ret.renderSkip
+	ret.RendererFuncs[ast.NodeHeading] = ret.renderHeading
+	ret.RendererFuncs[ast.NodeThematicBreak] = ret.renderThematicBreak
+	ret.RendererFuncs[ast.NodeBlockquote] = ret.renderBlockquote
+	ret.RendererFuncs[ast.NodeList] = ret.renderList
+	ret.RendererFuncs[ast.NodeListItem] = ret.renderListItem
+	ret.RendererFuncs[ast.NodeCodeBlock] = ret.renderCodeBlock
+	ret.RendererFuncs[ast.NodeMathBlock] = ret.renderMathBlock
+	ret.RendererFuncs[ast.NodeTable] = ret.renderTable
+	ret.RendererFuncs[ast.NodeFootnotesDefBlock] = ret.renderFootnotesDefBlock
+	ret.RendererFuncs[ast.NodeFootnotesDef] = ret.renderFootnotesDef
+	ret.RendererFuncs[ast.NodeToC] = ret.renderToC
+	ret.RendererFuncs[ast.NodeSuperBlock] = ret.renderSuperBlock
+	for typ := range rstSkippedBlocks {
+		ret.RendererFuncs[typ] = ret.renderSkip
+	}
+	ret.DefaultRendererFunc = ret.renderDefault
+	return ret
+}
+
+// renderDefault 将不支持的块渲染为原始透传块并记录警告，不支持的行级节点只渲染其子节点。
+func (r *RSTRenderer) renderDefault(node *ast.Node, entering bool) ast.WalkStatus {
+	if !node.IsBlock() {
+		return ast.WalkContinue
+	}
+	if entering {
+		r.warn(node, "raw passthrough block")
+		r.WriteString(".. raw:: html\n\n" + markupIndent(markupRaw(r.Tree, node, r.Options), "   ") + "\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *RSTRenderer) warn(node *ast.Node, degradation string) {
+	r.Warnings = append(r.Warnings, "unsupported node ["+node.Type.String()+"] is rendered as "+degradation)
+}
+
+func (r *RSTRenderer) renderSkip(node *ast.Node, entering bool) ast.WalkStatus {
+	return ast.WalkSkipChildren
+}
+
+// push 将后续输出重定向到新的缓冲中。
+func (r *RSTRenderer) push() {
+	r.writers = append(r.writers, r.Writer)
+	r.Writer = &bytes.Buffer{}
+}
+
+// pop 恢复上一个输出缓冲，返回当前缓冲中去掉末尾空白的内容。
+func (r *RSTRenderer) pop() string {
+	ret := strings.TrimRight(r.Writer.String(), " \n")
+	r.Writer = r.writers[len(r.writers)-1]
+	r.writers = r.writers[:len(r.writers)-1]
+	if 0 < r.Writer.Len() {
+		r.LastOut = r.Writer.Bytes()[r.Writer.Len()-1]
+	}
+	return ret
+}
+
+func (r *RSTRenderer) renderDocument(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Warnings, r.images, r.rawHTML = nil, nil, false
+		return ast.WalkContinue
+	}
+
+	if 0 < len(r.images) {
+		r.Newline()
+		r.WriteString("\n" + strings.Join(r.images, "\n") + "\n")
+	}
+	if r.rawHTML {
+		// 角色需要在使用前定义
+		content := r.Writer.String()
+		r.Writer.Reset()
+		r.WriteString(".. role:: raw-html(raw)\n   :format: html\n\n" + content)
+	}
+	return ast.WalkContinue
+}
+
+// blockEnd 在块 node 结束后换行，相邻块之间使用空行分隔。
+func (r *RSTRenderer) blockEnd(node *ast.Node) {
+	r.Newline()
+	if nil != markupNextBlock(node, rstSkippedBlocks) {
+		r.WriteByte('\n')
+	}
+}
+
+func (r *RSTRenderer) renderSuperBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderParagraph(node *ast.Node, entering bool) ast.WalkStatus {
+	if image := node.FirstChild; nil != image && ast.NodeImage == image.Type && nil == image.Next {
+		// 单独成段的图片渲染为图片指令
+		if entering {
+			r.WriteString(".. image:: " + r.linkDest(image) + "\n")
+			if alt := image.Text(); "" != alt {
+				r.WriteString("   :alt: " + alt + "\n")
+			}
+			r.blockEnd(node)
+		}
+		return ast.WalkSkipChildren
+	}
+	if !entering {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+// rstEscape 转义 reStructuredText 文本中的行内标记字符，下划线只在可能构成引用时转义。
+func rstEscape(text string) string {
+	buf := &bytes.Buffer{}
+	for i, c := range text {
+		switch c {
+		case '\\', '*', '`', '|':
+			buf.WriteByte('\\')
+		case '_':
+			if next, _ := utf8.DecodeRuneInString(text[i+1:]); !markupWordChar(next) {
+				buf.WriteByte('\\')
+			}
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
+
+func (r *RSTRenderer) renderText(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		text := util.BytesToStr(node.Tokens)
+		if prev := node.Previous; nil != prev && ast.NodeTaskListItemMarker == prev.Type {
+			text = strings.TrimLeft(text, " ")
+		}
+		r.WriteString(rstEscape(text))
+	}
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderEmojiUnicode(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.Write(node.Tokens)
+	}
+	return ast.WalkSkipChildren
+}
+
+// boundary 在行内标记紧邻字母或者数字时输出转义空白（\ ），否则标记不会被识别。
+func (r *RSTRenderer) boundary(node *ast.Node, entering bool) {
+	if entering && markupWordChar(markupPrevRune(node)) || !entering && markupWordChar(markupNextRune(node)) {
+		r.WriteString("\\ ")
+	}
+}
+
+// renderInlineMarkup 返回一个使用成对标记 mark 渲染行级节点的渲染函数。
+func (r *RSTRenderer) renderInlineMarkup(mark string) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			r.boundary(node, true)
+			r.WriteString(mark)
+		} else {
+			r.WriteString(mark)
+			r.boundary(node, false)
+		}
+		return ast.WalkContinue
+	}
+}
+
+// renderRole 返回一个将行级节点渲染为解释文本角色 :name:`text` 的渲染函数。
+func (r *RSTRenderer) renderRole(name string) RendererFunc {
+	return func(node *ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			text := node.Text()
+			if content := node.ChildByType(ast.NodeInlineMathContent); nil != content {
+				text = strings.TrimSpace(util.BytesToStr(content.Tokens))
+			}
+			r.boundary(node, true)
+			r.WriteString(":" + name + ":`" + strings.ReplaceAll(text, "`", "\\`") + "`")
+			r.boundary(node, false)
+		}
+		return ast.WalkSkipChildren
+	}
+}
+
+// renderPlain 将 reStructuredText 中没有对应标记的行级节点渲染为纯文本并记录警告。
+func (r *RSTRenderer) renderPlain(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.warn(node, "plain text")
+	}
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderCodeSpan(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		if content := node.ChildByType(ast.NodeCodeSpanContent); nil != content {
+			r.boundary(node, true)
+			r.WriteString("``" + util.BytesToStr(content.Tokens) + "``")
+			r.boundary(node, false)
+		}
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *RSTRenderer) renderSoftBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteByte('\n')
+	}
+	return ast.WalkContinue
+}
+
+// linkDest 返回链接或者图片 node 的地址。
+func (r *RSTRenderer) linkDest(node *ast.Node) string {
+	if dest := node.ChildByType(ast.NodeLinkDest); nil != dest {
+		return util.BytesToStr(r.LinkPath(dest.Tokens))
+	}
+	return ""
+}
+
+// rstEscapeRef 转义超链接引用文本中的反引号和尖括号。
+var rstEscapeRef = strings.NewReplacer("`", "\\`", "<", "\\<")
+
+func (r *RSTRenderer) renderLink(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	dest, text := r.linkDest(node), node.Text()
+	r.boundary(node, true)
+	if path, anchor, ok := crossDocLink(dest); ok && "" == anchor {
+		r.WriteString(":doc:`" + rstEscapeRef.Replace(text) + " <" + path + ">`")
+	} else if ok {
+		// :doc: 不支持锚点，链接到生成的 HTML 页面
+		r.WriteString("`" + rstEscapeRef.Replace(text) + " <" + path + ".html#" + anchor + ">`__")
+	} else if strings.HasPrefix(dest, "#") {
+		r.WriteString(":ref:`" + rstEscapeRef.Replace(text) + " <" + dest[1:] + ">`")
+	} else if text == dest {
+		r.WriteString(dest)
+	} else {
+		// 使用匿名超链接，避免同名链接文本冲突
+		r.WriteString("`" + rstEscapeRef.Replace(text) + " <" + dest + ">`__")
+	}
+	r.boundary(node, false)
+	return ast.WalkSkipChildren
+}
+
+// renderImage 将行内图片渲染为替换引用，替换定义输出在文末。
+func (r *RSTRenderer) renderImage(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		name := "image" + strconv.Itoa(len(r.images)+1)
+		definition := ".. |" + name + "| image:: " + r.linkDest(node)
+		if alt := node.Text(); "" != alt {
+			definition += "\n   :alt: " + alt
+		}
+		r.images = append(r.images, definition)
+		r.boundary(node, true)
+		r.WriteString("|" + name + "|")
+		r.boundary(node, false)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *RSTRenderer) renderBlockRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		id := ""
+		if idNode := node.ChildByType(ast.NodeBlockRefID); nil != idNode {
+			id = util.BytesToStr(idNode.Tokens)
+		}
+		r.boundary(node, true)
+		if text := node.ChildByType(ast.NodeBlockRefText); nil != text {
+			r.WriteString(":ref:`" + rstEscapeRef.Replace(util.BytesToStr(text.Tokens)) + " <" + id + ">`")
+		} else {
+			r.WriteString(":ref:`" + id + "`")
+		}
+		r.boundary(node, false)
+	}
+	return ast.WalkSkipChildren
+}
+
+// renderFootnotesRef 将脚注引用渲染为自动编号的标签脚注引用。
+func (r *RSTRenderer) renderFootnotesRef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		pos, def := r.Tree.FindFootnotesDef(node.Tokens)
+		if nil == def {
+			r.WriteString(rstEscape("[" + util.BytesToStr(node.Tokens) + "]"))
+			return ast.WalkContinue
+		}
+		r.boundary(node, true)
+		r.WriteString("[#fn" + strconv.Itoa(pos) + "]_")
+		r.boundary(node, false)
+	}
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderInlineHTML(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.warn(node, "raw passthrough")
+		r.rawHTML = true
+		r.boundary(node, true)
+		r.WriteString(":raw-html:`" + strings.ReplaceAll(util.BytesToStr(node.Tokens), "`", "\\`") + "`")
+		r.boundary(node, false)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *RSTRenderer) renderHeading(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.push()
+		return ast.WalkContinue
+	}
+
+	text := strings.ReplaceAll(r.pop(), "\n", " ")
+	if id := node.ChildByType(ast.NodeHeadingID); nil != id {
+		r.WriteString(".. _" + strings.TrimPrefix(util.BytesToStr(id.Tokens), "#") + ":\n\n")
+	}
+	adornment := strings.Repeat(rstHeadingAdornments[node.HeadingLevel-1], util.StrWidth(text))
+	if 1 == node.HeadingLevel {
+		r.WriteString(adornment + "\n")
+	}
+	r.WriteString(text + "\n" + adornment + "\n")
+	r.blockEnd(node)
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderThematicBreak(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString("----------\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderBlockquote(node *ast.Node, entering bool) ast.WalkStatus {
+	kind, content := admonition(node)
+	if "" == kind {
+		if entering {
+			if prev := node.Previous; nil != prev && ast.NodeParagraph != prev.Type && ast.NodeHeading != prev.Type && ast.NodeThematicBreak != prev.Type {
+				// 使用空注释结束前面的列表、指令等结构，否则缩进的引述会被当作它们的内容
+				r.WriteString("..\n\n")
+			}
+			r.push()
+		} else {
+			r.WriteString(markupIndent(r.pop(), "   ") + "\n")
+			r.blockEnd(node)
+		}
+		return ast.WalkContinue
+	}
+
+	if entering {
+		r.WriteString(".. " + strings.ToLower(kind) + "::\n\n")
+		r.WriteString(markupIndent(strings.TrimRight(markupRenderChildren(r.BaseRenderer, content), " \n"), "   ") + "\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
+
+func (r *RSTRenderer) renderList(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderListItem(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.push()
+		if task := node.FirstChild; nil != task && nil != task.FirstChild && ast.NodeTaskListItemMarker == task.FirstChild.Type {
+			if task.FirstChild.TaskListItemChecked {
+				r.WriteString("[x] ")
+			} else {
+				r.WriteString("[ ] ")
+			}
+		}
+		return ast.WalkContinue
+	}
+
+	marker := "- "
+	if 1 == node.ListData.Typ || (3 == node.ListData.Typ && 0 == node.ListData.BulletChar) {
+		marker = strconv.Itoa(node.ListData.Num) + ". "
+	}
+	content := markupIndent(r.pop(), strings.Repeat(" ", len(marker)))
+	r.WriteString(strings.TrimRight(marker+strings.TrimLeft(content, " "), " ") + "\n")
+	// 包含多个块（比如嵌套列表）的列表项之后需要空行，否则嵌套列表结束时会出现意外的缩进变化
+	if nil != node.Next && (!node.Parent.ListData.Tight || node.FirstChild != node.LastChild) {
+		r.WriteByte('\n')
+	}
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderCodeBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	code := ""
+	if content := node.ChildByType(ast.NodeCodeBlockCode); nil != content {
+		code = strings.TrimRight(util.BytesToStr(content.Tokens), "\n")
+	}
+	if info := strings.Fields(util.BytesToStr(node.CodeBlockInfo)); 0 < len(info) {
+		r.WriteString(".. code-block:: " + info[0] + "\n\n")
+	} else {
+		r.WriteString("::\n\n")
+	}
+	r.WriteString(markupIndent(code, "   ") + "\n")
+	r.blockEnd(node)
+	return ast.WalkSkipChildren
+}
+
+func (r *RSTRenderer) renderMathBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		content := ""
+		if c := node.ChildByType(ast.NodeMathBlockContent); nil != c {
+			content = strings.TrimSpace(util.BytesToStr(c.Tokens))
+		}
+		r.WriteString(".. math::\n\n" + markupIndent(content, "   ") + "\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
+
+// renderTable 将表格渲染为 list-table 指令，第一行作为表头。
+func (r *RSTRenderer) renderTable(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.WalkSkipChildren
+	}
+
+	r.WriteString(".. list-table::\n   :header-rows: 1\n\n")
+	ast.Walk(node, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || ast.NodeTableRow != n.Type {
+			return ast.WalkContinue
+		}
+		for cell := n.FirstChild; nil != cell; cell = cell.Next {
+			marker := "     -"
+			if cell == n.FirstChild {
+				marker = "   * -"
+			}
+			if content := strings.TrimSpace(markupRenderChildren(r.BaseRenderer, cell)); "" != content {
+				marker += " " + content
+			}
+			r.WriteString(marker + "\n")
+		}
+		return ast.WalkSkipChildren
+	})
+	r.blockEnd(node)
+	return ast.WalkSkipChildren
+}
+
+func (r *RSTRenderer) renderFootnotesDefBlock(node *ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		r.blockEnd(node)
+	}
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderFootnotesDef(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.push()
+		return ast.WalkContinue
+	}
+
+	pos, _ := r.Tree.FindFootnotesDef(node.Tokens)
+	marker := ".. [#fn" + strconv.Itoa(pos) + "] "
+	content := markupIndent(r.pop(), "   ")
+	r.WriteString(marker + strings.TrimLeft(content, " ") + "\n")
+	if nil != node.Next {
+		r.WriteByte('\n')
+	}
+	return ast.WalkContinue
+}
+
+func (r *RSTRenderer) renderToC(node *ast.Node, entering bool) ast.WalkStatus {
+	if entering {
+		r.WriteString(".. contents::\n")
+		r.blockEnd(node)
+	}
+	return ast.WalkSkipChildren
+}
@@ -0,0 +1,24 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"regexp"
+)
+
+// disallowedTagRegexp 匹配 GFM 规范中禁用的 HTML 标签。
+// https://github.github.com/gfm/#disallowed-raw-html-extension-
+var disallowedTagRegexp = regexp.MustCompile(`(?i)<(/?(?:title|textarea|style|xmp|iframe|noembed|noframes|script|plaintext))([\t\n\f\r />]|$)`)
+
+// tagFilter 将 tokens 中禁用标签的 < 转义为 &lt;。
+func tagFilter(tokens []byte) []byte {
+	return disallowedTagRegexp.ReplaceAll(tokens, []byte("&lt;$1$2"))
+}
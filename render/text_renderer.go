@@ -0,0 +1,473 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/util"
+)
+
+// TextRenderer 描述了纯文本渲染器，用于邮件 text/plain 正文、短信通知等场景。
+//
+// 渲染结果保留段落、列表、引用和表格等结构：列表渲染项目符号和序号，表格按照东亚宽度对齐列，链接地址作为脚注附加在文末，
+// 设置了 Options.TextWrapWidth 时按照该宽度自动换行。
+type TextRenderer struct {
+	*BaseRenderer
+	links []string // 链接地址，渲染为文末脚注
+
+	// 以下字段用于在纯文本渲染器的基础上扩展其他文本格式的渲染器，比如终端渲染器
+
+	blockFuncs  map[ast.NodeType]textBlockFunc  // 块渲染函数，优先于默认的块渲染
+	inlineFuncs map[ast.NodeType]textInlineFunc // 行级节点渲染函数，优先于默认的行级节点渲染
+	bullet      string                          // 无序列表项目符号
+	quote       string                          // 块引用前缀
+	rule        string                          // 分隔线字符
+	table       *textTableBorder                // 表格边框
+}
+
+// textBlockFunc 描述了纯文本块渲染函数，width 为可用宽度（0 表示不换行），返回渲染后的所有行。
+type textBlockFunc func(n *ast.Node, width int) []string
+
+// textInlineFunc 描述了纯文本行级节点渲染函数，返回在进入或者离开节点时输出的文本，子节点仍然会继续渲染。
+type textInlineFunc func(n *ast.Node, entering bool) string
+
+// textTableBorder 描述了表格边框字符，top、separator 和 bottom 依次为左、中、右连接字符，为空时不绘制该边框线。
+type textTableBorder struct {
+	top, separator, bottom [3]string
+	horizontal, vertical   string
+}
+
+// NewTextRenderer 创建一个纯文本渲染器。
+func NewTextRenderer(tree *parse.Tree, options *Options) Renderer {
+	return newTextRenderer(tree, options)
+}
+
+func newTextRenderer(tree *parse.Tree, options *Options) *TextRenderer {
+	return &TextRenderer{
+		BaseRenderer: NewBaseRenderer(tree, options),
+		blockFuncs:   map[ast.NodeType]textBlockFunc{},
+		inlineFuncs:  map[ast.NodeType]textInlineFunc{},
+		bullet:       "-",
+		quote:        "> ",
+		rule:         "-",
+		table:        &textTableBorder{separator: [3]string{"|", "|", "|"}, horizontal: "-", vertical: "|"},
+	}
+}
+
+// Render 渲染纯文本。
+func (r *TextRenderer) Render() (output []byte) {
+	r.links = nil
+	lines := r.renderBlocks(r.Tree.Root, r.Options.TextWrapWidth)
+	if 0 < len(r.links) {
+		lines = append(lines, "")
+		for i, link := range r.links {
+			lines = append(lines, "["+strconv.Itoa(i+1)+"] "+link)
+		}
+	}
+	if 1 > len(lines) {
+		return []byte{}
+	}
+	output = []byte(strings.Join(lines, "\n") + "\n")
+	return
+}
+
+// renderBlocks 渲染容器 container 的所有子块，块之间使用空行分隔（紧凑列表项中的块除外），width 为可用宽度，0 表示不换行。
+func (r *TextRenderer) renderBlocks(container *ast.Node, width int) (ret []string) {
+	tight := ast.NodeListItem == container.Type && nil != container.Parent && container.Parent.ListData.Tight
+	for c := container.FirstChild; nil != c; c = c.Next {
+		lines := r.renderBlock(c, width)
+		if 1 > len(lines) {
+			continue
+		}
+		if 0 < len(ret) && !tight {
+			ret = append(ret, "")
+		}
+		ret = append(ret, lines...)
+	}
+	return
+}
+
+// renderBlock 渲染块 n，返回渲染后的所有行，width 为可用宽度，0 表示不换行。
+func (r *TextRenderer) renderBlock(n *ast.Node, width int) (ret []string) {
+	if blockFunc := r.blockFuncs[n.Type]; nil != blockFunc {
+		return blockFunc(n, width)
+	}
+
+	switch n.Type {
+	case ast.NodeParagraph:
+		return r.wrap(r.inlineText(n), width)
+	case ast.NodeHeading:
+		ret = r.wrap(r.inlineText(n), width)
+		if 1 > len(ret) || 2 < n.HeadingLevel {
+			return
+		}
+		underline := "="
+		if 2 == n.HeadingLevel {
+			underline = "-"
+		}
+		lineWidth := 0
+		for _, line := range ret {
+			if w := textWidth(line); w > lineWidth {
+				lineWidth = w
+			}
+		}
+		return append(ret, strings.Repeat(underline, lineWidth))
+	case ast.NodeThematicBreak:
+		ruleWidth := width
+		if 1 > ruleWidth {
+			ruleWidth = 20
+		}
+		return []string{strings.Repeat(r.rule, ruleWidth)}
+	case ast.NodeBlockquote:
+		return r.indent(r.renderBlocks(n, r.narrow(width, textWidth(r.quote))), r.quote, r.quote)
+	case ast.NodeList:
+		for item := n.FirstChild; nil != item; item = item.Next {
+			if ast.NodeListItem != item.Type {
+				continue
+			}
+			if 0 < len(ret) && !n.ListData.Tight {
+				ret = append(ret, "")
+			}
+			ret = append(ret, r.renderListItem(item, width)...)
+		}
+		return
+	case ast.NodeCodeBlock:
+		code := n.ChildByType(ast.NodeCodeBlockCode)
+		if nil == code {
+			return
+		}
+		return r.indent(strings.Split(strings.TrimRight(util.BytesToStr(code.Tokens), "\n"), "\n"), "    ", "    ")
+	case ast.NodeMathBlock:
+		content := n.ChildByType(ast.NodeMathBlockContent)
+		if nil == content {
+			return
+		}
+		return r.indent(strings.Split(strings.TrimSpace(util.BytesToStr(content.Tokens)), "\n"), "    ", "    ")
+	case ast.NodeTable:
+		return r.renderTable(n)
+	case ast.NodeFootnotesDefBlock:
+		for def := n.FirstChild; nil != def; def = def.Next {
+			marker := "[" + util.BytesToStr(def.Tokens) + "]: "
+			lines := r.renderBlocks(def, r.narrow(width, textWidth(marker)))
+			ret = append(ret, r.indent(lines, marker, strings.Repeat(" ", textWidth(marker)))...)
+		}
+		return
+	case ast.NodeSuperBlock, ast.NodeDocument:
+		return r.renderBlocks(n, width)
+	}
+	// 其他块（比如 HTML 块、YAML Front Matter、目录、内联属性列表和链接引用定义）不输出
+	return
+}
+
+// renderListItem 渲染列表项 item，第一行使用项目符号或者序号作为前缀，其余行按照前缀宽度缩进。
+func (r *TextRenderer) renderListItem(item *ast.Node, width int) (ret []string) {
+	marker := r.bullet
+	if 1 == item.ListData.Typ {
+		marker = strconv.Itoa(item.ListData.Num) + "."
+	}
+	if task := item.FirstChild; nil != task && nil != task.FirstChild && ast.NodeTaskListItemMarker == task.FirstChild.Type {
+		if task.FirstChild.TaskListItemChecked {
+			marker += " [x]"
+		} else {
+			marker += " [ ]"
+		}
+	}
+	marker += " "
+
+	lines := r.renderBlocks(item, r.narrow(width, textWidth(marker)))
+	if 1 > len(lines) {
+		return []string{strings.TrimRight(marker, " ")}
+	}
+	return r.indent(lines, marker, strings.Repeat(" ", textWidth(marker)))
+}
+
+// renderTable 渲染表格，每一列按照该列最宽的单元格对齐。
+func (r *TextRenderer) renderTable(table *ast.Node) (ret []string) {
+	var rows [][]string
+	var aligns []int
+	ast.Walk(table, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || ast.NodeTableRow != n.Type {
+			return ast.WalkContinue
+		}
+		var row []string
+		for cell := n.FirstChild; nil != cell; cell = cell.Next {
+			row = append(row, strings.ReplaceAll(r.inlineText(cell), "\n", " "))
+			if 1 > len(rows) {
+				aligns = append(aligns, cell.TableCellAlign)
+			}
+		}
+		rows = append(rows, row)
+		return ast.WalkSkipChildren
+	})
+	if 1 > len(rows) {
+		return
+	}
+
+	widths := make([]int, len(aligns))
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && textWidth(cell) > widths[i] {
+				widths[i] = textWidth(cell)
+			}
+		}
+	}
+	border := r.table
+	renderRow := func(row []string) string {
+		buf := &bytes.Buffer{}
+		buf.WriteString(border.vertical)
+		for i, w := range widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			padding := w - textWidth(cell)
+			left := 0
+			switch aligns[i] {
+			case 2:
+				left = padding / 2
+			case 3:
+				left = padding
+			}
+			buf.WriteString(" " + strings.Repeat(" ", left) + cell + strings.Repeat(" ", padding-left) + " " + border.vertical)
+		}
+		return buf.String()
+	}
+	renderLine := func(joints [3]string) string {
+		buf := &bytes.Buffer{}
+		buf.WriteString(joints[0])
+		for i, w := range widths {
+			buf.WriteString(strings.Repeat(border.horizontal, w+2))
+			if i < len(widths)-1 {
+				buf.WriteString(joints[1])
+			}
+		}
+		buf.WriteString(joints[2])
+		return buf.String()
+	}
+
+	if "" != border.top[0] {
+		ret = append(ret, renderLine(border.top))
+	}
+	ret = append(ret, renderRow(rows[0]))
+	ret = append(ret, renderLine(border.separator))
+	for _, row := range rows[1:] {
+		ret = append(ret, renderRow(row))
+	}
+	if "" != border.bottom[0] {
+		ret = append(ret, renderLine(border.bottom))
+	}
+	return
+}
+
+// inlineText 返回块 n 的行级内容文本，硬换行渲染为换行符，链接地址替换为脚注序号。
+func (r *TextRenderer) inlineText(n *ast.Node) string {
+	buf := &bytes.Buffer{}
+	ast.Walk(n, func(c *ast.Node, entering bool) ast.WalkStatus {
+		if inlineFunc := r.inlineFuncs[c.Type]; nil != inlineFunc {
+			buf.WriteString(inlineFunc(c, entering))
+			return ast.WalkContinue
+		}
+
+		switch c.Type {
+		case ast.NodeText, ast.NodeLinkText, ast.NodeCodeSpanContent, ast.NodeInlineMathContent, ast.NodeHTMLEntity, ast.NodeEmojiAlias,
+			ast.NodeBackslashContent, ast.NodeBlockRefText, ast.NodeFileAnnotationRefText:
+			if entering {
+				buf.Write(c.Tokens)
+			}
+		case ast.NodeEmojiUnicode:
+			if entering {
+				buf.Write(c.Tokens)
+			}
+			return ast.WalkSkipChildren
+		case ast.NodeFootnotesRef:
+			if entering {
+				buf.WriteString("[" + util.BytesToStr(c.Tokens) + "]")
+			}
+		case ast.NodeHardBreak, ast.NodeBr:
+			if entering {
+				buf.WriteByte('\n')
+			}
+		case ast.NodeSoftBreak:
+			if entering {
+				if 0 < r.Options.TextWrapWidth {
+					buf.WriteByte(' ')
+				} else {
+					buf.WriteByte('\n')
+				}
+			}
+		case ast.NodeLink, ast.NodeImage:
+			if !entering {
+				if number := r.linkNumber(c); "" != number {
+					buf.WriteString("[" + number + "]")
+				}
+			}
+		case ast.NodeKramdownSpanIAL, ast.NodeInlineHTML:
+			return ast.WalkSkipChildren
+		}
+		return ast.WalkContinue
+	})
+	return strings.TrimSpace(buf.String())
+}
+
+// linkNumber 返回链接或者图片 link 的地址对应的脚注序号，地址和链接文本相同（比如自动链接）时返回空字符串。
+func (r *TextRenderer) linkNumber(link *ast.Node) string {
+	dest := link.ChildByType(ast.NodeLinkDest)
+	if nil == dest || 1 > len(dest.Tokens) {
+		return ""
+	}
+	destStr := util.BytesToStr(r.LinkPath(dest.Tokens))
+	if text := link.ChildByType(ast.NodeLinkText); ast.NodeLink == link.Type && nil != text && util.BytesToStr(text.Tokens) == util.BytesToStr(dest.Tokens) {
+		return ""
+	}
+	for i, l := range r.links {
+		if l == destStr {
+			return strconv.Itoa(i + 1)
+		}
+	}
+	r.links = append(r.links, destStr)
+	return strconv.Itoa(len(r.links))
+}
+
+// wrap 将文本 text 按照宽度 width 折行，width 为 0 时仅按照换行符分行。
+//
+// 空白和中日韩字符之后可以折行，超过宽度的单词（比如链接地址）不会被截断。
+func (r *TextRenderer) wrap(text string, width int) (ret []string) {
+	if "" == text {
+		return
+	}
+	for _, paragraph := range strings.Split(text, "\n") {
+		if 1 > width {
+			ret = append(ret, paragraph)
+			continue
+		}
+
+		var line, word strings.Builder
+		lineWidth, wordWidth := 0, 0
+		flushWord := func() {
+			if 0 < lineWidth && lineWidth+wordWidth > width {
+				ret = append(ret, strings.TrimRightFunc(line.String(), unicode.IsSpace))
+				line.Reset()
+				lineWidth = 0
+				trimmed := strings.TrimLeftFunc(word.String(), unicode.IsSpace)
+				word.Reset()
+				word.WriteString(trimmed)
+				wordWidth = textWidth(trimmed)
+			}
+			line.WriteString(word.String())
+			lineWidth += wordWidth
+			word.Reset()
+			wordWidth = 0
+		}
+		for i := 0; i < len(paragraph); {
+			if seq := escapeSequence(paragraph[i:]); "" != seq {
+				// 终端控制序列不占宽度，也不能被截断
+				word.WriteString(seq)
+				i += len(seq)
+				continue
+			}
+			c, size := utf8.DecodeRuneInString(paragraph[i:])
+			i += size
+			w := util.RuneWidth(c)
+			if unicode.IsSpace(c) {
+				flushWord()
+				word.WriteRune(c)
+				wordWidth += w
+				continue
+			}
+			if 2 == w {
+				// 宽字符前后都可以折行
+				flushWord()
+				word.WriteRune(c)
+				wordWidth += w
+				flushWord()
+				continue
+			}
+			word.WriteRune(c)
+			wordWidth += w
+		}
+		flushWord()
+		ret = append(ret, strings.TrimRightFunc(line.String(), unicode.IsSpace))
+	}
+	return
+}
+
+// narrow 返回减去缩进 indent 后的可用宽度，width 为 0（不换行）时仍然返回 0。
+func (r *TextRenderer) narrow(width, indent int) int {
+	if 1 > width {
+		return 0
+	}
+	if width -= indent; 1 > width {
+		return 1
+	}
+	return width
+}
+
+// indent 为 lines 的第一行添加前缀 first，其余非空行添加前缀 rest。
+func (r *TextRenderer) indent(lines []string, first, rest string) (ret []string) {
+	for i, line := range lines {
+		prefix := rest
+		if 0 == i {
+			prefix = first
+		}
+		if "" == line && 0 < i {
+			ret = append(ret, strings.TrimRight(prefix, " "))
+			continue
+		}
+		ret = append(ret, prefix+line)
+	}
+	return
+}
+
+// textWidth 返回 str 的显示宽度，终端控制序列不计入宽度。
+func textWidth(str string) (ret int) {
+	for i := 0; i < len(str); {
+		if seq := escapeSequence(str[i:]); "" != seq {
+			i += len(seq)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(str[i:])
+		ret += util.RuneWidth(r)
+		i += size
+	}
+	return
+}
+
+// escapeSequence 返回 str 开头的终端控制序列（CSI 序列，比如 \x1b[1m，或者 OSC 序列，比如 \x1b]8;;url\x1b\\），str 不以控制序列开头时返回空字符串。
+func escapeSequence(str string) string {
+	if 2 > len(str) || 0x1B != str[0] {
+		return ""
+	}
+	switch str[1] {
+	case '[':
+		for i := 2; i < len(str); i++ {
+			if 0x40 <= str[i] && 0x7E >= str[i] {
+				return str[:i+1]
+			}
+		}
+	case ']':
+		for i := 2; i < len(str); i++ {
+			if 0x07 == str[i] {
+				return str[:i+1]
+			}
+			if 0x1B == str[i] && i+1 < len(str) && '\\' == str[i+1] {
+				return str[:i+2]
+			}
+		}
+	}
+	return ""
+}
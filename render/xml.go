@@ -0,0 +1,19 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package render
+
+// xmlChar 用于 strings.Map，去掉 XML 1.0 中不允许出现的字符（比如除了制表符、换行和回车以外的控制字符）。
+func xmlChar(r rune) rune {
+	if 0x9 == r || 0xA == r || 0xD == r || (0x20 <= r && 0xD7FF >= r) || (0xE000 <= r && 0xFFFD >= r) || 0x10000 <= r {
+		return r
+	}
+	return -1
+}
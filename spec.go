@@ -0,0 +1,142 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"context"
+	"strings"
+)
+
+// SpecExample 描述了规范中的一个示例。
+type SpecExample struct {
+	Example   int    `json:"example"`             // 示例序号，从 1 开始
+	Section   string `json:"section"`             // 所在章节
+	Extension string `json:"extension,omitempty"` // GFM 扩展名，比如 table、strikethrough
+	Markdown  string `json:"markdown"`            // 输入
+	HTML      string `json:"html"`                // 期望输出
+	StartLine int    `json:"start_line"`          // 起始行号
+	EndLine   int    `json:"end_line"`            // 结束行号
+}
+
+// SpecSectionReport 描述了规范中一个章节的一致性检查结果。
+type SpecSectionReport struct {
+	Section string `json:"section"` // 章节
+	Total   int    `json:"total"`   // 示例总数
+	Passed  int    `json:"passed"`  // 通过数
+	Failed  []int  `json:"failed"`  // 未通过的示例序号
+}
+
+// SpecReport 描述了规范一致性检查报告。
+type SpecReport struct {
+	Total    int                  `json:"total"`    // 示例总数
+	Passed   int                  `json:"passed"`   // 通过数
+	Sections []*SpecSectionReport `json:"sections"` // 按照规范中的章节顺序排列的章节报告
+}
+
+// specFence 是规范中示例代码块的围栏。
+const specFence = "````````````````````````````````"
+
+// ParseSpec 解析 CommonMark 或者 GFM 规范文本 spec（spec.txt 格式），返回其中的所有示例。
+func ParseSpec(spec []byte) (ret []*SpecExample) {
+	lines := strings.Split(strings.ReplaceAll(string(spec), "\r\n", "\n"), "\n")
+	section := ""
+	var example *SpecExample
+	var markdown, html strings.Builder
+	inHTML := false
+	for i, line := range lines {
+		lineNum := i + 1
+		if nil == example {
+			if "<!-- END TESTS -->" == line {
+				break
+			}
+			if strings.HasPrefix(line, specFence+" example") {
+				example = &SpecExample{Example: len(ret) + 1, Section: section, StartLine: lineNum}
+				example.Extension = strings.TrimSpace(line[len(specFence+" example"):])
+				markdown.Reset()
+				html.Reset()
+				inHTML = false
+			} else if strings.HasPrefix(line, "#") {
+				if heading := strings.TrimLeft(line, "#"); strings.HasPrefix(heading, " ") {
+					section = strings.TrimSpace(heading)
+				}
+			}
+			continue
+		}
+
+		if specFence == line {
+			example.EndLine = lineNum
+			example.Markdown = strings.ReplaceAll(markdown.String(), "→", "\t")
+			example.HTML = strings.ReplaceAll(html.String(), "→", "\t")
+			ret = append(ret, example)
+			example = nil
+			continue
+		}
+		if "." == line && !inHTML {
+			inHTML = true
+			continue
+		}
+		if inHTML {
+			html.WriteString(line + "\n")
+		} else {
+			markdown.WriteString(line + "\n")
+		}
+	}
+	return
+}
+
+// specExtensions 定义了 GFM 规范示例中的扩展名对应的引擎选项设置。
+var specExtensions = map[string]func(lute *Lute){
+	"table":         func(lute *Lute) { lute.SetGFMTable(true) },
+	"strikethrough": func(lute *Lute) { lute.SetGFMStrikethrough(true) },
+	"autolink":      func(lute *Lute) { lute.SetGFMAutoLink(true) },
+	"tagfilter":     func(lute *Lute) { lute.SetGFMTagFilter(true) },
+	"tasklist":      func(lute *Lute) { lute.SetGFMTaskListItem(true) },
+}
+
+// SpecReport 渲染所有示例 examples，并和期望输出逐字比较，返回按照章节统计的一致性检查报告。
+//
+// 普通示例使用当前引擎的选项渲染；GFM 扩展示例在当前引擎选项的基础上启用相应的扩展后渲染；标记为 disabled 的示例会被跳过。
+// 比如使用 PresetCommonMark 预设的引擎检查 GFM 规范时，和 GFM 官方测试的运行方式一致。
+func (lute *Lute) SpecReport(examples []*SpecExample) (ret *SpecReport) {
+	ret = &SpecReport{}
+	sections := map[string]*SpecSectionReport{}
+	engines := map[string]*Lute{"": lute}
+	for _, example := range examples {
+		engine := engines[example.Extension]
+		if nil == engine {
+			extension := specExtensions[example.Extension]
+			if nil == extension { // disabled 或者不支持的扩展
+				continue
+			}
+
+			engine = NewWithConfig(lute.Config())
+			extension(engine)
+			engines[example.Extension] = engine
+		}
+
+		section := sections[example.Section]
+		if nil == section {
+			section = &SpecSectionReport{Section: example.Section}
+			sections[example.Section] = section
+			ret.Sections = append(ret.Sections, section)
+		}
+
+		ret.Total++
+		section.Total++
+		if html, err := engine.MarkdownE(context.Background(), "", []byte(example.Markdown)); nil == err && example.HTML == string(html) {
+			ret.Passed++
+			section.Passed++
+		} else {
+			section.Failed = append(section.Failed, example.Example)
+		}
+	}
+	return
+}
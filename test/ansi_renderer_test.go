@@ -0,0 +1,74 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var ansiRendererTests = []parseTest{
+
+	{"5", "```go\nfmt.Println(1)\n```\n\n$$\nx\n$$\n", "┌─ go ───────────┐\n│ fmt.Println(1) │\n└────────────────┘\n\n┌─ math ──┐\n│ x       │\n└─────────┘\n"},
+	{"4", "|a|中文|\n|:-|-:|\n|1|2|\n", "┌───┬──────┐\n│ \x1b[1ma\x1b[22m │ \x1b[1m中文\x1b[22m │\n├───┼──────┤\n│ 1 │    2 │\n└───┴──────┘\n"},
+	{"3", "> foo\n\n- a\n- b\n\n---\n", "\x1b[90m│\x1b[39m foo\n\n• a\n• b\n\n────────────────────\n"},
+	{"2", "[foo](https://b3log.org) ![bar](/a.png)\n", "\x1b]8;;https://b3log.org\x1b\\\x1b[4mfoo\x1b[24m\x1b]8;;\x1b\\ \x1b]8;;/a.png\x1b\\\x1b[4mbar\x1b[24m\x1b]8;;\x1b\\\n"},
+	{"1", "# foo\n\n## bar\n\n#### baz\n", "\x1b[1;35m\x1b[4mfoo\x1b[24m\x1b[0m\n\n\x1b[1;34mbar\x1b[0m\n\n\x1b[1;36mbaz\x1b[0m\n"},
+	{"0", "*a* **b** ~~c~~ `d`\n", "\x1b[3ma\x1b[23m \x1b[1mb\x1b[22m \x1b[9mc\x1b[29m \x1b[36md\x1b[39m\n"},
+}
+
+func TestANSIRenderer(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetCodeSyntaxHighlight(false)
+
+	for _, test := range ansiRendererTests {
+		text := luteEngine.Md2ANSI(test.from)
+		if test.to != text {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, text, test.from)
+		}
+	}
+}
+
+var ansiRendererWrapTests = []parseTest{
+
+	{"1", "```\nfoo\n```\n", "┌──────────────────┐\n│ foo              │\n└──────────────────┘\n"},
+	{"0", "**bold text** and [a link](https://b3log.org) wraps here\n", "\x1b[1mbold text\x1b[22m and \x1b]8;;https://b3log.org\x1b\\\x1b[4ma link\x1b[24m\x1b]8;;\x1b\\\nwraps here\n"},
+}
+
+func TestANSIRendererWrap(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetCodeSyntaxHighlight(false)
+	luteEngine.SetTextWrapWidth(20)
+
+	for _, test := range ansiRendererWrapTests {
+		text := luteEngine.Md2ANSI(test.from)
+		if test.to != text {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, text, test.from)
+		}
+	}
+}
+
+func TestANSIRendererHighlight(t *testing.T) {
+	luteEngine := lute.New()
+
+	text := luteEngine.Md2ANSI("```go\nfunc main() {}\n```\n")
+	if !strings.Contains(text, "\x1b[38;5;") || !strings.HasSuffix(text, "() {}\x1b[0m │\n└────────────────┘\n") {
+		t.Fatalf("highlight with 256 colors failed, got\n\t%q", text)
+	}
+
+	luteEngine.SetANSITrueColor(true)
+	text = luteEngine.Md2ANSI("```go\nfunc main() {}\n```\n")
+	if !strings.Contains(text, "\x1b[38;2;") {
+		t.Fatalf("highlight with true color failed, got\n\t%q", text)
+	}
+}
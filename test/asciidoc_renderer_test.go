@@ -0,0 +1,68 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var asciiDocRendererTests = []parseTest{
+
+	{"8", "foo[^1] bar[^1]\n\n[^1]: note [x]\n", "foofootnote:fn1[note [x\\]] barfootnote:fn1[]\n"},
+	{"7", "[ext](https://b3log.org) [doc](guide/install.md) [sec](guide/install.md#linux) [anchor](#sec) [pdf](a.pdf) <https://ld246.com>\n", "https://b3log.org[ext] xref:guide/install.adoc[doc] xref:guide/install.adoc#linux[sec] <<sec,anchor>> link:a.pdf[pdf] https://ld246.com\n"},
+	{"6", "```go\nfmt.Println(1)\n```\n\n```\n----\n```\n\n$$\n\\sum\n$$\n\nfoo $x^2$\n", ":stem: latexmath\n\n[source,go]\n----\nfmt.Println(1)\n----\n\n-----\n----\n-----\n\n[stem]\n++++\n\\sum\n++++\n\nfoo stem:[x^2]\n"},
+	{"5", "|a|b\\|c|\n|:-:|-:|\n|1|2|\n", "[cols=\"^,>\",options=\"header\"]\n|===\n|a |b\\|c\n|1 |2\n|===\n"},
+	{"4", "- a\n- [x] b\n  ```go\n  code\n  ```\n  - c\n\n3. one\n4. two\n", "* a\n\n* [x] b\n+\n[source,go]\n----\ncode\n----\n** c\n\n[start=3]\n. one\n. two\n"},
+	{"3", "> [!NOTE]\n> Be careful.\n\n> **Warning:** hot\n\n> quote\n> > nested\n", "[NOTE]\n====\nBe careful.\n====\n\n[WARNING]\n====\nhot\n====\n\n____\nquote\n\n______\nnested\n______\n____\n"},
+	{"2", "*em* **strong** foo**bar**baz `a*b` ~~del~~ ==mark== ^sup^ ~sub~\nfoo  \nbar ![img](a.png)\n\n![block](b.png)\n", "_em_ *strong* foo**bar**baz `+a*b+` [.line-through]#del# #mark# ^sup^ ~sub~\nfoo +\nbar image:a.png[img]\n\nimage::b.png[block]\n"},
+	{"1", "## Install\n\n### Linux\n\n---\n", "=== Install\n\n==== Linux\n\n'''\n"},
+	{"0", "# Title\n\n## Section\n\nfoo\n", "= Title\n\n== Section\n\nfoo\n"},
+}
+
+func TestAsciiDocRenderer(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMark(true)
+	luteEngine.SetSup(true)
+	luteEngine.SetSub(true)
+
+	for _, test := range asciiDocRendererTests {
+		asciidoc, warnings := luteEngine.Md2AsciiDoc(test.from)
+		if test.to != asciidoc {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, asciidoc, test.from)
+		}
+		if 0 < len(warnings) {
+			t.Fatalf("test case [%s] failed, unexpected warnings %q", test.name, warnings)
+		}
+	}
+}
+
+func TestAsciiDocRendererWarnings(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetYamlFrontMatter(true)
+
+	asciidoc, warnings := luteEngine.Md2AsciiDoc("---\ntitle: x\n---\n\n<div>\nhtml\n</div>\n\nfoo <span>bar</span>\n")
+	expected := "++++\n---\ntitle: x\n---\n++++\n\n++++\n<div>\nhtml\n</div>\n++++\n\nfoo pass:[<span>]barpass:[</span>]\n"
+	if expected != asciidoc {
+		t.Fatalf("render unsupported nodes failed\nexpected\n\t%q\ngot\n\t%q", expected, asciidoc)
+	}
+	expectedWarnings := []string{
+		"unsupported node [NodeYamlFrontMatter] is rendered as raw passthrough block",
+		"unsupported node [NodeHTMLBlock] is rendered as raw passthrough block",
+		"unsupported node [NodeInlineHTML] is rendered as raw passthrough",
+		"unsupported node [NodeInlineHTML] is rendered as raw passthrough",
+	}
+	if !reflect.DeepEqual(expectedWarnings, warnings) {
+		t.Fatalf("warnings mismatch\nexpected\n\t%q\ngot\n\t%q", expectedWarnings, warnings)
+	}
+}
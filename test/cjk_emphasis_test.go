@@ -0,0 +1,84 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var cjkFriendlyEmphasisTests = []parseTest{
+
+	{"10", "foo**(bar)**baz\n", "<p>foo**(bar)**baz</p>\n"},
+	{"9", "a**「b」**c\n", "<p>a<strong>「b」</strong>c</p>\n"},
+	{"8", "__加粗：__后面\n", "<p>__加粗：__后面</p>\n"},
+	{"7", "これは**「太字」**です\n", "<p>これは<strong>「太字」</strong>です</p>\n"},
+	{"6", "*斜体。*后面\n", "<p><em>斜体。</em>后面</p>\n"},
+	{"5", "测试**（括号）**测试\n", "<p>测试<strong>（括号）</strong>测试</p>\n"},
+	{"4", "** 加粗：**后面\n", "<p>** 加粗：**后面</p>\n"},
+	{"3", "中文**English**中文\n", "<p>中文<strong>English</strong>中文</p>\n"},
+	{"2", "测试**「引用」**测试\n", "<p>测试<strong>「引用」</strong>测试</p>\n"},
+	{"1", "**「引用」**测试\n", "<p><strong>「引用」</strong>测试</p>\n"},
+	{"0", "**加粗：**后面的文字\n", "<p><strong>加粗：</strong>后面的文字</p>\n"},
+}
+
+func TestCJKFriendlyEmphasis(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetCJKFriendlyEmphasis(true)
+
+	for _, test := range cjkFriendlyEmphasisTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var cjkFriendlyEmphasisDisableTests = []parseTest{
+
+	{"1", "**「引用」**测试\n", "<p>**「引用」**测试</p>\n"},
+	{"0", "**加粗：**后面的文字\n", "<p>**加粗：**后面的文字</p>\n"},
+}
+
+func TestCJKFriendlyEmphasisDisable(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range cjkFriendlyEmphasisDisableTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+// TestCJKFriendlyEmphasisSpec 确认启用中日韩友好强调规则后仍然通过 CommonMark 规范测试。
+func TestCJKFriendlyEmphasisSpec(t *testing.T) {
+	bytes, err := ioutil.ReadFile("commonmark-spec.json")
+	if nil != err {
+		t.Fatalf("read spec test cases failed: " + err.Error())
+	}
+	var examples []*lute.SpecExample
+	if err = json.Unmarshal(bytes, &examples); nil != err {
+		t.Fatalf("read spec test caes failed: " + err.Error())
+	}
+
+	luteEngine, _ := lute.NewWithPreset(lute.PresetCommonMark)
+	luteEngine.SetCJKFriendlyEmphasis(true)
+	report := luteEngine.SpecReport(examples)
+	for _, section := range report.Sections {
+		if section.Passed != section.Total {
+			t.Fatalf("section [%s] failed examples %v", section.Section, section.Failed)
+		}
+	}
+}
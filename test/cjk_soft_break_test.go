@@ -0,0 +1,82 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+)
+
+var joinCJKSoftBreakTests = []parseTest{
+
+	{"5", "中文\n**加粗**\n", "<p>中文<strong>加粗</strong></p>\n"},
+	{"4", "これは\nテストです\n", "<p>これはテストです</p>\n"},
+	{"3", "中文\nEnglish\n", "<p>中文\nEnglish</p>\n"},
+	{"2", "foo\nbar\n", "<p>foo\nbar</p>\n"},
+	{"1", "第一行，\n第二行。\n", "<p>第一行，第二行。</p>\n"},
+	{"0", "中文\n中文\n", "<p>中文中文</p>\n"},
+}
+
+func TestJoinCJKSoftBreak(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetSoftBreak2HardBreak(false)
+	luteEngine.SetAutoSpace(false)
+	luteEngine.SetJoinCJKSoftBreak(true)
+
+	for _, test := range joinCJKSoftBreakTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+
+	luteEngine.SetJoinCJKSoftBreak(false)
+	html := luteEngine.MarkdownStr("", "中文\n中文\n")
+	if expected := "<p>中文\n中文</p>\n"; expected != html {
+		t.Fatalf("expected [%s], got [%s]", expected, html)
+	}
+}
+
+var joinCJKSoftBreakTextTests = []parseTest{
+
+	{"2", "<p>中文\nEnglish</p>", "中文 English"},
+	{"1", "<p>foo\nbar</p>", "foo bar"},
+	{"0", "<p>中文\n中文</p>", "中文中文"},
+}
+
+func TestJoinCJKSoftBreakText(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetAutoSpace(false)
+	luteEngine.SetJoinCJKSoftBreak(true)
+
+	for _, test := range joinCJKSoftBreakTextTests {
+		text := luteEngine.HTML2Text(test.from)
+		if test.to != text {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal html\n\t%q", test.name, test.to, text, test.from)
+		}
+	}
+}
+
+func TestJoinCJKSoftBreakProtylePreview(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetSoftBreak2HardBreak(false)
+	luteEngine.SetAutoSpace(false)
+	luteEngine.SetJoinCJKSoftBreak(true)
+
+	tree := parse.Parse("", []byte("中文\n中文\n\nfoo\nbar\n"), luteEngine.ParseOptions)
+	html := luteEngine.ProtylePreview(tree, luteEngine.RenderOptions)
+	if !strings.Contains(html, "中文中文") || !strings.Contains(html, "foo\nbar") {
+		t.Fatalf("unexpected protyle preview [%s]", html)
+	}
+}
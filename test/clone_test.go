@@ -0,0 +1,120 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+func TestClone(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetKramdownBlockIAL(true)
+
+	tree := parse.Parse("", []byte("# foo\n{: id=\"20210101000000-aaaaaaa\" custom-a=\"b\"}\n\n- [x] bar[^1]\n\n[^1]: baz\n"), luteEngine.ParseOptions)
+	expected := lute.FormatNode(tree.Root, luteEngine.ParseOptions, luteEngine.RenderOptions)
+	clone := tree.Root.Clone(true)
+	if formatted := lute.FormatNode(clone, luteEngine.ParseOptions, luteEngine.RenderOptions); expected != formatted {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, formatted)
+	}
+	if !tree.Root.Equal(clone, nil) {
+		t.Fatalf("clone should be equal to the original")
+	}
+
+	heading := clone.FirstChild
+	heading.SetIALAttr("custom-a", "c")
+	heading.FirstChild.Tokens[0] = 'F'
+	clone.ChildByType(ast.NodeList).FirstChild.ListData.Checked = false
+	if original := tree.Root.FirstChild; "b" != original.IALAttr("custom-a") || "foo" != original.Text() || !tree.Root.ChildByType(ast.NodeList).FirstChild.ListData.Checked {
+		t.Fatalf("modifying the clone should not affect the original")
+	}
+	if tree.Root.Equal(clone, nil) {
+		t.Fatalf("modified clone should not be equal to the original")
+	}
+
+	var ref *ast.Node
+	ast.Walk(clone, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering && ast.NodeFootnotesRef == n.Type {
+			ref = n
+		}
+		return ast.WalkContinue
+	})
+	if def := clone.ChildByType(ast.NodeFootnotesDefBlock).FirstChild; 1 != len(def.FootnotesRefs) || ref != def.FootnotesRefs[0] {
+		t.Fatalf("footnotes refs should point to the cloned refs")
+	}
+
+	if shallow := tree.Root.FirstChild.Clone(false); nil != shallow.FirstChild || nil != shallow.Parent || nil != shallow.Next {
+		t.Fatalf("shallow clone should not have children or siblings")
+	}
+}
+
+func TestCloneWithNewIDs(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetKramdownBlockIAL(true)
+
+	tree := parse.Parse("", []byte("foo\n{: id=\"20210101000000-aaaaaaa\"}\n"), luteEngine.ParseOptions)
+	clone := tree.Root.CloneWithNewIDs(true)
+	paragraph := clone.FirstChild
+	if "20210101000000-aaaaaaa" == paragraph.ID || paragraph.ID != paragraph.IALAttr("id") || paragraph.ID != parse.IALVal(paragraph.Next, "id") {
+		t.Fatalf("clone should have new ids, got [%s] [%s] [%s]", paragraph.ID, paragraph.IALAttr("id"), paragraph.Next.Tokens)
+	}
+	if "20210101000000-aaaaaaa" != tree.Root.FirstChild.ID {
+		t.Fatalf("original ids should not be changed")
+	}
+	if tree.Root.Equal(clone, nil) {
+		t.Fatalf("clone with new ids should not be equal to the original")
+	}
+	if !tree.Root.Equal(clone, &ast.EqualOptions{IgnoreIDs: true}) {
+		t.Fatalf("clone with new ids should be equal to the original ignoring ids")
+	}
+}
+
+type equalTest struct {
+	name  string
+	a     string
+	b     string
+	opts  *ast.EqualOptions
+	equal bool
+}
+
+var equalTests = []equalTest{
+
+	{"10", "- a\n  - b\n", "* a\n  + b\n", &ast.EqualOptions{IgnoreMarkers: true, IgnoreIDs: true}, true},
+	{"9", "1. foo\n", "1) foo\n", &ast.EqualOptions{IgnoreIDs: true}, false},
+	{"8", "- foo\n", "+ foo\n", &ast.EqualOptions{IgnoreMarkers: true, IgnoreIDs: true}, true},
+	{"7", "- foo\n", "* foo\n", &ast.EqualOptions{IgnoreMarkers: true, IgnoreIDs: true}, true},
+	{"6", "- foo\n", "- foo\n", &ast.EqualOptions{IgnoreIDs: true}, true},
+	{"5", "1. foo\n", "1) foo\n", &ast.EqualOptions{IgnoreMarkers: true, IgnoreIDs: true}, true},
+	{"4", "- foo\n", "* foo\n", &ast.EqualOptions{IgnoreIDs: true}, false},
+	{"3", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n", "foo\n{: id=\"20210101000000-bbbbbbb\"}\n", &ast.EqualOptions{IgnoreIDs: true}, true},
+	{"2", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n", "foo\n{: id=\"20210101000000-bbbbbbb\"}\n", nil, false},
+	{"1", "*foo* **bar**\n", "_foo_ __bar__\n", &ast.EqualOptions{IgnoreMarkers: true}, true},
+	{"0", "*foo* **bar**\n", "_foo_ __bar__\n", nil, false},
+}
+
+func TestEqual(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetKramdownBlockIAL(true)
+
+	for _, test := range equalTests {
+		a := parse.Parse("", []byte(test.a), luteEngine.ParseOptions).Root.FirstChild
+		b := parse.Parse("", []byte(test.b), luteEngine.ParseOptions).Root.FirstChild
+		if equal := a.Equal(b, test.opts); test.equal != equal {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%v\ngot\n\t%v", test.name, test.equal, equal)
+		}
+	}
+}
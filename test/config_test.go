@@ -0,0 +1,98 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+func TestPutEmojisNotShared(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.PutEmojis(map[string]string{"lutetest": "🎻"})
+	if html := luteEngine.MarkdownStr("", ":lutetest:"); "<p>🎻</p>\n" != html {
+		t.Fatalf("emoji expected, got %q", html)
+	}
+
+	if html := lute.New().MarkdownStr("", ":lutetest:"); "<p>:lutetest:</p>\n" != html {
+		t.Fatalf("emoji should not be shared with other engines, got %q", html)
+	}
+
+	luteEngine.PutEmojis(map[string]string{"lutetest2": "🎸"})
+	if html := luteEngine.MarkdownStr("", ":lutetest: :lutetest2:"); "<p>🎻 🎸</p>\n" != html {
+		t.Fatalf("emojis expected, got %q", html)
+	}
+
+	emojis, terms := map[string]string{"lutetest": "🎻"}, map[string]string{"lute": "Lute"}
+	luteEngine.SetEmojis(emojis)
+	luteEngine.SetTerms(terms)
+	luteEngine.PutEmojis(map[string]string{"lutetest2": "🎸"})
+	luteEngine.PutTerms(map[string]string{"vditor": "Vditor"})
+	if 1 != len(emojis) || 1 != len(terms) {
+		t.Fatalf("maps set by caller should not be modified")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	luteEngine := lute.New()
+	config := luteEngine.Config()
+	luteEngine.SetGFMStrikethrough(false)
+	luteEngine.PutEmojis(map[string]string{"lutetest": "🎻"})
+
+	engine := lute.NewWithConfig(config)
+	if html := engine.MarkdownStr("", "~~foo~~ :lutetest:"); "<p><del>foo</del> :lutetest:</p>\n" != html {
+		t.Fatalf("snapshot should not be affected by engine, got %q", html)
+	}
+
+	builder := config.Builder().ParseOptions(func(options *parse.Options) {
+		options.GFMStrikethrough = false
+	}).RenderOptions(func(options *render.Options) {
+		options.FixTermTypo = true
+	}).PutEmojis(map[string]string{"lutetest": "🎻"}).PutTerms(map[string]string{"lute": "Lute"})
+	config2 := builder.Build()
+	builder.PutEmojis(map[string]string{"lutetest": "🎸"})
+
+	engine = lute.NewWithConfig(config2)
+	if html := engine.MarkdownStr("", "~~foo~~ :lutetest: lute"); "<p>~~foo~~ 🎻 Lute</p>\n" != html {
+		t.Fatalf("built config failed, got %q", html)
+	}
+	engine.PutTerms(map[string]string{"lute": "LUTE"})
+	if _, ok := config.RenderOptions().Terms["lute"]; ok {
+		t.Fatalf("builder should not affect original snapshot")
+	}
+	if "Lute" != config2.RenderOptions().Terms["lute"] {
+		t.Fatalf("engine should not affect snapshot")
+	}
+	if "🎻" != config2.ParseOptions().AliasEmoji["lutetest"] {
+		t.Fatalf("builder should copy on write after build")
+	}
+}
+
+func TestConfigConcurrent(t *testing.T) {
+	config := lute.NewConfig()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			engine := lute.NewWithConfig(config)
+			engine.PutEmojis(map[string]string{"lutetest": "🎻"})
+			if html := engine.MarkdownStr("", ":lutetest: :heart:"); "<p>🎻 ❤️</p>\n" != html {
+				t.Errorf("concurrent render failed, got %q", html)
+			}
+		}()
+	}
+	wg.Wait()
+}
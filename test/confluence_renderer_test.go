@@ -0,0 +1,48 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var confluenceRendererTests = []parseTest{
+
+	{"4", "| a | b |\n|:-:|--:|\n| 1 | 2 |\n\n![logo](assets/logo%20x.png) ![r](https://b3log.org/a.png)\n\nfoo[^1]\n\n[^1]: bar\n", "<table>\n<tbody>\n<tr><th style=\"text-align: center;\">a</th><th style=\"text-align: right;\">b</th></tr>\n<tr><td style=\"text-align: center;\">1</td><td style=\"text-align: right;\">2</td></tr>\n</tbody>\n</table>\n<p><ac:image ac:alt=\"logo\"><ri:attachment ri:filename=\"logo x.png\" /></ac:image> <ac:image ac:alt=\"r\"><ri:url ri:value=\"https://b3log.org/a.png\" /></ac:image></p>\n<p>foo<sup>[1]</sup></p>\n<hr />\n<ol>\n<li>\n<p>bar</p>\n</li>\n</ol>\n"},
+	{"3", "```js\nif (a]]>b) {}\n```\n\n```\nplain\n```\n", "<ac:structured-macro ac:name=\"code\"><ac:parameter ac:name=\"language\">javascript</ac:parameter><ac:plain-text-body><![CDATA[if (a]]]]><![CDATA[>b) {}]]></ac:plain-text-body></ac:structured-macro>\n<ac:structured-macro ac:name=\"code\"><ac:plain-text-body><![CDATA[plain]]></ac:plain-text-body></ac:structured-macro>\n"},
+	{"2", "- [x] done\n- [ ] todo\n  - nested\n\n3. three\n4. four\n", "<ac:task-list>\n<ac:task>\n<ac:task-id>1</ac:task-id>\n<ac:task-status>complete</ac:task-status>\n<ac:task-body>done</ac:task-body>\n</ac:task>\n<ac:task>\n<ac:task-id>2</ac:task-id>\n<ac:task-status>incomplete</ac:task-status>\n<ac:task-body>todo\n<ul>\n<li>nested</li>\n</ul>\n</ac:task-body>\n</ac:task>\n</ac:task-list>\n<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n"},
+	{"1", "> [!NOTE]\n> Remember this.\n\n> **Warning:** Danger.\n\n> plain\n", "<ac:structured-macro ac:name=\"info\"><ac:rich-text-body>\n<p>Remember this.</p>\n</ac:rich-text-body></ac:structured-macro>\n<ac:structured-macro ac:name=\"warning\"><ac:rich-text-body>\n<p>Danger.</p>\n</ac:rich-text-body></ac:structured-macro>\n<blockquote>\n<p>plain</p>\n</blockquote>\n"},
+	{"0", "# Title\n\nfoo **bar** ~~baz~~ `a<b` [link](https://b3log.org) [anchor](#intro)\n", "<h1>Title</h1>\n<p>foo <strong>bar</strong> <span style=\"text-decoration: line-through;\">baz</span> <code>a&lt;b</code> <a href=\"https://b3log.org\">link</a> <ac:link ac:anchor=\"intro\"><ac:link-body>anchor</ac:link-body></ac:link></p>\n"},
+}
+
+func TestConfluenceRenderer(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range confluenceRendererTests {
+		storage := luteEngine.Md2Confluence(test.from)
+		if test.to != storage {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, storage, test.from)
+		}
+	}
+}
+
+func TestConfluenceRendererInvalidXMLChars(t *testing.T) {
+	luteEngine := lute.New()
+
+	storage := luteEngine.Md2Confluence("# T\x01itle\n\nfoo\x01bar `a\x0Bb`\n\n```\nx\x01y\n```\n")
+	checkWellFormed(t, "storage", "<root>"+storage+"</root>")
+	expected := "<h1>Title</h1>\n<p>foobar <code>ab</code></p>\n<ac:structured-macro ac:name=\"code\"><ac:plain-text-body><![CDATA[xy]]></ac:plain-text-body></ac:structured-macro>\n"
+	if expected != storage {
+		t.Fatalf("invalid XML characters should be removed\nexpected\n\t%q\ngot\n\t%q", expected, storage)
+	}
+}
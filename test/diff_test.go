@@ -0,0 +1,70 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+)
+
+type diffTest struct {
+	name string
+	old  string
+	new  string
+	ops  string
+	html string
+}
+
+var diffTests = []diffTest{
+
+	{"7", "a c\n", "a 中文b c\n", "update", "<div class=\"lute-diff lute-diff--update\">\n<p>a <ins>中文 b </ins>c</p>\n</div>\n"},
+	{"6", "foo bar\n", "foo &amp; bar\n", "update", "<div class=\"lute-diff lute-diff--update\">\n<p>foo <ins>&amp;</ins><ins> </ins>bar</p>\n</div>\n"},
+	{"5", "| a | b |\n| --- | --- |\n| foo | bar |\n", "| a | b |\n| --- | --- |\n| foo | baz |\n", "update", "<div class=\"lute-diff lute-diff--update\">\n<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>foo</td>\n<td><del>bar</del><ins>baz</ins></td>\n</tr>\n</tbody>\n</table>\n</div>\n"},
+	{"4", "foo\n\n```go\nfmt.Println(1)\n```\n", "foo\n\n```go\nfmt.Println(2)\n```\n", "equal update", "<p>foo</p>\n<div class=\"lute-diff lute-diff--update\">\n<div class=\"lute-diff lute-diff--delete\">\n<pre><code class=\"language-go\">fmt.Println(1)\n</code></pre>\n</div>\n<div class=\"lute-diff lute-diff--insert\">\n<pre><code class=\"language-go\">fmt.Println(2)\n</code></pre>\n</div>\n</div>\n"},
+	{"3", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n\nbar\n{: id=\"20210101000000-bbbbbbb\"}\n", "bar changed totally\n{: id=\"20210101000000-bbbbbbb\"}\n\nfoo\n{: id=\"20210101000000-aaaaaaa\"}\n", "move equal", "<div class=\"lute-diff lute-diff--move\">\n<p id=\"20210101000000-bbbbbbb\">bar<ins> changed totally</ins></p>\n</div>\n<p id=\"20210101000000-aaaaaaa\">foo</p>\n"},
+	{"2", "a one\n\nb two\n\nc three\n", "c three\n\na one\n\nb two\n", "move equal equal", "<div class=\"lute-diff lute-diff--move\">\n<p>c three</p>\n</div>\n<p>a one</p>\n<p>b two</p>\n"},
+	{"1", "hello [world](/a) **bold**\n\nremoved\n", "hello [earth](/a) **bold**\n\n---\n", "update delete insert", "<div class=\"lute-diff lute-diff--update\">\n<p>hello <a href=\"/a\"><del>world</del><ins>earth</ins></a> <strong>bold</strong></p>\n</div>\n<div class=\"lute-diff lute-diff--delete\">\n<p>removed</p>\n</div>\n<div class=\"lute-diff lute-diff--insert\">\n<hr />\n</div>\n"},
+	{"0", "# Title\n\nfoo bar baz\n", "# Title\n\nfoo qux baz\n\nnew\n", "equal update insert", "<h1 id=\"Title\">Title</h1>\n<div class=\"lute-diff lute-diff--update\">\n<p>foo <del>bar</del><ins>qux</ins> baz</p>\n</div>\n<div class=\"lute-diff lute-diff--insert\">\n<p>new</p>\n</div>\n"},
+}
+
+func TestDiff(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetKramdownBlockIAL(true)
+	luteEngine.SetCodeSyntaxHighlight(false)
+	luteEngine.SetAutoSpace(true)
+
+	for _, test := range diffTests {
+		var ops []string
+		for _, op := range luteEngine.Diff(test.old, test.new).Ops {
+			ops = append(ops, op.Type.String())
+		}
+		if opsStr := strings.Join(ops, " "); test.ops != opsStr {
+			t.Fatalf("test case [%s] failed\nexpected ops\n\t%q\ngot\n\t%q", test.name, test.ops, opsStr)
+		}
+		if html := luteEngine.DiffHTML(test.old, test.new); test.html != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.html, html)
+		}
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	var diffs []string
+	for _, diff := range parse.DiffText("foo 中文 bar", "foo 中国 baz bar") {
+		diffs = append(diffs, diff.Type.String()+":"+diff.Text)
+	}
+	if expected := "equal:foo 中|delete:文 |insert:国 baz |equal:bar"; expected != strings.Join(diffs, "|") {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, strings.Join(diffs, "|"))
+	}
+}
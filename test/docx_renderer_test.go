@@ -0,0 +1,129 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// +build !javascript
+
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var docxRendererTests = []parseTest{
+
+	{"8", "ctrl\x01char\n\n# T {#a\x02b}\n", `<w:p><w:r><w:t xml:space="preserve">ctrlchar</w:t></w:r></w:p><w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:bookmarkStart w:id="1" w:name="ab"/><w:r><w:t xml:space="preserve">T</w:t></w:r><w:bookmarkEnd w:id="1"/></w:p>`},
+	{"7", "x[^1]\n\n[^1]: note\n", `<w:p><w:r><w:t xml:space="preserve">x</w:t></w:r><w:r><w:rPr><w:rStyle w:val="FootnoteReference"/></w:rPr><w:footnoteReference w:id="1"/></w:r></w:p>`},
+	{"6", "[l](https://b3log.org?a&b) [in](#intro)\n", `<w:p><w:hyperlink r:id="rId5"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t xml:space="preserve">l</w:t></w:r></w:hyperlink><w:r><w:t xml:space="preserve"> </w:t></w:r><w:hyperlink w:anchor="intro"><w:r><w:rPr><w:rStyle w:val="Hyperlink"/></w:rPr><w:t xml:space="preserve">in</w:t></w:r></w:hyperlink></w:p>`},
+	{"5", "|a|b|\n|:-:|-:|\n|1|2|\n", `<w:tbl><w:tblPr><w:tblStyle w:val="Table"/><w:tblW w:w="5000" w:type="pct"/></w:tblPr><w:tblGrid><w:gridCol w:w="4513"/><w:gridCol w:w="4513"/></w:tblGrid><w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:tcPr><w:tcW w:w="4513" w:type="dxa"/></w:tcPr><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">a</w:t></w:r></w:p></w:tc><w:tc><w:tcPr><w:tcW w:w="4513" w:type="dxa"/></w:tcPr><w:p><w:pPr><w:jc w:val="right"/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">b</w:t></w:r></w:p></w:tc></w:tr><w:tr><w:tc><w:tcPr><w:tcW w:w="4513" w:type="dxa"/></w:tcPr><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t xml:space="preserve">1</w:t></w:r></w:p></w:tc><w:tc><w:tcPr><w:tcW w:w="4513" w:type="dxa"/></w:tcPr><w:p><w:pPr><w:jc w:val="right"/></w:pPr><w:r><w:t xml:space="preserve">2</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`},
+	{"4", "> q\n\n```go\nfunc() {\n\treturn\n}\n```\n", `<w:p><w:pPr><w:pStyle w:val="BlockText"/><w:ind w:left="720"/></w:pPr><w:r><w:t xml:space="preserve">q</w:t></w:r></w:p><w:p><w:pPr><w:pStyle w:val="SourceCode"/></w:pPr><w:r><w:t xml:space="preserve">func() {</w:t><w:br/><w:tab/><w:t xml:space="preserve">return</w:t><w:br/><w:t xml:space="preserve">}</w:t></w:r></w:p>`},
+	{"3", "- [x] done\n- [ ] todo\n", `<w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:ind w:left="720"/></w:pPr><w:r><w:t xml:space="preserve">☒ </w:t></w:r><w:r><w:t xml:space="preserve">done</w:t></w:r></w:p><w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:ind w:left="720"/></w:pPr><w:r><w:t xml:space="preserve">☐ </w:t></w:r><w:r><w:t xml:space="preserve">todo</w:t></w:r></w:p>`},
+	{"2", "* a\n  * b\n\n3. c\n", `<w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">a</w:t></w:r></w:p><w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">b</w:t></w:r></w:p><w:p><w:pPr><w:pStyle w:val="ListParagraph"/><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">c</w:t></w:r></w:p>`},
+	{"1", "**b** *i* `c` ~~s~~ ==m==\n", `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">b</w:t></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">i</w:t></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r><w:r><w:rPr><w:rStyle w:val="VerbatimChar"/></w:rPr><w:t xml:space="preserve">c</w:t></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r><w:r><w:rPr><w:strike/></w:rPr><w:t xml:space="preserve">s</w:t></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r><w:r><w:rPr><w:highlight w:val="yellow"/></w:rPr><w:t xml:space="preserve">m</w:t></w:r></w:p>`},
+	{"0", "# Title {#intro}\n", `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:bookmarkStart w:id="1" w:name="intro"/><w:r><w:t xml:space="preserve">Title</w:t></w:r><w:bookmarkEnd w:id="1"/></w:p>`},
+}
+
+func TestDocxRenderer(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMark(true)
+
+	for _, test := range docxRendererTests {
+		docx := unzipParts(t, luteEngine.Md2Docx(test.from))
+		body := docx["word/document.xml"]
+		body = body[strings.Index(body, "<w:body>")+len("<w:body>") : strings.Index(body, "<w:sectPr>")]
+		if test.to != body {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, body, test.from)
+		}
+	}
+}
+
+func TestDocxRendererPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lute-docx")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	img, err := os.Create(filepath.Join(dir, "logo.png"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	png.Encode(img, image.NewRGBA(image.Rect(0, 0, 2000, 100)))
+	img.Close()
+
+	luteEngine := lute.New()
+	luteEngine.SetDocxResourceDir(dir)
+	docx := unzipParts(t, luteEngine.Md2Docx("![logo](logo.png) ![missing](missing.png)\n\n2. a\n3. b\n\nx[^1]\n\n[^1]: note *e*\n"))
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "word/document.xml", "word/_rels/document.xml.rels", "word/styles.xml", "word/numbering.xml", "word/footnotes.xml", "word/settings.xml"} {
+		content, ok := docx[name]
+		if !ok {
+			t.Fatalf("missing part [%s]", name)
+		}
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		for {
+			if _, err := decoder.Token(); nil != err {
+				if io.EOF != err {
+					t.Fatalf("part [%s] is not well-formed: %s", name, err)
+				}
+				break
+			}
+		}
+	}
+	if _, ok := docx["word/media/image1.png"]; !ok {
+		t.Fatal("local image is not embedded")
+	}
+
+	expected := []struct {
+		name, fragment string
+	}{
+		{"word/document.xml", `<wp:extent cx="5731510" cy="286575"/><wp:docPr id="1" name="Picture 1" descr="logo"/>`},
+		{"word/document.xml", `<a:blip r:embed="rId5"/>`},
+		{"word/document.xml", `<w:r><w:t xml:space="preserve">missing</w:t></w:r>`},
+		{"word/_rels/document.xml.rels", `<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/>`},
+		{"word/numbering.xml", `<w:num w:numId="2"><w:abstractNumId w:val="1"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="2"/></w:lvlOverride></w:num>`},
+		{"word/footnotes.xml", `<w:footnote w:id="1"><w:p><w:pPr><w:pStyle w:val="FootnoteText"/></w:pPr><w:r><w:rPr><w:rStyle w:val="FootnoteReference"/></w:rPr><w:footnoteRef/></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r><w:r><w:t xml:space="preserve">note </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">e</w:t></w:r></w:p></w:footnote>`},
+		{"word/styles.xml", `<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/>`},
+	}
+	for _, e := range expected {
+		if !strings.Contains(docx[e.name], e.fragment) {
+			t.Fatalf("part [%s] does not contain\n\t%q\ngot\n\t%q", e.name, e.fragment, docx[e.name])
+		}
+	}
+}
+
+// unzipParts 解压 zip 压缩包（.docx、.epub 等），返回部件名到内容的映射。
+func unzipParts(t *testing.T, data []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if nil != err {
+		t.Fatal(err)
+	}
+	ret := map[string]string{}
+	for _, file := range reader.File {
+		f, err := file.Open()
+		if nil != err {
+			t.Fatal(err)
+		}
+		data, _ := ioutil.ReadAll(f)
+		f.Close()
+		ret[file.Name] = string(data)
+	}
+	return ret
+}
@@ -0,0 +1,137 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+// +build !javascript
+
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+func TestEPUBRenderer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lute-epub")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "img"), 0755)
+	img, err := os.Create(filepath.Join(dir, "img", "a.png"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	png.Encode(img, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	img.Close()
+	one := filepath.Join(dir, "one.md")
+	ioutil.WriteFile(one, []byte("---\ntitle: Handbook\nauthor:\n  - Alice\n  - Bob\nlang: zh-CN\ntags: [a, b]\n---\n\n# Intro\n\n![pic](img/a.png) ![remote](https://b3log.org/r.png) [two](two.md#setup) [later](#later)[^1]\n\n# Later\n\n## Sub\n\n[^1]: note &copy;<br>\n"), 0644)
+	two := filepath.Join(dir, "two.md")
+	ioutil.WriteFile(two, []byte("# Two\n\n## Setup\n\n[one](one.md) ![same](img/a.png)\n"), 0644)
+
+	luteEngine := lute.New()
+	luteEngine.SetEPUBSplitHeading(true)
+	luteEngine.SetEPUBCSS("body { color: #333; }")
+	data, err := luteEngine.MdFiles2EPUB(one, two)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if first := reader.File[0]; "mimetype" != first.Name || zip.Store != first.Method {
+		t.Fatalf("mimetype must be the first stored entry, got [%s]", first.Name)
+	}
+	epub := unzipParts(t, data)
+	if "application/epub+zip" != epub["mimetype"] {
+		t.Fatalf("unexpected mimetype [%s]", epub["mimetype"])
+	}
+	for name, content := range epub {
+		if !strings.HasSuffix(name, ".xml") && !strings.HasSuffix(name, ".xhtml") && !strings.HasSuffix(name, ".opf") {
+			continue
+		}
+		checkWellFormed(t, name, content)
+	}
+	if _, ok := epub["OEBPS/images/image1.png"]; !ok {
+		t.Fatal("local image is not embedded")
+	}
+	if _, ok := epub["OEBPS/images/image2.png"]; ok {
+		t.Fatal("the same image is embedded twice")
+	}
+
+	expected := []struct {
+		name, fragment string
+	}{
+		{"OEBPS/content.opf", "<dc:title>Handbook</dc:title>\n<dc:language>zh-CN</dc:language>\n<dc:creator>Alice</dc:creator>\n<dc:creator>Bob</dc:creator>\n<dc:subject>a</dc:subject>\n<dc:subject>b</dc:subject>\n"},
+		{"OEBPS/content.opf", `<item id="image1" href="images/image1.png" media-type="image/png"/>`},
+		{"OEBPS/content.opf", "<spine>\n<itemref idref=\"chapter1\"/>\n<itemref idref=\"chapter2\"/>\n<itemref idref=\"chapter3\"/>\n</spine>"},
+		{"OEBPS/nav.xhtml", "<ol>\n<li><a href=\"chapter1.xhtml#Intro\">Intro</a></li>\n<li><a href=\"chapter2.xhtml#Later\">Later</a>\n<ol>\n<li><a href=\"chapter2.xhtml#Sub\">Sub</a></li>\n</ol>\n</li>\n<li><a href=\"chapter3.xhtml#Two\">Two</a>\n<ol>\n<li><a href=\"chapter3.xhtml#Setup\">Setup</a></li>\n</ol>\n</li>\n</ol>"},
+		{"OEBPS/chapter1.xhtml", `<img src="images/image1.png" alt="pic"/> <a href="https://b3log.org/r.png">remote</a> <a href="chapter3.xhtml#Setup">two</a> <a href="chapter2.xhtml#Later">later</a>`},
+		{"OEBPS/chapter1.xhtml", `<a href="chapter2.xhtml#footnotes-def-1">1</a>`},
+		{"OEBPS/chapter2.xhtml", `<li id="footnotes-def-1"><p>note ©<br/>`},
+		{"OEBPS/chapter3.xhtml", `<a href="chapter1.xhtml">one</a> <img src="images/image1.png" alt="same"/>`},
+		{"OEBPS/css/style.css", ".highlight-chroma"},
+		{"OEBPS/css/style.css", "body { color: #333; }"},
+	}
+	for _, e := range expected {
+		if !strings.Contains(epub[e.name], e.fragment) {
+			t.Fatalf("file [%s] does not contain\n\t%q\ngot\n\t%q", e.name, e.fragment, epub[e.name])
+		}
+	}
+}
+
+func TestEPUBRendererInvalidXMLChars(t *testing.T) {
+	luteEngine := lute.New()
+	epub := unzipParts(t, luteEngine.Md2EPUB("# T\x01itle\n\nctrl\x01char\n"))
+	for _, name := range []string{"OEBPS/chapter1.xhtml", "OEBPS/nav.xhtml"} {
+		checkWellFormed(t, name, epub[name])
+	}
+	if !strings.Contains(epub["OEBPS/chapter1.xhtml"], "<p>ctrlchar</p>") {
+		t.Fatalf("invalid XML characters should be removed, got\n\t%q", epub["OEBPS/chapter1.xhtml"])
+	}
+}
+
+func checkWellFormed(t *testing.T, name, content string) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	for {
+		if _, err := decoder.Token(); nil != err {
+			if io.EOF != err {
+				t.Fatalf("file [%s] is not well-formed: %s", name, err)
+			}
+			return
+		}
+	}
+}
+
+func TestEPUBRendererNoSplit(t *testing.T) {
+	luteEngine := lute.New()
+	epub := unzipParts(t, luteEngine.Md2EPUB("# One\n\n# Two\n", "foo\n"))
+	if _, ok := epub["OEBPS/chapter2.xhtml"]; !ok {
+		t.Fatal("each markdown should be rendered as a chapter")
+	}
+	if _, ok := epub["OEBPS/chapter3.xhtml"]; ok {
+		t.Fatal("chapters should not be split by heading by default")
+	}
+	if expected := "<li><a href=\"chapter1.xhtml#One\">One</a></li>\n<li><a href=\"chapter1.xhtml#Two\">Two</a></li>\n<li><a href=\"chapter2.xhtml\">One 2</a></li>"; !strings.Contains(epub["OEBPS/nav.xhtml"], expected) {
+		t.Fatalf("nav does not contain\n\t%q\ngot\n\t%q", expected, epub["OEBPS/nav.xhtml"])
+	}
+}
@@ -0,0 +1,69 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+func TestMarkdownE(t *testing.T) {
+	luteEngine := lute.New()
+	html, err := luteEngine.MarkdownE(context.Background(), "", []byte("*foo*\n"))
+	if nil != err || "<p><em>foo</em></p>\n" != string(html) {
+		t.Fatalf("markdown failed: %q %v", html, err)
+	}
+
+	luteEngine.SetMaxNestingDepth(1)
+	html, err = luteEngine.MarkdownE(context.Background(), "", []byte("> > foo\n"))
+	if !errors.Is(err, lute.ErrLimitExceeded) {
+		t.Fatalf("limit exceeded expected, got %v", err)
+	}
+	if "<blockquote>\n<p>&gt; foo</p>\n</blockquote>\n" != string(html) {
+		t.Fatalf("degraded output expected, got %q", html)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	html, err = luteEngine.MarkdownE(ctx, "", []byte("*foo*\n"))
+	if !errors.Is(err, lute.ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled expected, got %v", err)
+	}
+	if nil != html {
+		t.Fatalf("empty output expected, got %q", html)
+	}
+}
+
+func TestBlockDOM2MdE(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetProtyleWYSIWYG(true)
+	luteEngine.SetKramdownIAL(true)
+
+	md, err := luteEngine.BlockDOM2MdE(context.Background(), "<div data-node-id=\"20060102150405-1a2b3c4\" data-type=\"NodeParagraph\" class=\"p\"><div contenteditable=\"true\" spellcheck=\"false\">foo</div><div class=\"protyle-attr\"></div></div>")
+	if nil != err || "foo\n{: id=\"20060102150405-1a2b3c4\"}\n" != md {
+		t.Fatalf("block DOM to markdown failed: %q %v", md, err)
+	}
+
+	md, err = luteEngine.BlockDOM2MdE(context.Background(), "<div data-type=\"NodeCodeBlock\" class=\"code-block\"><div class=\"protyle-action\"></div></div>")
+	if !errors.Is(err, lute.ErrInvalidDOM) {
+		t.Fatalf("invalid DOM expected, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = luteEngine.BlockDOM2TreeE(ctx, "<p>foo</p>")
+	if !errors.Is(err, lute.ErrCanceled) {
+		t.Fatalf("canceled expected, got %v", err)
+	}
+}
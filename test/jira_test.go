@@ -0,0 +1,75 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var md2JiraWikiTests = []parseTest{
+
+	{"5", "line one  \nline two\n\n- item  \n  break\n", "line one\nline two\n\n* item\\\\ break\n"},
+	{"4", "{braces} [brackets] a|b well-known -v and *-x-* Hello!\n\n\\- not a list\n\nh1\\. not a heading\n\n- end.\n", "\\{braces\\} \\[brackets\\] a\\|b well-known -v and _\\-x-_ Hello!\n\n\\- not a list\n\nh1\\. not a heading\n\n* end.\n"},
+	{"3", "| a | b |\n| - | - |\n| 1 | |\n\n![alt](a.png)\n\n---\n\nfoo[^1]\n\n[^1]: bar\n", "||a||b||\n|1| |\n\n!a.png|alt=alt!\n\n----\n\nfoo\\[1\\]\n\n----\n# bar\n"},
+	{"2", "```go\nfunc main() {}\n```\n\n> quote\n\n> [!TIP]\n> Tip.\n", "{code:go}\nfunc main() {}\n{code}\n\n{quote}\nquote\n{quote}\n\n{panel:title=Tip}\nTip.\n{panel}\n"},
+	{"1", "- one\n  - nested\n- two\n\n1. a\n   - b\n\n- [x] done\n- [ ] todo\n", "* one\n** nested\n* two\n\n# a\n#* b\n\n* (/) done\n* (x) todo\n"},
+	{"0", "# Title\n\nfoo **bar** *em* ~~del~~ `code` intra**word**s [link](https://b3log.org) [b3log.org](b3log.org)\n", "h1. Title\n\nfoo *bar* _em_ -del- {{code}} intra{*}word{*}s [link|https://b3log.org] [b3log.org]\n"},
+}
+
+func TestMd2JiraWiki(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range md2JiraWikiTests {
+		wiki := luteEngine.Md2JiraWiki(test.from)
+		if test.to != wiki {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, wiki, test.from)
+		}
+	}
+}
+
+var jiraWiki2MdTests = []parseTest{
+
+	{"5", "[https://b3log.org] [Lute|https://github.com/88250/lute] [#anchor] [~jdoe] [ABC-123] [mailto:a@b.c]\n!img.png! !https://x.com/a.png|alt=Logo, thumbnail! Hello! well-known a - b -- c {color:red}red{color}\n\n----\n\\*not bold\\*", "[https://b3log.org](https://b3log.org) [Lute](https://github.com/88250/lute) [anchor](#anchor) @jdoe [ABC-123] [a@b.c](mailto:a@b.c)\n![](img.png) ![Logo](https://x.com/a.png) Hello! well-known a - b -- c red\n\n---\n\n\\*not bold\\*\n"},
+	{"4", "||a||b||\n|1|[x|https://b3log.org]|\n|\\|pipe|two|", "| a     | b                      |\n| ----- | ---------------------- |\n| 1     | [x](https://b3log.org) |\n| \\|pipe | two                    |\n"},
+	{"3", "{quote}\nquoted\n\nagain\n{quote}\n\nbq. short quote\n\n{panel:title=Warning}\nCareful.\n{panel}\n\n{info}Informative{info}\n\n{panel:title=Custom}\nBody\n{panel}", "> quoted\n>\n> again\n\n> short quote\n\n> [!WARNING]\n> Careful.\n\n> [!NOTE]\n> Informative\n\n> **Custom**\n>\n> Body\n"},
+	{"2", "{code:java}\nclass A {}\n{code}\n\n{noformat}\nraw *text*\n{noformat}\n\n{code:title=A.go|language=go}x := 1{code}", "```java\nclass A {}\n```\n\n```\nraw *text*\n```\n\n```go\nx := 1\n```\n"},
+	{"1", "* one\n** nested\n* two\n# a\n#* b\n\n* (/) done\n* (x) todo", "- one\n  - nested\n- two\n\n1. a\n   - b\n\n- [X] done\n- [ ] todo\n"},
+	{"0", "h1. Title\n\nfoo *bar* _em_ -del- +under+ ^sup^ ~sub~ ??cite?? {{code}} intra{*}word{*}s\nsecond line\\\\third", "# Title\n\nfoo **bar** *em* ~~del~~ <u>under</u> ^sup^ ~sub~ *cite* `code` intra**word**s\nsecond line\nthird\n"},
+}
+
+func TestJiraWiki2Md(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range jiraWiki2MdTests {
+		md := luteEngine.JiraWiki2Md(test.from)
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal jira wiki text\n\t%q", test.name, test.to, md, test.from)
+		}
+	}
+}
+
+func TestJiraWikiRoundTrip(t *testing.T) {
+	luteEngine := lute.New()
+
+	md := "# Design\n\nSome **bold** and `code`.\n\n- [x] done\n- [ ] todo\n\n| a | b |\n| - | - |\n| 1 | 2 |\n\n```go\nfunc main() {}\n```\n"
+	result := luteEngine.JiraWiki2Md(luteEngine.Md2JiraWiki(md))
+	expected := "# Design\n\nSome **bold** and `code`.\n\n- [X] done\n- [ ] todo\n\n| a | b |\n| - | - |\n| 1 | 2 |\n\n```go\nfunc main() {}\n```\n"
+	if expected != result {
+		t.Fatalf("round trip failed\nexpected\n\t%q\ngot\n\t%q", expected, result)
+	}
+
+	html := luteEngine.JiraWiki2HTML("h2. Hi\n\n*bold* +u+")
+	if "<h2>Hi</h2>\n<p><strong>bold</strong> <u>u</u></p>\n" != html {
+		t.Fatalf("jira wiki to html failed, got %q", html)
+	}
+}
@@ -20,6 +20,7 @@ var JSONRendererTests = []parseTest{
 	{"测试普通文本", "普通文本测试", "[{\"flag\":\"Paragraph\",\"children\":[{\"type\":\"Text\",\"value\":\"普通文本测试\"}]}]"},
 	{"测试行内代码", "`console.log(\"Hello World\")`", "[{\"flag\":\"Paragraph\",\"children\":[{\"type\":\"CodeSpan\",\"value\":\"console.log(\\\"Hello World\\\")\"}]}]"},
 	{"测试代码块", "```js\nconsole.log(\"Hello World\")\n```\n", "[{\"type\":\"CodeBlock\",\"value\":\"console.log(\\\"Hello World\\\")\\n\",\"language\":\"js\"}]"},
+	{"测试缩进代码块", "\tfoo\n", "[{\"type\":\"CodeBlock\",\"value\":\"foo\\n\",\"language\":\"\"}]"},
 	{"测试数学块", "$$\na + b = c\n$$\n", "[{\"type\":\"MathBlock\",\"value\":\"a + b = c\"}]"},
 	{"测试行内数学公式", "$a + b = c$", "[{\"flag\":\"Paragraph\",\"children\":[{\"type\":\"InlineMath\",\"value\":\"a + b = c\"}]}]"},
 	{"测试斜体", "*测试斜体*", "[{\"flag\":\"Paragraph\",\"children\":[{\"flag\":\"Emphasis\",\"children\":[{\"type\":\"Text\",\"value\":\"测试斜体\"}]}]}]"},
@@ -0,0 +1,73 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var latexRendererTests = []parseTest{
+
+	{"9", "[^1]: note *here*\n\nfoo[^1] bar[^1]\n", "foo\\footnote{note \\emph{here}} bar\\footnotemark[1]\n"},
+	{"8", "![img](a.png) ![remote](https://b3log.org/a.png) [link](https://b3log.org/#a%20) <https://b3log.org>\n", "\\includegraphics[width=\\maxwidth]{a.png} \\href{https://b3log.org/a.png}{remote} \\href{https://b3log.org/\\#a\\%20}{link} \\url{https://b3log.org}\n"},
+	{"7", "|a|b|\n|:-|-:|\n|1|2|\n", "\\noindent\n\\begin{tabularx}{\\linewidth}{>{\\raggedright\\arraybackslash}X>{\\raggedleft\\arraybackslash}X}\n\\toprule\na & b \\\\\n\\midrule\n1 & 2 \\\\\n\\bottomrule\n\\end{tabularx}\n"},
+	{"6", "```python\nprint(1)\n```\n\n```go\nfmt.Println(1)\n```\n", "\\begin{lstlisting}[language=Python]\nprint(1)\n\\end{lstlisting}\n\n\\begin{lstlisting}\nfmt.Println(1)\n\\end{lstlisting}\n"},
+	{"5", "$$\n\\frac{1}{2}\n$$\n\n---\n\n<div>foo</div>\n", "\\[\n\\frac{1}{2}\n\\]\n\n\\begin{center}\\rule{0.5\\linewidth}{0.5pt}\\end{center}\n"},
+	{"4", "> foo\n>\n> bar\n", "\\begin{quote}\nfoo\n\nbar\n\\end{quote}\n"},
+	{"3", "3. a\n4. b\n   1. c\n", "\\begin{enumerate}\n\\setcounter{enumi}{2}\n\\item a\n\\item b\n\\begin{enumerate}\n\\item c\n\\end{enumerate}\n\\end{enumerate}\n"},
+	{"2", "- [x] done\n- [ ] todo\n", "\\begin{itemize}\n\\item[$\\boxtimes$] done\n\\item[$\\square$] todo\n\\end{itemize}\n"},
+	{"1", "*em* **strong** ~~del~~ `a_b` ==mark== ^sup^ ~sub~\nfoo  \nbar\n", "\\emph{em} \\textbf{strong} \\sout{del} \\texttt{a\\_b} \\hl{mark} \\textsuperscript{sup} \\textsubscript{sub}\nfoo\\newline\nbar\n"},
+	{"0", "# Hi $x^2$ & co_1\n\n#### 50% #1 ~\\\n\n{foo}\n", "\\section{Hi $x^2$ \\& co\\_1}\n\n\\paragraph{50\\% \\#1 \\textasciitilde{}\\textbackslash{}}\n\n\\{foo\\}\n"},
+}
+
+func TestLaTeXRenderer(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMark(true)
+	luteEngine.SetSup(true)
+	luteEngine.SetSub(true)
+
+	for _, test := range latexRendererTests {
+		latex := latexBody(luteEngine.Md2LaTeX(test.from))
+		if test.to != latex {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, latex, test.from)
+		}
+	}
+}
+
+func TestLaTeXRendererOptions(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetLaTeXDocumentClass("ctexbook")
+	luteEngine.SetLaTeXDocumentClassOptions("a4paper,12pt")
+	luteEngine.SetLaTeXPreamble("\\setCJKmainfont{Noto Serif CJK SC}\n")
+	luteEngine.SetLaTeXXeCJK(true)
+	luteEngine.SetLaTeXMinted(true)
+	luteEngine.SetLaTeXLongTable(true)
+
+	latex := luteEngine.Md2LaTeX("# 中文\n\n```go\nfmt.Println(1)\n```\n\n|a|b|\n|-|:-:|\n|1|2|\n")
+	for _, expected := range []string{"\\documentclass[a4paper,12pt]{ctexbook}\n", "\\usepackage{xeCJK}\n", "\\usepackage{minted}\n", "\\usepackage{longtable}\n", "\\setCJKmainfont{Noto Serif CJK SC}\n\n\\begin{document}"} {
+		if !strings.Contains(latex, expected) {
+			t.Fatalf("preamble should contain %q, got\n\t%q", expected, latex)
+		}
+	}
+	expected := "\\chapter{中文}\n\n\\begin{minted}{go}\nfmt.Println(1)\n\\end{minted}\n\n\\begin{longtable}{lc}\n\\toprule\na & b \\\\\n\\midrule\n\\endhead\n1 & 2 \\\\\n\\bottomrule\n\\end{longtable}\n"
+	if body := latexBody(latex); expected != body {
+		t.Fatalf("render with options failed\nexpected\n\t%q\ngot\n\t%q", expected, body)
+	}
+}
+
+// latexBody 返回 LaTeX 文档正文。
+func latexBody(latex string) string {
+	latex = latex[strings.Index(latex, "\\begin{document}\n\n")+len("\\begin{document}\n\n"):]
+	return strings.TrimSuffix(latex, "\n\\end{document}\n")
+}
@@ -0,0 +1,39 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var manRendererTests = []parseTest{
+
+	{"6", "# lute\n\n|a|b|\n|:-|-:|\n|1|2|\n", "'\\\" t\n.TH \"LUTE\" \"1\" \"\" \"\" \"\"\n.PP\n.TS\nallbox;\nlb rb\nl r.\nT{\na\nT}\tT{\nb\nT}\nT{\n1\nT}\tT{\n2\nT}\n.TE\n"},
+	{"5", "# lute\n\nSee <https://b3log.org> and [site](https://b3log.org/a-b) note[^1].  \nnext ***both*** 'quoted\n\n[^1]: The note.\n", ".TH \"LUTE\" \"1\" \"\" \"\" \"\"\n.PP\nSee \\%https://b3log.org and site <\\%https://b3log.org/a\\-b> note[1].\n.br\nnext \\fI\\f(BIboth\\fI\\fR 'quoted\n.SH NOTES\n.IP [1] 4\nThe note.\n"},
+	{"4", "# lute\n\n```sh\n.hidden \\n -x\n```\n\n> quote\n\n---\n", ".TH \"LUTE\" \"1\" \"\" \"\" \"\"\n.PP\n.RS 4\n.nf\n\\&.hidden \\en \\-x\n.fi\n.RE\n.RS 4\n.PP\nquote\n.RE\n.sp\n"},
+	{"3", "# lute\n\n### Lists\n\n1. one\n   - nested\n   - .dot at start\n2. two\n\n- [x] done\n- [ ] todo\n", ".TH \"LUTE\" \"1\" \"\" \"\" \"\"\n.SS \"Lists\"\n.IP 1. 4\none\n.RS\n.IP \\(bu 2\nnested\n.IP \\(bu 2\n\\&.dot at start\n.RE\n.IP 2. 4\ntwo\n.IP [x] 4\ndone\n.IP [\\ ] 4\ntodo\n"},
+	{"2", "# lute\n\n## Options\n\n* `-v`, `--verbose`:\n  Print *more* output.\n\n  Second para.\n* `--out` *file*\n  Write to file.\n", ".TH \"LUTE\" \"1\" \"\" \"\" \"\"\n.SH \"OPTIONS\"\n.TP\n\\fB\\-v\\fR, \\fB\\-\\-verbose\\fR\nPrint \\fImore\\fR output.\n.IP\nSecond para.\n.TP\n\\fB\\-\\-out\\fR \\fIfile\\fR\nWrite to file.\n"},
+	{"1", "# lute(1) -- a structured markdown engine\n\n## Synopsis\n\n`lute` [*options*] **file**...\n", ".TH \"LUTE\" \"1\" \"\" \"\" \"\"\n.SH NAME\nlute \\- a structured markdown engine\n.SH \"SYNOPSIS\"\n.PP\n\\fBlute\\fR [\\fIoptions\\fR] \\fBfile\\fR...\n"},
+	{"0", "---\nsection: 8\ndate: 2021-09-01\nsource: Lute 1.7\nmanual: Lute \"Manual\"\n---\n\n# lute(1)\n\nfoo\n", ".TH \"LUTE\" \"8\" \"2021\\-09\\-01\" \"Lute 1.7\" \"Lute \\(dqManual\\(dq\"\n.PP\nfoo\n"},
+}
+
+func TestManRenderer(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range manRendererTests {
+		man := luteEngine.Md2Man(test.from)
+		if test.to != man {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, man, test.from)
+		}
+	}
+}
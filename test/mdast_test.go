@@ -0,0 +1,139 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var md2MdastTests = []parseTest{
+
+	{"9", "==mark==\n", "{\"type\":\"root\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"luteMark\",\"children\":[{\"type\":\"luteMark2OpenMarker\",\"value\":\"==\"},{\"type\":\"text\",\"value\":\"mark\"},{\"type\":\"luteMark2CloseMarker\",\"value\":\"==\"}]}]}]}"},
+	{"8", "---\ntitle: foo\n---\n", "{\"type\":\"root\",\"children\":[{\"type\":\"yaml\",\"value\":\"title: foo\"}]}"},
+	{"7", "foo[^1]\n\n[^1]: bar\n", "{\"type\":\"root\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"foo\"},{\"type\":\"footnoteReference\",\"identifier\":\"1\",\"label\":\"1\"}]},{\"type\":\"footnoteDefinition\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"bar\"}]}],\"identifier\":\"1\",\"label\":\"1\"}]}"},
+	{"6", "$$\nx\n$$\n\n$y$\n", "{\"type\":\"root\",\"children\":[{\"type\":\"math\",\"value\":\"x\"},{\"type\":\"paragraph\",\"children\":[{\"type\":\"inlineMath\",\"value\":\"y\"}]}]}"},
+	{"5", "| a | b |\n|:-|-|\n| 1 | 2 |\n", "{\"type\":\"root\",\"children\":[{\"type\":\"table\",\"children\":[{\"type\":\"tableRow\",\"children\":[{\"type\":\"tableCell\",\"children\":[{\"type\":\"text\",\"value\":\"a\"}]},{\"type\":\"tableCell\",\"children\":[{\"type\":\"text\",\"value\":\"b\"}]}]},{\"type\":\"tableRow\",\"children\":[{\"type\":\"tableCell\",\"children\":[{\"type\":\"text\",\"value\":\"1\"}]},{\"type\":\"tableCell\",\"children\":[{\"type\":\"text\",\"value\":\"2\"}]}]}],\"align\":[\"left\",null]}]}"},
+	{"4", "- [x] foo\n- [ ] bar\n", "{\"type\":\"root\",\"children\":[{\"type\":\"list\",\"children\":[{\"type\":\"listItem\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"foo\"}]}],\"spread\":false,\"checked\":true},{\"type\":\"listItem\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"bar\"}]}],\"spread\":false,\"checked\":false}],\"ordered\":false,\"spread\":false}]}"},
+	{"3", "```go linenos\nfunc main() {}\n```\n", "{\"type\":\"root\",\"children\":[{\"type\":\"code\",\"value\":\"func main() {}\",\"lang\":\"go\",\"meta\":\"linenos\"}]}"},
+	{"2", "[foo](/bar \"baz\") ![img](/img.png)\n", "{\"type\":\"root\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"link\",\"children\":[{\"type\":\"text\",\"value\":\"foo\"}],\"url\":\"/bar\",\"title\":\"baz\"},{\"type\":\"text\",\"value\":\" \"},{\"type\":\"image\",\"url\":\"/img.png\",\"alt\":\"img\"}]}]}"},
+	{"1", "foo\nbar *baz* \\*\n", "{\"type\":\"root\",\"children\":[{\"type\":\"paragraph\",\"children\":[{\"type\":\"text\",\"value\":\"foo\\nbar \"},{\"type\":\"emphasis\",\"children\":[{\"type\":\"text\",\"value\":\"baz\"}]},{\"type\":\"text\",\"value\":\" *\"}]}]}"},
+	{"0", "# foo\n", "{\"type\":\"root\",\"children\":[{\"type\":\"heading\",\"children\":[{\"type\":\"text\",\"value\":\"foo\"}],\"depth\":1}]}"},
+}
+
+func TestMd2Mdast(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMark(true)
+
+	for _, test := range md2MdastTests {
+		mdast := luteEngine.Md2Mdast(test.from)
+		if test.to != mdast {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, mdast, test.from)
+		}
+	}
+}
+
+var mdastRoundTripTests = []string{
+	"# foo {#bar}\n\nSetext\n===\n",
+	"> **bold** _em_ ~~del~~ `co``de` [link](/url \"title\") ![img *alt*](/img.png)\n",
+	"foo  \nbar\nbaz &amp; \\* :smile:\n",
+	"3. foo\n4. bar\n\n- a\n\n  b\n- c\n",
+	"- [x] done\n- [ ] todo\n",
+	"    indented\n\n```go meta\ncode\n```\n\n````\n```\n````\n",
+	"$$\nmath\n$$\n\n$x$\n",
+	"| a | b | c |\n|:-|:-:|-:|\n| 1 | 2 | 3 |\n",
+	"<div>\nhtml\n</div>\n\n<b>inline</b>\n",
+	"[foo][1]\n\n[1]: /ref \"title\"\n",
+	"foo[^1] bar[^1]\n\n[^1]: footnote\n",
+	"==mark==\n",
+	"***\n",
+}
+
+func TestMdastRoundTrip(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMark(true)
+
+	for _, md := range mdastRoundTripTests {
+		expected := luteEngine.MarkdownStr("", md)
+		html, err := luteEngine.Mdast2HTML(luteEngine.Md2Mdast(md))
+		if nil != err {
+			t.Fatalf("import mdast failed: %s", err)
+		}
+		if expected != html {
+			t.Fatalf("mdast round trip failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", expected, html, md)
+		}
+	}
+}
+
+// remarkMdast 是 remark 解析 "[foo][bar]\n\n![baz][bar]\n\n[bar]: /url\n" 并去掉 position 后的结果。
+const remarkMdast = `{"type":"root","children":[
+{"type":"paragraph","children":[{"type":"linkReference","identifier":"bar","label":"bar","referenceType":"full","children":[{"type":"text","value":"foo"}]}]},
+{"type":"paragraph","children":[{"type":"imageReference","identifier":"bar","label":"bar","referenceType":"full","alt":"baz"}]},
+{"type":"definition","identifier":"bar","label":"bar","url":"/url","title":null},
+{"type":"paragraph","children":[{"type":"linkReference","identifier":"missing","label":"missing","referenceType":"shortcut","children":[{"type":"text","value":"missing"}]},{"type":"footnoteReference","identifier":"none","label":"none"}]}
+]}`
+
+func TestMdast2HTML(t *testing.T) {
+	luteEngine := lute.New()
+
+	html, err := luteEngine.Mdast2HTML(remarkMdast)
+	if nil != err {
+		t.Fatalf("import mdast failed: %s", err)
+	}
+	expected := "<p><a href=\"/url\">foo</a></p>\n<p><img src=\"/url\" alt=\"baz\" /></p>\n<p>missing[^none]</p>\n"
+	if expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+
+	if _, err = luteEngine.Mdast2HTML(`{"type":"paragraph"}`); nil == err {
+		t.Fatalf("non-root mdast should be rejected")
+	}
+	if _, err = luteEngine.Mdast2HTML(`{"type":"root","children":[{"type":"luteFoo"}]}`); nil == err {
+		t.Fatalf("unknown lute node type should be rejected")
+	}
+}
+
+func TestMdast2Md(t *testing.T) {
+	luteEngine := lute.New()
+
+	md, err := luteEngine.Mdast2Md(`{"type":"root","children":[{"type":"heading","depth":2,"children":[{"type":"text","value":"foo"}]},{"type":"list","ordered":true,"start":1,"spread":false,"children":[{"type":"listItem","spread":false,"children":[{"type":"paragraph","children":[{"type":"strong","children":[{"type":"text","value":"bar"}]}]}]}]}]}`)
+	if nil != err {
+		t.Fatalf("import mdast failed: %s", err)
+	}
+	if expected := "## foo\n\n1. **bar**\n"; expected != md {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, md)
+	}
+}
+
+func TestMdastKramdownIALRoundTrip(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+
+	md := "- {: id=\"20210101000000-aaaaaaa\"}a\n  {: id=\"20210101000000-bbbbbbb\"}\n- {: id=\"20210101000000-ccccccc\"}b\n{: id=\"20210101000000-ddddddd\"}\n\n> q\n> {: id=\"20210101000000-eeeeeee\"}\n{: id=\"20210101000000-fffffff\" custom-a=\"b\"}\n\nfoo\n{: id=\"20210101000000-ggggggg\"}\n\n\n{: id=\"20210101000000-hhhhhhh\" type=\"doc\"}\n"
+	mdast := luteEngine.Md2Mdast(md)
+	if strings.Contains(mdast, "luteKramdownBlockIAL") {
+		t.Fatalf("block IAL should be exported as data only, got\n\t%s", mdast)
+	}
+	if !strings.Contains(mdast, `"kramdownIAL":[["id","20210101000000-hhhhhhh"],["type","doc"]]`) {
+		t.Fatalf("doc IAL should be exported on root, got\n\t%s", mdast)
+	}
+
+	expected := luteEngine.FormatStr("", md)
+	got, err := luteEngine.Mdast2Md(mdast)
+	if nil != err {
+		t.Fatalf("import mdast failed: %s", err)
+	}
+	if expected != got {
+		t.Fatalf("mdast round trip failed\nexpected\n\t%q\ngot\n\t%q", expected, got)
+	}
+}
@@ -0,0 +1,65 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+)
+
+type mergeTest struct {
+	name      string
+	base      string
+	ours      string
+	theirs    string
+	to        string
+	conflicts int
+}
+
+var mergeTests = []mergeTest{
+
+	{"6", "foo\n\nbar\n", "foo1\n\nbar\n", "foo2\n\nbar\n", "<<<<<<< ours\nfoo1\n=======\nfoo2\n>>>>>>> theirs\nbar\n\n\n{: id=\"20210101000000-ddddocc\" type=\"doc\"}\n", 1},
+	{"5", "foo bar baz\n\nqux\n", "foo bar1 baz\n\nqux\n", "foo bar baz2\n\nqux\n", "foo bar1 baz2\n\nqux\n\n\n{: id=\"20210101000000-ddddocc\" type=\"doc\"}\n", 0},
+	{"4", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n\nbar\n{: id=\"20210101000000-bbbbbbb\"}\n", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n\nbar changed\n{: id=\"20210101000000-bbbbbbb\"}\n", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n\n<<<<<<< ours\n=======\nbar changed\n{: id=\"20210101000000-bbbbbbb\"}\n>>>>>>> theirs\n\n{: id=\"20210101000000-ddddocc\" type=\"doc\"}\n", 1},
+	{"3", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n\nbar\n{: id=\"20210101000000-bbbbbbb\"}\n", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n", "foo\n{: id=\"20210101000000-aaaaaaa\" style=\"color: red\"}\n\nbar\n{: id=\"20210101000000-bbbbbbb\"}\n", "foo\n{: id=\"20210101000000-aaaaaaa\" style=\"color: red\"}\n\n\n{: id=\"20210101000000-ddddocc\" type=\"doc\"}\n", 0},
+	{"2", "foo bar\n{: id=\"20210101000000-aaaaaaa\"}\n", "foo baz\n{: id=\"20210101000000-aaaaaaa\"}\n", "foo qux\n{: id=\"20210101000000-aaaaaaa\"}\n", "<<<<<<< ours\nfoo baz\n{: id=\"20210101000000-aaaaaaa\"}\n=======\nfoo qux\n{: id=\"20210101000000-aaaaaaa\"}\n>>>>>>> theirs\n\n{: id=\"20210101000000-ddddocc\" type=\"doc\"}\n", 1},
+	{"1", "foo bar baz\n{: id=\"20210101000000-aaaaaaa\"}\n\nqux\n{: id=\"20210101000000-bbbbbbb\"}\n", "foo bar1 baz\n{: id=\"20210101000000-aaaaaaa\"}\n\nqux\n{: id=\"20210101000000-bbbbbbb\"}\n\nours\n{: id=\"20210101000000-ccccccc\"}\n", "theirs\n{: id=\"20210101000000-ddddddd\"}\n\nfoo bar baz2\n{: id=\"20210101000000-aaaaaaa\"}\n\nqux\n{: id=\"20210101000000-bbbbbbb\"}\n", "theirs\n{: id=\"20210101000000-ddddddd\"}\n\nfoo bar1 baz2\n{: id=\"20210101000000-aaaaaaa\"}\n\nqux\n{: id=\"20210101000000-bbbbbbb\"}\n\nours\n{: id=\"20210101000000-ccccccc\"}\n\n\n{: id=\"20210101000000-ddddocc\" type=\"doc\"}\n", 0},
+	{"0", "# Title\n\nfoo\n\nbar\n", "# Title\n\nfoo\n\nbar\n\nbaz\n", "# Title\n\nbar\n", "# Title\n\nbar\n\nbaz\n\n\n{: id=\"20210101000000-ddddocc\" type=\"doc\"}\n", 0},
+}
+
+const mergeDocIAL = "\n{: id=\"20210101000000-ddddocc\" type=\"doc\"}\n"
+
+func TestMerge(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetKramdownBlockIAL(true)
+
+	for _, test := range mergeTests {
+		md, conflicts := luteEngine.MergeMarkdown(test.base+mergeDocIAL, test.ours+mergeDocIAL, test.theirs+mergeDocIAL)
+		if test.to != md {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, md)
+		}
+		if test.conflicts != conflicts {
+			t.Fatalf("test case [%s] failed\nexpected conflicts [%d], got [%d]", test.name, test.conflicts, conflicts)
+		}
+	}
+}
+
+func TestMergeText(t *testing.T) {
+	if merged, ok := parse.MergeText("foo bar baz", "foo 中文 bar baz", "foo bar qux"); !ok || "foo 中文 bar qux" != merged {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", "foo 中文 bar qux", merged)
+	}
+	if _, ok := parse.MergeText("foo bar", "foo baz", "foo qux"); ok {
+		t.Fatalf("expected conflict")
+	}
+}
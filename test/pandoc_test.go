@@ -0,0 +1,116 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var md2PandocJSONTests = []parseTest{
+
+	{"4", "==mark==\n", "{\"blocks\":[{\"t\":\"Para\",\"c\":[{\"t\":\"Span\",\"c\":[[\"\",[\"lute\"],[[\"lute-type\",\"Mark\"]]],[{\"t\":\"Span\",\"c\":[[\"\",[\"lute\"],[[\"lute-type\",\"Mark2OpenMarker\"],[\"lute-tokens\",\"==\"]]],[]]},{\"t\":\"Str\",\"c\":\"mark\"},{\"t\":\"Span\",\"c\":[[\"\",[\"lute\"],[[\"lute-type\",\"Mark2CloseMarker\"],[\"lute-tokens\",\"==\"]]],[]]}]]}]}],\"meta\":{},\"pandoc-api-version\":[1,22,2,1]}"},
+	{"3", "foo[^1]\n\n[^1]: bar\n", "{\"blocks\":[{\"t\":\"Para\",\"c\":[{\"t\":\"Str\",\"c\":\"foo\"},{\"t\":\"Note\",\"c\":[{\"t\":\"Para\",\"c\":[{\"t\":\"Str\",\"c\":\"bar\"}]}]}]}],\"meta\":{},\"pandoc-api-version\":[1,22,2,1]}"},
+	{"2", "2) [x] foo\n3) [ ] bar\n", "{\"blocks\":[{\"t\":\"OrderedList\",\"c\":[[2,{\"t\":\"Decimal\"},{\"t\":\"OneParen\"}],[[{\"t\":\"Plain\",\"c\":[{\"t\":\"Str\",\"c\":\"☒\"},{\"t\":\"Space\"},{\"t\":\"Str\",\"c\":\"foo\"}]}],[{\"t\":\"Plain\",\"c\":[{\"t\":\"Str\",\"c\":\"☐\"},{\"t\":\"Space\"},{\"t\":\"Str\",\"c\":\"bar\"}]}]]]}],\"meta\":{},\"pandoc-api-version\":[1,22,2,1]}"},
+	{"1", "foo *bar* `baz`\n$$\nx\n$$\n", "{\"blocks\":[{\"t\":\"Para\",\"c\":[{\"t\":\"Str\",\"c\":\"foo\"},{\"t\":\"Space\"},{\"t\":\"Emph\",\"c\":[{\"t\":\"Str\",\"c\":\"bar\"}]},{\"t\":\"Space\"},{\"t\":\"Code\",\"c\":[[\"\",[],[]],\"baz\"]}]},{\"t\":\"Para\",\"c\":[{\"t\":\"Math\",\"c\":[{\"t\":\"DisplayMath\"},\"x\"]}]}],\"meta\":{},\"pandoc-api-version\":[1,22,2,1]}"},
+	{"0", "# foo {#bar}\n\n```go linenos\ncode\n```\n", "{\"blocks\":[{\"t\":\"Header\",\"c\":[1,[\"bar\",[],[]],[{\"t\":\"Str\",\"c\":\"foo\"}]]},{\"t\":\"CodeBlock\",\"c\":[[\"\",[\"go\",\"linenos\"],[]],\"code\"]}],\"meta\":{},\"pandoc-api-version\":[1,22,2,1]}"},
+}
+
+func TestMd2PandocJSON(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMark(true)
+
+	for _, test := range md2PandocJSONTests {
+		pandocJSON := luteEngine.Md2PandocJSON(test.from)
+		if test.to != pandocJSON {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, pandocJSON, test.from)
+		}
+	}
+}
+
+var pandocJSONRoundTripTests = []string{
+	"# foo {#bar}\n\nSetext\n===\n",
+	"> **bold** _em_ ~~del~~ `co``de` [link](/url \"title\") ![img *alt*](/img.png)\n",
+	"foo  \nbar\nbaz &amp; \\* :smile: ^sup^ ~sub~\n",
+	"3. foo\n4. bar\n\n- a\n\n  b\n- c\n",
+	"- [x] done\n- [ ] todo\n",
+	"    indented\n\n```go meta\ncode\n```\n\n````\n```\n````\n",
+	"$$\nmath\n$$\n\n$x$\n",
+	"| a | b | c |\n|:-|:-:|-:|\n| 1 | *2* | 3 |\n",
+	"<div>\nhtml\n</div>\n\n<b>inline</b>\n",
+	"[foo][1]\n\n[1]: /ref \"title\"\n",
+	"foo[^1] bar[^2]\n\n[^1]: footnote\n[^2]: > quote\n",
+	"==mark== #tag#\n",
+	"{{{row\nfoo\n\nbar\n}}}\n",
+	"***\n",
+}
+
+func TestPandocJSONRoundTrip(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMark(true)
+	luteEngine.SetSup(true)
+	luteEngine.SetSub(true)
+	luteEngine.SetTag(true)
+	luteEngine.SetSuperBlock(true)
+
+	for _, md := range pandocJSONRoundTripTests {
+		expected := luteEngine.MarkdownStr("", md)
+		html, err := luteEngine.PandocJSON2HTML(luteEngine.Md2PandocJSON(md))
+		if nil != err {
+			t.Fatalf("import pandoc json failed: %s", err)
+		}
+		if expected != html {
+			t.Fatalf("pandoc json round trip failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", expected, html, md)
+		}
+	}
+}
+
+func TestPandocJSONKramdownIAL(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetKramdownBlockIAL(true)
+
+	md := "foo\n{: id=\"20210101000000-abcdefg\" style=\"color: red\"}\n\n- {: id=\"20210101000000-hijklmn\"}bar\n{: id=\"20210101000000-opqrstu\"}\n"
+	expected := luteEngine.MarkdownStr("", md)
+	html, err := luteEngine.PandocJSON2HTML(luteEngine.Md2PandocJSON(md))
+	if nil != err {
+		t.Fatalf("import pandoc json failed: %s", err)
+	}
+	if expected != html {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+// pandocJSON 是 pandoc -f markdown -t json 转换 "Hello *\"world\"*[^1]\n\nTerm\n:   Definition\n\n[^1]: Note\n" 的结果。
+const pandocJSON = `{"pandoc-api-version":[1,22,2,1],"meta":{},"blocks":[
+{"t":"Para","c":[{"t":"Str","c":"Hello"},{"t":"Space"},{"t":"Emph","c":[{"t":"Quoted","c":[{"t":"DoubleQuote"},[{"t":"Str","c":"world"}]]}]},{"t":"Note","c":[{"t":"Para","c":[{"t":"Str","c":"Note"}]}]}]},
+{"t":"DefinitionList","c":[[[{"t":"Str","c":"Term"}],[[{"t":"Plain","c":[{"t":"Str","c":"Definition"}]}]]]]}
+]}`
+
+func TestPandocJSON2Md(t *testing.T) {
+	luteEngine := lute.New()
+
+	md, err := luteEngine.PandocJSON2Md(pandocJSON)
+	if nil != err {
+		t.Fatalf("import pandoc json failed: %s", err)
+	}
+	if expected := "Hello *\"world\"*[^1]\n\nTerm\n\nDefinition\n\n[^1]: Note\n"; expected != md {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, md)
+	}
+
+	if _, err = luteEngine.PandocJSON2Md(`{"pandoc-api-version":[1,17],"blocks":[]}`); nil == err {
+		t.Fatalf("old pandoc api version should be rejected")
+	}
+	if _, err = luteEngine.PandocJSON2Md(`{"pandoc-api-version":[1,22],"blocks":[{"t":"Div","c":[["",["lute"],[["lute-type","Foo"]]],[]]}]}`); nil == err {
+		t.Fatalf("unknown lute node type should be rejected")
+	}
+}
@@ -0,0 +1,129 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/parse"
+)
+
+var maxNestingDepthTests = []parseTest{
+
+	{"3", "* foo\n  * bar\n    * baz\n", "<ul>\n<li>foo\n<ul>\n<li>bar<br />\n* baz</li>\n</ul>\n</li>\n</ul>\n"},
+	{"2", "> > > foo\n", "<blockquote>\n<blockquote>\n<p>&gt; foo</p>\n</blockquote>\n</blockquote>\n"},
+	{"1", "> > foo\n", "<blockquote>\n<blockquote>\n<p>foo</p>\n</blockquote>\n</blockquote>\n"},
+	{"0", "> foo\n", "<blockquote>\n<p>foo</p>\n</blockquote>\n"},
+}
+
+func TestMaxNestingDepth(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxNestingDepth(2)
+
+	for _, test := range maxNestingDepthTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var maxDelimitersTests = []parseTest{
+
+	{"3", "[[[foo](bar)\n", "<p>[<a href=\"bar\">[foo</a></p>\n"},
+	{"2", "**foo** *bar*\n", "<p><strong>foo</strong> *bar*</p>\n"},
+	{"1", "*foo *bar *baz*\n", "<p>*foo *bar *baz*</p>\n"},
+	{"0", "*foo*\n", "<p><em>foo</em></p>\n"},
+}
+
+func TestMaxDelimiters(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxDelimiters(2)
+
+	for _, test := range maxDelimitersTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var maxInputSizeTests = []parseTest{
+
+	{"2", "中文**粗体**\n", "<p>中文**</p>\n<p>粗体**</p>\n"},
+	{"1", "foo\n**bar**\n", "<p>foo</p>\n<p>**bar**</p>\n"},
+	{"0", "*foo*\n", "<p><em>foo</em></p>\n"},
+}
+
+func TestMaxInputSize(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxInputSize(8)
+
+	for _, test := range maxInputSizeTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+var maxLinkRefDefsTests = []parseTest{
+
+	{"1", "[foo]: /a\n[bar]: /b\n\n[foo] [bar]\n", "<p>[bar]: /b</p>\n<p><a href=\"/a\">foo</a> [bar]</p>\n"},
+	{"0", "[foo]: /a\n\n[foo]\n", "<p><a href=\"/a\">foo</a></p>\n"},
+}
+
+func TestMaxLinkRefDefs(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMaxLinkRefDefs(1)
+
+	for _, test := range maxLinkRefDefsTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestLimitExceeded(t *testing.T) {
+	options := parse.NewOptions()
+	options.MaxNestingDepth = 64
+	tree := parse.Parse("", []byte(strings.Repeat(">", 100000)+" foo\n"), options)
+	if !tree.Context.LimitExceeded() {
+		t.Fatalf("limit exceeded expected")
+	}
+
+	tree = parse.Parse("", []byte("> foo\n"), options)
+	if tree.Context.LimitExceeded() {
+		t.Fatalf("limit exceeded unexpected")
+	}
+}
+
+func TestParseWithContext(t *testing.T) {
+	options := parse.NewOptions()
+	tree, err := parse.ParseWithContext(context.Background(), "", []byte("*foo*\n"), options)
+	if nil != err || "foo" != tree.Root.Text() {
+		t.Fatalf("parse with context failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tree, err = parse.ParseWithContext(ctx, "", []byte("*foo*\n\n**bar**\n"), options)
+	if context.Canceled != err {
+		t.Fatalf("context canceled expected, got %v", err)
+	}
+	if nil == tree.Root.FirstChild || "*foo*" != tree.Root.FirstChild.Text() {
+		t.Fatalf("unparsed text expected")
+	}
+}
@@ -0,0 +1,90 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var presetTests = []struct {
+	preset string
+	from   string
+	to     string
+}{
+	{"commonmark", "~~foo~~ :heart:\nbar\n", "<p>~~foo~~ :heart:\nbar</p>\n"},
+	{"gfm", "~~foo~~ :heart:\nbar\n", "<p><del>foo</del> ❤️\nbar</p>\n"},
+	{"vditor", "==foo==\n", "<p><mark>foo</mark></p>\n"},
+	{"siyuan", "^foo^ ~bar~\n", "<p><sup>foo</sup> <sub>bar</sub></p>\n"},
+	{"protyle", "==foo==\n", "<p><mark>foo</mark></p>\n"},
+}
+
+func TestPreset(t *testing.T) {
+	for _, test := range presetTests {
+		luteEngine, err := lute.NewWithPreset(test.preset)
+		if nil != err {
+			t.Fatalf("new with preset [%s] failed: %s", test.preset, err)
+		}
+		html := luteEngine.MarkdownStr("", test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.preset, test.to, html, test.from)
+		}
+	}
+
+	if _, err := lute.NewWithPreset("foo"); nil == err {
+		t.Fatalf("unknown preset should fail")
+	}
+}
+
+func TestOptionsJSON(t *testing.T) {
+	luteEngine, err := lute.NewWithOptionsJSON([]byte(`{"preset": "gfm", "parse": {"GFMStrikethrough": false}, "render": {"AutoSpace": true}}`))
+	if nil != err {
+		t.Fatalf("new with options failed: %s", err)
+	}
+	if html := luteEngine.MarkdownStr("", "~~foo~~ bar中文\nbaz\n"); "<p>~~foo~~ bar 中文\nbaz</p>\n" != html {
+		t.Fatalf("options JSON failed, got %q", html)
+	}
+
+	data, err := luteEngine.MarshalOptionsJSON()
+	if nil != err {
+		t.Fatalf("marshal options failed: %s", err)
+	}
+	engine, err := lute.NewWithOptionsJSON(data)
+	if nil != err {
+		t.Fatalf("new with options failed: %s", err)
+	}
+	if data2, _ := engine.MarshalOptionsJSON(); string(data) != string(data2) {
+		t.Fatalf("options JSON round trip failed\nexpected\n\t%s\ngot\n\t%s", data, data2)
+	}
+}
+
+func TestOptionsYAML(t *testing.T) {
+	luteEngine, err := lute.NewWithOptionsYAML([]byte("preset: commonmark\nparse:\n  GFMStrikethrough: true\n"))
+	if nil != err {
+		t.Fatalf("new with options failed: %s", err)
+	}
+	if html := luteEngine.MarkdownStr("", "~~foo~~ :heart:\n"); "<p><del>foo</del> :heart:</p>\n" != html {
+		t.Fatalf("options YAML failed, got %q", html)
+	}
+
+	data, err := luteEngine.MarshalOptionsYAML()
+	if nil != err {
+		t.Fatalf("marshal options failed: %s", err)
+	}
+	engine, err := lute.NewWithOptionsYAML(data)
+	if nil != err {
+		t.Fatalf("new with options failed: %s", err)
+	}
+	if data2, _ := engine.MarshalOptionsYAML(); string(data) != string(data2) {
+		t.Fatalf("options YAML round trip failed\nexpected\n\t%s\ngot\n\t%s", data, data2)
+	}
+}
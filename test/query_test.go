@@ -0,0 +1,71 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+type queryTest struct {
+	name     string
+	from     string
+	selector string
+	to       string
+}
+
+var queryTests = []queryTest{
+
+	{"11", "# foo\n\n## bar\n\nbaz\n", "heading[level=2], paragraph", "Heading:bar|Paragraph:baz"},
+	{"10", "[a](https://b3log.org) [b](/foo)\n", "link[dest^=https]", "Link:a"},
+	{"9", "```go\nfoo\n```\n\n```js\nbar\n```\n", "codeBlock[lang=js]", "CodeBlock:"},
+	{"8", "foo\n{: custom-priority=\"high\"}\n\nbar\n{: custom-priority=\"low\"}\n\nbaz\n", "paragraph[custom-priority]:not([custom-priority=low])", "Paragraph:foo"},
+	{"7", "- foo\n  - bar\n- baz\n", "document > list > listItem > paragraph", "Paragraph:foo|Paragraph:baz"},
+	{"6", "> foo **bar**\n\nbar\n", "blockquote strong:contains(bar)", "Strong:bar"},
+	{"5", "- foo\n- bar\n- baz\n", "listItem:first-child, listItem:last-child", "ListItem:foo|ListItem:baz"},
+	{"4", "# foo\n\nbar *baz*\n", "paragraph > :first-child", "Text:bar "},
+	{"3", "- [x] foo\n- [ ] bar\n\n- baz\n", "listItem[checked]", "ListItem: foo"},
+	{"2", "- foo\n  - bar\n", "listItem:has(> paragraph:contains(bar))", "ListItem:bar"},
+	{"1", "- [x] foo\n- [ ] bar\n- [x] baz\n", "list > listItem:has(taskListItemMarker[checked]) paragraph", "Paragraph: foo|Paragraph: baz"},
+	{"0", "foo\n\n> bar\n", "paragraph", "Paragraph:foo|Paragraph:bar"},
+}
+
+func TestQuery(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+	luteEngine.SetKramdownBlockIAL(true)
+
+	for _, test := range queryTests {
+		tree := parse.Parse("", []byte(test.from), luteEngine.ParseOptions)
+		var results []string
+		for _, n := range ast.Query(tree.Root, test.selector) {
+			results = append(results, strings.TrimPrefix(n.Type.String(), "Node")+":"+n.Text())
+		}
+		if result := strings.Join(results, "|"); test.to != result {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\nselector\n\t%q", test.name, test.to, result, test.selector)
+		}
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	for _, selector := range []string{"", "foo", "paragraph >", "paragraph[", "paragraph[a~=b]", ":unknown", "paragraph:has(text", "[a=\"b]"} {
+		if _, err := ast.ParseSelector(selector); nil == err {
+			t.Fatalf("invalid selector [%s] should be rejected", selector)
+		}
+	}
+	if nil != ast.Query(&ast.Node{Type: ast.NodeDocument}, "foo") {
+		t.Fatalf("query with invalid selector should return nil")
+	}
+}
@@ -0,0 +1,95 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+	"github.com/88250/lute/render"
+)
+
+// upperRenderer 用于测试注册自定义输出格式，将文本渲染为大写。
+type upperRenderer struct {
+	*render.BaseRenderer
+}
+
+func newUpperRenderer(tree *parse.Tree, options *render.Options) render.Renderer {
+	ret := &upperRenderer{render.NewBaseRenderer(tree, options)}
+	ret.DefaultRendererFunc = func(n *ast.Node, entering bool) ast.WalkStatus {
+		if entering && ast.NodeText == n.Type {
+			ret.WriteString(strings.ToUpper(n.TokensStr()))
+		}
+		return ast.WalkContinue
+	}
+	return ret
+}
+
+type registryTest struct {
+	name   string
+	format string
+	from   string
+	to     string
+}
+
+var registryTests = []registryTest{
+
+	{"4", "markdown", "*foo* bar", "*foo* bar\n"},
+	{"3", "json", "foo", "[{\"flag\":\"Paragraph\",\"children\":[{\"type\":\"Text\",\"value\":\"foo\"}]}]"},
+	{"2", "html", "*foo* bar", "<p><em>foo</em> bar</p>\n"},
+	{"1", "upper", "*foo* bar", "FOO BAR"},
+	{"0", "upper-ext", "*foo* bar", "FOO[bar]"},
+}
+
+func TestRender(t *testing.T) {
+	render.Register("upper", newUpperRenderer)
+	render.Register("upper-ext", newUpperRenderer)
+	defer render.Register("upper", nil)
+	defer render.Register("upper-ext", nil)
+
+	luteEngine := lute.New()
+	luteEngine.ExtRendererFuncs["upper-ext"] = map[ast.NodeType]render.ExtRendererFunc{
+		ast.NodeText: func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+			if !entering || "bar" != strings.TrimSpace(n.TokensStr()) {
+				return "", ast.WalkContinue
+			}
+			return "[bar]", ast.WalkContinue
+		},
+		ast.NodeEmphasis: func(n *ast.Node, entering bool) (string, ast.WalkStatus) {
+			if entering {
+				return "FOO", ast.WalkSkipChildren
+			}
+			return "", ast.WalkContinue
+		},
+	}
+
+	for _, test := range registryTests {
+		if output := luteEngine.Render(test.format, test.from); test.to != output {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q", test.name, test.to, output)
+		}
+	}
+
+	formats := strings.Join(luteEngine.Formats(), " ")
+	if !strings.Contains(formats, "html") || !strings.Contains(formats, "upper") {
+		t.Fatalf("registered formats [%s] should contain html and upper", formats)
+	}
+	if output := luteEngine.Render("unknown", "foo"); "" != output {
+		t.Fatalf("unknown format expected empty output, got [%s]", output)
+	}
+	if _, err := luteEngine.RenderE(context.Background(), "unknown", "foo"); !errors.Is(err, lute.ErrUnknownFormat) {
+		t.Fatalf("expected unknown format error, got [%v]", err)
+	}
+}
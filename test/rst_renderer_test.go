@@ -0,0 +1,68 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var rstRendererTests = []parseTest{
+
+	{"8", "foo[^1] bar[^1]\n\n[^1]: note\n\n    more\n", "foo\\ [#fn1]_ bar\\ [#fn1]_\n\n.. [#fn1] note\n\n   more\n"},
+	{"7", "[ext](https://b3log.org) [doc](guide/install.md) [sec](guide/install.md#linux) [anchor](#sec) <https://ld246.com>\n", "`ext <https://b3log.org>`__ :doc:`doc <guide/install>` `sec <guide/install.html#linux>`__ :ref:`anchor <sec>` https://ld246.com\n"},
+	{"6", "```go\nfmt.Println(1)\n```\n\n```\nfoo\n```\n\n$$\n\\sum\n$$\n\nfoo $x^2$\n", ".. code-block:: go\n\n   fmt.Println(1)\n\n::\n\n   foo\n\n.. math::\n\n   \\sum\n\nfoo :math:`x^2`\n"},
+	{"5", "|a|b\\|c|\n|:-:|-:|\n|1||\n", ".. list-table::\n   :header-rows: 1\n\n   * - a\n     - b\\|c\n   * - 1\n     -\n"},
+	{"4", "- a\n- [x] b\n  ```go\n  code\n  ```\n  - c\n- d\n\n3. one\n4. two\n", "- a\n\n- [x] b\n\n  .. code-block:: go\n\n     code\n\n  - c\n\n- d\n\n3. one\n4. two\n"},
+	{"3", "> [!NOTE]\n> Be careful.\n\n> **Warning:** hot\n\n> quote\n> > nested\n", ".. note::\n\n   Be careful.\n\n.. warning::\n\n   hot\n\n..\n\n   quote\n\n      nested\n"},
+	{"2", "*em* **strong** foo**bar**baz `a*b` ^sup^ ~sub~ snake_case ref_ 2*3 ![img](a.png)\n\n![block](b.png)\n", "*em* **strong** foo\\ **bar**\\ baz ``a*b`` :sup:`sup` :sub:`sub` snake_case ref\\_ 2\\*3 |image1|\n\n.. image:: b.png\n   :alt: block\n\n.. |image1| image:: a.png\n   :alt: img\n"},
+	{"1", "## Install {#install}\n\n### 中文\n\n---\n\nfoo\n", ".. _install:\n\nInstall\n=======\n\n中文\n----\n\n----------\n\nfoo\n"},
+	{"0", "# Title\n\nfoo\n", "=====\nTitle\n=====\n\nfoo\n"},
+}
+
+func TestRSTRenderer(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetSup(true)
+	luteEngine.SetSub(true)
+
+	for _, test := range rstRendererTests {
+		rst, warnings := luteEngine.Md2RST(test.from)
+		if test.to != rst {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, rst, test.from)
+		}
+		if 0 < len(warnings) {
+			t.Fatalf("test case [%s] failed, unexpected warnings %q", test.name, warnings)
+		}
+	}
+}
+
+func TestRSTRendererWarnings(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMark(true)
+
+	rst, warnings := luteEngine.Md2RST("<div>\nhtml\n</div>\n\n~~del~~ ==mark== <span>bar</span>\n")
+	expected := ".. role:: raw-html(raw)\n   :format: html\n\n.. raw:: html\n\n   <div>\n   html\n   </div>\n\ndel mark :raw-html:`<span>`\\ bar\\ :raw-html:`</span>`\n"
+	if expected != rst {
+		t.Fatalf("render unsupported nodes failed\nexpected\n\t%q\ngot\n\t%q", expected, rst)
+	}
+	expectedWarnings := []string{
+		"unsupported node [NodeHTMLBlock] is rendered as raw passthrough block",
+		"unsupported node [NodeStrikethrough] is rendered as plain text",
+		"unsupported node [NodeMark] is rendered as plain text",
+		"unsupported node [NodeInlineHTML] is rendered as raw passthrough",
+		"unsupported node [NodeInlineHTML] is rendered as raw passthrough",
+	}
+	if !reflect.DeepEqual(expectedWarnings, warnings) {
+		t.Fatalf("warnings mismatch\nexpected\n\t%q\ngot\n\t%q", expectedWarnings, warnings)
+	}
+}
@@ -0,0 +1,121 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var strictTests = []parseTest{
+
+	{"5", "<title>foo</title>\n", "&lt;title>foo&lt;/title>\n"},
+	{"4", "www.foo.bar\n", "<p><a href=\"http://www.foo.bar\">www.foo.bar</a></p>\n"},
+	{"3", "*~foo~*\n", "<p><em><del>foo</del></em></p>\n"},
+	{"2", "$$\nfoo\n$$\n", "<p>$$\nfoo\n$$</p>\n"},
+	{"1", "$foo$ :heart:\n", "<p>$foo$ :heart:</p>\n"},
+	{"0", "foo\nbar\n", "<p>foo\nbar</p>\n"},
+}
+
+func TestStrict(t *testing.T) {
+	luteEngine, _ := lute.NewWithPreset(lute.PresetGFM)
+	luteEngine.SetStrict(true)
+
+	for _, test := range strictTests {
+		html := luteEngine.MarkdownStr(test.name, test.from)
+		if test.to != html {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, html, test.from)
+		}
+	}
+}
+
+func TestStrictRestore(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetMark(true)
+	from := "==foo== :heart:\n"
+	expected := luteEngine.MarkdownStr("", from)
+
+	luteEngine.SetStrict(true)
+	luteEngine.SetStrict(true)
+	if html := luteEngine.MarkdownStr("", from); "<p>==foo== :heart:</p>\n" != html {
+		t.Fatalf("strict mode failed, got %q", html)
+	}
+
+	luteEngine.SetStrict(false)
+	if html := luteEngine.MarkdownStr("", from); expected != html {
+		t.Fatalf("restore options failed\nexpected\n\t%q\ngot\n\t%q", expected, html)
+	}
+
+	// 启用严格模式期间修改的选项在关闭严格模式后保持修改后的值
+	luteEngine.SetStrict(true)
+	luteEngine.SetEmoji(true)
+	luteEngine.SetGFMStrikethrough(false)
+	luteEngine.SetStrict(false)
+	from = "==foo== :heart: ~~bar~~\n"
+	if html := luteEngine.MarkdownStr("", from); "<p><mark>foo</mark> ❤️ ~~bar~~</p>\n" != html {
+		t.Fatalf("options set in strict mode should be kept, got %q", html)
+	}
+	luteEngine.SetEmoji(false)
+	luteEngine.SetStrict(true)
+	luteEngine.SetStrict(false)
+	if luteEngine.ParseOptions.Emoji {
+		t.Fatalf("option disabled before strict mode should stay disabled")
+	}
+}
+
+func TestCommonMarkSpecReport(t *testing.T) {
+	bytes, err := ioutil.ReadFile("commonmark-spec.json")
+	if nil != err {
+		t.Fatalf("read spec test cases failed: " + err.Error())
+	}
+	var examples []*lute.SpecExample
+	if err = json.Unmarshal(bytes, &examples); nil != err {
+		t.Fatalf("read spec test caes failed: " + err.Error())
+	}
+
+	luteEngine, _ := lute.NewWithPreset(lute.PresetCommonMark)
+	report := luteEngine.SpecReport(examples)
+	if 652 != report.Total || report.Total != report.Passed || 26 != len(report.Sections) {
+		t.Fatalf("spec report failed: %d/%d passed", report.Passed, report.Total)
+	}
+	if section := report.Sections[0]; "Tabs" != section.Section || 11 != section.Total || 11 != section.Passed {
+		t.Fatalf("unexpected section report %+v", section)
+	}
+}
+
+func TestGFMSpecReport(t *testing.T) {
+	bytes, err := ioutil.ReadFile("gfm-spec.md")
+	if nil != err {
+		t.Fatalf("read spec failed: " + err.Error())
+	}
+	examples := lute.ParseSpec(bytes)
+	if 673 != len(examples) {
+		t.Fatalf("parse spec failed, got %d examples", len(examples))
+	}
+
+	luteEngine, _ := lute.NewWithPreset(lute.PresetCommonMark)
+	report := luteEngine.SpecReport(examples)
+	// 两个任务列表示例在规范中标记为 disabled，不参与检查
+	if 671 != report.Total {
+		t.Fatalf("spec report total expected 671, got %d", report.Total)
+	}
+
+	if report.Total != report.Passed {
+		var failed []int
+		for _, section := range report.Sections {
+			failed = append(failed, section.Failed...)
+		}
+		t.Fatalf("spec report failed: %d/%d passed, failed examples %v", report.Passed, report.Total, failed)
+	}
+}
@@ -0,0 +1,61 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/88250/lute"
+)
+
+var textRendererTests = []parseTest{
+
+	{"8", "foo[^1]\n\n[^1]: note\n", "foo[^1]\n\n[^1]: note\n"},
+	{"7", "```go\nfmt.Println(1)\n```\n\n---\n", "    fmt.Println(1)\n\n--------------------\n"},
+	{"6", "|a|中文|c|\n|:-|:-:|-:|\n|1|2|三三|\n", "| a | 中文 |    c |\n|---|------|------|\n| 1 |  2   | 三三 |\n"},
+	{"5", "> foo\n>\n> bar\n", "> foo\n>\n> bar\n"},
+	{"4", "- [x] done\n- [ ] todo\n", "- [x] done\n- [ ] todo\n"},
+	{"3", "- a\n- b\n  - c\n\n3. one\n4. two\n", "- a\n- b\n  - c\n\n3. one\n4. two\n"},
+	{"2", "[foo](https://b3log.org) <https://ld246.com> ![bar](/a.png) [baz](https://b3log.org)\n", "foo[1] https://ld246.com bar[2] baz[1]\n\n[1] https://b3log.org\n[2] /a.png\n"},
+	{"1", "# foo\n\nbar\n---\n\n### baz\n", "foo\n===\n\nbar\n---\n\nbaz\n"},
+	{"0", "*foo* **bar** `baz` &amp; :heart:\nqux  \nquux\n", "foo bar baz & ❤️\nqux\nquux\n"},
+}
+
+func TestTextRenderer(t *testing.T) {
+	luteEngine := lute.New()
+
+	for _, test := range textRendererTests {
+		text := luteEngine.Md2Text(test.from)
+		if test.to != text {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, text, test.from)
+		}
+	}
+}
+
+var textRendererWrapTests = []parseTest{
+
+	{"2", "> quote line that is long enough to wrap\n", "> quote line that is long\n> enough to wrap\n"},
+	{"1", "- nested item text that is long enough to wrap\n", "- nested item text that is\n  long enough to wrap\n"},
+	{"0", "This is a paragraph\nwith a https://b3log.org/very/long/url and 中文段落测试自动换行是否按照东亚宽度计算。\n", "This is a paragraph with a\nhttps://b3log.org/very/long/url\nand 中文段落测试自动换行是否\n按照东亚宽度计算。\n"},
+}
+
+func TestTextRendererWrap(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetAutoSpace(false)
+	luteEngine.SetTextWrapWidth(28)
+
+	for _, test := range textRendererWrapTests {
+		text := luteEngine.Md2Text(test.from)
+		if test.to != text {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, text, test.from)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+func TestTransformer(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetToC(true)
+	var order []string
+	luteEngine.AddTransformer(
+		lute.HeadingIDTransformer(func(heading *ast.Node, id string) string {
+			order = append(order, "heading")
+			if "remove" == id {
+				return ""
+			}
+			return "doc-" + strings.ToLower(id)
+		}),
+		lute.LinkDestTransformer(func(node *ast.Node, dest string) string {
+			order = append(order, "link")
+			return strings.Replace(dest, "http://", "https://", 1)
+		}),
+		lute.TransformerFunc(func(tree *parse.Tree) {
+			order = append(order, "func")
+		}),
+	)
+
+	md := "# Foo\n\n## Bar {#remove}\n\n[link](http://b3log.org) ![img](http://b3log.org/logo.png)\n\n[ref]: http://ld246.com\n"
+	expected := "# Foo {#doc-foo}\n\n## Bar\n\n[link](https://b3log.org) ![img](https://b3log.org/logo.png)\n\n[ref]: https://ld246.com\n"
+	if formatted := luteEngine.FormatStr("", md); expected != formatted {
+		t.Fatalf("expected\n\t%q\ngot\n\t%q", expected, formatted)
+	}
+	if expected := "heading,heading,link,link,link,func"; expected != strings.Join(order, ",") {
+		t.Fatalf("transformers should run in order, expected [%s] got [%s]", expected, strings.Join(order, ","))
+	}
+
+	entries := map[string]func(){
+		"Markdown":       func() { luteEngine.MarkdownStr("", "# Baz\n") },
+		"Format":         func() { luteEngine.FormatStr("", "# Baz\n") },
+		"Md2BlockDOM":    func() { luteEngine.Md2BlockDOM("# Baz\n") },
+		"Md2VditorIRDOM": func() { luteEngine.Md2VditorIRDOM("# Baz\n") },
+	}
+	for name, entry := range entries {
+		order = nil
+		entry()
+		if expected := "heading,func"; expected != strings.Join(order, ",") {
+			t.Fatalf("transformers should run for [%s], expected [%s] got [%s]", name, expected, strings.Join(order, ","))
+		}
+	}
+	if ir := luteEngine.Md2VditorIRDOM("# Baz\n"); !strings.Contains(ir, "doc-baz") {
+		t.Fatalf("transformed heading ID should be rendered, got\n\t%q", ir)
+	}
+}
+
+func TestTransformerExportOnly(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetHeadingID(true)
+	luteEngine.AddTransformer(
+		lute.ExportOnly(lute.HeadingIDTransformer(func(heading *ast.Node, id string) string { return "doc-" + id })),
+		lute.ExportOnly(lute.LinkDestTransformer(func(node *ast.Node, dest string) string { return "/base" + dest })),
+	)
+
+	md := "# Foo {#foo}\n\n[link](/x)\n"
+	ir := luteEngine.Md2VditorIRDOM(md)
+	for i := 0; i < 3; i++ {
+		ir = luteEngine.SpinVditorIRDOM(ir)
+	}
+	if strings.Contains(ir, "doc-") || strings.Contains(ir, "/base") {
+		t.Fatalf("export only transformers should not run on spin, got\n\t%q", ir)
+	}
+	if markdown := luteEngine.VditorIRDOM2Md(ir); md != markdown {
+		t.Fatalf("spin failed\nexpected\n\t%q\ngot\n\t%q", md, markdown)
+	}
+
+	formatted := luteEngine.FormatStr("", luteEngine.FormatStr("", md))
+	if md != formatted {
+		t.Fatalf("format failed\nexpected\n\t%q\ngot\n\t%q", md, formatted)
+	}
+
+	expected := "<h1 id=\"doc-foo\">Foo</h1>\n<p><a href=\"/base/x\">link</a></p>\n"
+	if html := luteEngine.MarkdownStr("", formatted); expected != html {
+		t.Fatalf("export failed\nexpected\n\t%q\ngot\n\t%q", expected, html)
+	}
+}
+
+func TestToCTransformer(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetToC(true)
+	luteEngine.AddTransformer(lute.ToCTransformer{})
+
+	cases := []parseTest{
+		{"2", "foo\n", "foo\n"},
+		{"1", "[toc]\n\n# foo\n", "[toc]\n\n# foo\n"},
+		{"0", "---\ntitle: foo\n---\n\n# foo\n", "---\ntitle: foo\n---\n[toc]\n\n# foo\n"},
+	}
+	for _, test := range cases {
+		if formatted := luteEngine.FormatStr("", test.from); test.to != formatted {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", test.name, test.to, formatted, test.from)
+		}
+	}
+}
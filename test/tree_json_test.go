@@ -0,0 +1,117 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+var treeJSONTests = []string{
+	"```go {linenos}\nfunc main() {}\n```\n",
+	"| a | b | c |\n|:--|:-:|--:|\n| 1 | 2 | 3 |\n",
+	"3) foo\n4) bar\n\n- [x] done\n- [ ] todo\n",
+	"foo[^1] bar[^1]\n\n[^1]: footnote\n",
+	"# Heading {#custom-id}\n\n> **bold** _em_ `code` [link](/url \"title\") ![img](/img.png)\n",
+	"foo\n{: id=\"20060102150405-1a2b3c4\" custom-attr=\"bar\"}\n",
+}
+
+func TestTreeJSON(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+
+	for i, md := range treeJSONTests {
+		tree := parse.Parse("tree", []byte(md), luteEngine.ParseOptions)
+		tree.ID, tree.HPath, tree.Marks, tree.Created, tree.Updated = "tree-id", "/foo/bar", []string{"mark"}, 1, 2
+		data, err := json.Marshal(tree)
+		if nil != err {
+			t.Fatalf("test case [%d] marshal failed: %s", i, err)
+		}
+
+		restored := &parse.Tree{}
+		if err = json.Unmarshal(data, restored); nil != err {
+			t.Fatalf("test case [%d] unmarshal failed: %s", i, err)
+		}
+		if "tree" != restored.Name || "tree-id" != restored.ID || "/foo/bar" != restored.HPath || 1 != len(restored.Marks) || 1 != restored.Created || 2 != restored.Updated {
+			t.Fatalf("test case [%d] tree metadata mismatch", i)
+		}
+
+		again, err := json.Marshal(restored)
+		if nil != err {
+			t.Fatalf("test case [%d] marshal restored tree failed: %s", i, err)
+		}
+		if !bytes.Equal(data, again) {
+			t.Fatalf("test case [%d] failed\nexpected\n\t%s\ngot\n\t%s", i, data, again)
+		}
+
+		expected := luteEngine.Tree2HTML(tree, luteEngine.RenderOptions)
+		got := luteEngine.Tree2HTML(restored, luteEngine.RenderOptions)
+		if expected != got {
+			t.Fatalf("test case [%d] failed\nexpected\n\t%q\ngot\n\t%q\noriginal markdown text\n\t%q", i, expected, got, md)
+		}
+
+		ast.Walk(restored.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+			if !entering {
+				return ast.WalkContinue
+			}
+			for c := n.FirstChild; nil != c; c = c.Next {
+				if n != c.Parent || (nil != c.Next && c != c.Next.Previous) || (nil == c.Next && c != n.LastChild) {
+					t.Fatalf("test case [%d] node links of [%s] are broken", i, c.Type)
+				}
+			}
+			return ast.WalkContinue
+		})
+	}
+}
+
+func TestTreeJSONFootnotesRefs(t *testing.T) {
+	luteEngine := lute.New()
+	tree := parse.Parse("", []byte(treeJSONTests[3]), luteEngine.ParseOptions)
+	data, _ := json.Marshal(tree)
+	restored := &parse.Tree{}
+	if err := json.Unmarshal(data, restored); nil != err {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+
+	def := restored.Root.ChildByType(ast.NodeFootnotesDefBlock).FirstChild
+	if 2 != len(def.FootnotesRefs) {
+		t.Fatalf("expected 2 footnotes refs, got [%d]", len(def.FootnotesRefs))
+	}
+	for _, ref := range def.FootnotesRefs {
+		if ast.NodeFootnotesRef != ref.Type || restored.Root != ref.Parent.Parent {
+			t.Fatalf("footnotes ref is not linked to the restored tree")
+		}
+	}
+}
+
+func TestTreeJSONSchema(t *testing.T) {
+	tree := &parse.Tree{}
+	if err := json.Unmarshal([]byte(`{"schema":2,"root":{"type":"NodeDocument"}}`), tree); nil == err {
+		t.Fatalf("newer schema should be rejected")
+	}
+	if err := json.Unmarshal([]byte(`{"root":{"type":"NodeDocument"}}`), tree); nil == err {
+		t.Fatalf("missing schema should be rejected")
+	}
+	if err := json.Unmarshal([]byte(`{"schema":1,"root":{"type":"NodeFoo"}}`), tree); nil == err {
+		t.Fatalf("unknown node type should be rejected")
+	}
+	if err := json.Unmarshal([]byte(`{"schema":1,"root":{"type":"NodeDocument","children":[{"type":"NodeParagraph","children":[{"type":"NodeText","data":"foo"}]}]}}`), tree); nil != err {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if "foo" != tree.Root.Text() || nil == tree.Context || nil == tree.Context.ParseOption {
+		t.Fatalf("unexpected tree")
+	}
+}
@@ -0,0 +1,103 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/88250/lute"
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+type validateTest struct {
+	name   string
+	from   string
+	modify func(root *ast.Node)
+	to     string
+}
+
+var validateTests = []validateTest{
+
+	{"5", "|a|b|\n|-|-|\n|1|2|\n", func(root *ast.Node) { root.FirstChild.LastChild.LastChild.Unlink() }, "invalid tree: NodeTable: table row has 1 cells, expected 2"},
+	{"4", "foo\n{: id=\"20210101000000-aaaaaaa\"}\n\nbar\n{: id=\"20210101000000-bbbbbbb\"}\n", func(root *ast.Node) { root.FirstChild.Next.Next.ID = "20210101000000-aaaaaaa" }, "invalid tree: NodeParagraph [20210101000000-aaaaaaa]: duplicated id with NodeParagraph; NodeParagraph [20210101000000-aaaaaaa]: id is inconsistent with IAL id [20210101000000-bbbbbbb]"},
+	{"3", "*foo*\n", func(root *ast.Node) { root.FirstChild.FirstChild.LastChild.Unlink() }, "invalid tree: NodeEmA6kOpenMarker: open marker is unpaired"},
+	{"2", "- {: id=\"20210101000000-ccccccc\"}foo\n", func(root *ast.Node) { root.FirstChild.InsertBefore(root.FirstChild.FirstChild) }, "invalid tree: NodeListItem [20210101000000-ccccccc]: block is under NodeDocument"},
+	{"1", "foo\n", func(root *ast.Node) { root.AppendChild(&ast.Node{Type: ast.NodeText, Tokens: []byte("bar")}) }, "invalid tree: NodeText: inline is under NodeDocument"},
+	{"0", "# foo\n\n> - [x] *bar* `baz`\n\n|a|b|\n|-|-|\n|1|2|\n\n```go\nqux\n```\n", nil, ""},
+}
+
+func TestValidate(t *testing.T) {
+	luteEngine := lute.New()
+	luteEngine.SetKramdownIAL(true)
+
+	for _, test := range validateTests {
+		tree := parse.Parse("", []byte(test.from), luteEngine.ParseOptions)
+		if nil != test.modify {
+			test.modify(tree.Root)
+		}
+		violations := ast.Validate(tree.Root)
+		if "" == test.to {
+			if nil != violations {
+				t.Fatalf("test case [%s] failed\nexpected no violations\ngot\n\t%q", test.name, violations.Error())
+			}
+			continue
+		}
+		if nil == violations || test.to != violations.Error() {
+			t.Fatalf("test case [%s] failed\nexpected\n\t%q\ngot\n\t%v", test.name, test.to, violations)
+		}
+	}
+
+	if violations := ast.Validate(&ast.Node{Type: ast.NodeEmA6kOpenMarker}); nil != violations {
+		t.Fatalf("detached marker expected no violations, got %q", violations.Error())
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	bytes, err := ioutil.ReadFile("commonmark-spec.json")
+	if nil != err {
+		t.Fatalf("read spec test cases failed: " + err.Error())
+	}
+	var testcases []testcase
+	if err = json.Unmarshal(bytes, &testcases); nil != err {
+		t.Fatalf("read spec test caes failed: " + err.Error())
+	}
+
+	luteEngine := lute.New()
+	for _, test := range testcases {
+		tree := parse.Parse("", []byte(test.Markdown), luteEngine.ParseOptions)
+		if violations := ast.Validate(tree.Root); nil != violations {
+			t.Fatalf("test case [%s] failed\nexpected no violations\ngot\n\t%q\noriginal markdown text\n\t%q", test.Section+" "+strconv.Itoa(test.Example), violations.Error(), test.Markdown)
+		}
+	}
+}
+
+func TestValidateTree(t *testing.T) {
+	luteEngine := lute.New()
+	if _, err := luteEngine.VditorDOM2MdE(context.Background(), "foo"); nil != err {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	luteEngine.SetValidateTree(true)
+	if _, err := luteEngine.VditorDOM2MdE(context.Background(), "<p>foo</p>"); nil != err {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	_, err := luteEngine.VditorDOM2MdE(context.Background(), "foo")
+	if !errors.Is(err, lute.ErrInvalidDOM) || !strings.Contains(err.Error(), "NodeText: inline is under NodeDocument") {
+		t.Fatalf("expected invalid DOM error, got [%v]", err)
+	}
+}
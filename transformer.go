@@ -0,0 +1,144 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"strings"
+
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+// Transformer 描述了语法树转换器，用于在解析 Markdown 之后、渲染之前对语法树进行转换。
+type Transformer interface {
+	// Transform 转换语法树 tree。
+	Transform(tree *parse.Tree)
+}
+
+// TransformerFunc 用于将普通函数作为语法树转换器使用。
+type TransformerFunc func(tree *parse.Tree)
+
+// Transform 调用 f(tree)。
+func (f TransformerFunc) Transform(tree *parse.Tree) {
+	f(tree)
+}
+
+// AddTransformer 添加语法树转换器，转换器按照添加顺序依次执行，所有解析 Markdown 的入口（Markdown、Format、Md2BlockDOM、Md2VditorIRDOM 等）都会执行。
+//
+// 如果转换结果不应该写回 Markdown（比如为链接地址添加前缀），可以使用 ExportOnly 包装转换器。
+func (lute *Lute) AddTransformer(transformers ...Transformer) {
+	lute.Transformers = append(lute.Transformers, transformers...)
+}
+
+// ExportOnly 包装转换器 t，使其只在导出时执行。
+//
+// Format、TextBundle、MergeMarkdown 以及编辑器相关的入口（Spin*、HTML2*DOM、Md2*DOM）的结果会被写回 Markdown，
+// 这些入口会跳过 ExportOnly 转换器，以免编辑器反复 Spin 时转换被重复应用。
+func ExportOnly(t Transformer) Transformer {
+	return exportOnlyTransformer{t}
+}
+
+type exportOnlyTransformer struct {
+	Transformer
+}
+
+// parse 解析 markdown 并执行语法树转换器，用于导出。
+func (lute *Lute) parse(name string, markdown []byte) (tree *parse.Tree) {
+	tree = parse.Parse(name, markdown, lute.ParseOptions)
+	lute.transform(tree, false)
+	return
+}
+
+// parseEditing 解析 markdown 并执行语法树转换器，用于结果会写回 Markdown 的入口，跳过 ExportOnly 转换器。
+func (lute *Lute) parseEditing(name string, markdown []byte) (tree *parse.Tree) {
+	tree = parse.Parse(name, markdown, lute.ParseOptions)
+	lute.transform(tree, true)
+	return
+}
+
+func (lute *Lute) transform(tree *parse.Tree, editing bool) {
+	for _, transformer := range lute.Transformers {
+		if _, exportOnly := transformer.(exportOnlyTransformer); exportOnly && editing {
+			continue
+		}
+		transformer.Transform(tree)
+	}
+}
+
+// HeadingIDTransformer 用于改写标题 ID。参数 id 为标题的自定义 ID，没有自定义 ID 时为标题文本，返回值为新的自定义 ID，返回空字符串时移除自定义 ID。
+//
+// 比如为所有标题 ID 添加前缀：
+//
+//	lute.HeadingIDTransformer(func(heading *ast.Node, id string) string { return "doc-" + id })
+type HeadingIDTransformer func(heading *ast.Node, id string) string
+
+// Transform 改写 tree 中所有标题的 ID。
+func (f HeadingIDTransformer) Transform(tree *parse.Tree) {
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || ast.NodeHeading != n.Type {
+			return ast.WalkContinue
+		}
+
+		headingID := n.ChildByType(ast.NodeHeadingID)
+		id := n.Text()
+		if nil != headingID {
+			id = strings.TrimPrefix(headingID.TokensStr(), "#")
+		}
+		id = f(n, id)
+		if "" == id {
+			if nil != headingID {
+				headingID.Unlink()
+			}
+		} else {
+			if nil == headingID {
+				headingID = &ast.Node{Type: ast.NodeHeadingID}
+				n.AppendChild(headingID)
+			}
+			headingID.Tokens = []byte("#" + id)
+		}
+		n.HeadingNormalizedID = ""
+		return ast.WalkSkipChildren
+	})
+}
+
+// LinkDestTransformer 用于改写链接和图片地址，参数 node 为链接或者图片节点，dest 为原地址，返回值为新地址。
+type LinkDestTransformer func(node *ast.Node, dest string) string
+
+// Transform 改写 tree 中所有链接和图片（包括链接引用定义）的地址。
+func (f LinkDestTransformer) Transform(tree *parse.Tree) {
+	ast.Walk(tree.Root, func(n *ast.Node, entering bool) ast.WalkStatus {
+		if !entering || (ast.NodeLink != n.Type && ast.NodeImage != n.Type) {
+			return ast.WalkContinue
+		}
+
+		if dest := n.ChildByType(ast.NodeLinkDest); nil != dest {
+			dest.Tokens = []byte(f(n, dest.TokensStr()))
+		}
+		return ast.WalkContinue
+	})
+}
+
+// ToCTransformer 用于在文档开头（YAML Front Matter 之后）自动插入目录，文档中已经有目录或者没有标题时不插入。
+type ToCTransformer struct{}
+
+// Transform 在 tree 开头插入目录。
+func (t ToCTransformer) Transform(tree *parse.Tree) {
+	if 0 < len(tree.Root.ChildrenByType(ast.NodeToC)) || 1 > len(tree.Root.ChildrenByType(ast.NodeHeading)) {
+		return
+	}
+
+	toc := &ast.Node{Type: ast.NodeToC}
+	if first := tree.Root.FirstChild; nil != first && ast.NodeYamlFrontMatter == first.Type {
+		first.InsertAfter(toc)
+		return
+	}
+	tree.Root.PrependChild(toc)
+}
@@ -0,0 +1,41 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package util
+
+import "unicode"
+
+// RuneWidth 返回字符 r 在等宽字体终端中的显示宽度（列数）：东亚宽字符和全角字符为 2，组合字符、控制字符和零宽字符为 0，其他字符为 1。
+func RuneWidth(r rune) int {
+	switch {
+	case 0x20 > r || (0x7F <= r && 0xA0 > r), 0x200B <= r && 0x200F >= r, 0xFEFF == r, 0xFE00 <= r && 0xFE0F >= r:
+		return 0
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r):
+		return 0
+	case 0x1100 <= r && 0x115F >= r, // 谚文字母
+		0x2E80 <= r && 0x303E >= r, 0x3041 <= r && 0xA4CF >= r, // 中日韩部首、标点、假名和汉字
+		0xAC00 <= r && 0xD7A3 >= r,                             // 谚文音节
+		0xF900 <= r && 0xFAFF >= r,                             // 中日韩兼容汉字
+		0xFE30 <= r && 0xFE4F >= r,                             // 中日韩兼容形式
+		0xFF00 <= r && 0xFF60 >= r, 0xFFE0 <= r && 0xFFE6 >= r, // 全角字符
+		0x1F300 <= r && 0x1F64F >= r, 0x1F900 <= r && 0x1F9FF >= r, // Emoji
+		0x20000 <= r && 0x3FFFD >= r: // 中日韩扩展汉字
+		return 2
+	}
+	return 1
+}
+
+// StrWidth 返回字符串 str 在等宽字体终端中的显示宽度（列数）。
+func StrWidth(str string) (ret int) {
+	for _, r := range str {
+		ret += RuneWidth(r)
+	}
+	return
+}
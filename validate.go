@@ -0,0 +1,26 @@
+// Lute - 一款结构化的 Markdown 引擎，支持 Go 和 JavaScript
+// Copyright (c) 2019-present, b3log.org
+//
+// Lute is licensed under Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//         http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR PURPOSE.
+// See the Mulan PSL v2 for more details.
+
+package lute
+
+import (
+	"github.com/88250/lute/ast"
+	"github.com/88250/lute/parse"
+)
+
+// validateTree 在打开 ValidateTree 调试选项时校验 DOM 转换生成的语法树 tree，校验不通过时 panic（...E 方法中返回 ErrInvalidDOM）。
+func (lute *Lute) validateTree(tree *parse.Tree) {
+	if !lute.ValidateTree || nil == tree {
+		return
+	}
+	if violations := ast.Validate(tree.Root); nil != violations {
+		panic(violations)
+	}
+}
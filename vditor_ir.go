@@ -28,7 +28,7 @@ func (lute *Lute) SpinVditorIRDOM(ivHTML string) (ovHTML string) {
 	// 替换插入符
 	ivHTML = strings.ReplaceAll(ivHTML, "<wbr>", util.Caret)
 	markdown := lute.vditorIRDOM2Md(ivHTML)
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	renderer := render.NewVditorIRRenderer(tree, lute.RenderOptions)
 	output := renderer.Render()
 	// 替换插入符
@@ -44,7 +44,7 @@ func (lute *Lute) HTML2VditorIRDOM(sHTML string) (vHTML string) {
 		return
 	}
 
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	renderer := render.NewVditorIRRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.HTML2VditorIRDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -63,7 +63,7 @@ func (lute *Lute) VditorIRDOM2HTML(vhtml string) (sHTML string) {
 
 // Md2VditorIRDOM 将 markdown 转换为 Vditor Instant-Rendering DOM，用于从源码模式切换至即时渲染模式。
 func (lute *Lute) Md2VditorIRDOM(markdown string) (vHTML string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	renderer := render.NewVditorIRRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2VditorIRDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -128,6 +128,8 @@ func (lute *Lute) vditorIRDOM2Md(htmlStr string) (markdown string) {
 		return ast.WalkContinue
 	})
 
+	lute.validateTree(tree)
+
 	// 将 AST 进行 Markdown 格式化渲染
 	options := render.NewOptions()
 	options.AutoSpace = false
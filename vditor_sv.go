@@ -13,7 +13,6 @@ package lute
 import (
 	"strings"
 
-	"github.com/88250/lute/parse"
 	"github.com/88250/lute/render"
 	"github.com/88250/lute/util"
 )
@@ -25,7 +24,7 @@ func (lute *Lute) SpinVditorSVDOM(markdown string) (ovHTML string) {
 		return "<span data-type=\"text\"><wbr></span>" + string(render.NewlineSV)
 	}
 
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 
 	renderer := render.NewVditorSVRenderer(tree, lute.RenderOptions)
 	output := renderer.Render()
@@ -42,7 +41,7 @@ func (lute *Lute) HTML2VditorSVDOM(sHTML string) (vHTML string) {
 		return
 	}
 
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	renderer := render.NewVditorSVRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.HTML2VditorSVDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -54,7 +53,7 @@ func (lute *Lute) HTML2VditorSVDOM(sHTML string) (vHTML string) {
 
 // Md2VditorSVDOM 将 markdown 转换为 Vditor Split-View DOM，用于从源码模式切换至分屏预览模式。
 func (lute *Lute) Md2VditorSVDOM(markdown string) (vHTML string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	renderer := render.NewVditorSVRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2VditorSVDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -33,7 +33,7 @@ func (lute *Lute) Md2HTML(markdown string) (sHTML string) {
 func (lute *Lute) SpinVditorDOM(ivHTML string) (ovHTML string) {
 	ivHTML = strings.ReplaceAll(ivHTML, util.FrontEndCaret, util.Caret)
 	markdown := lute.vditorDOM2Md(ivHTML)
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	renderer := render.NewVditorRenderer(tree, lute.RenderOptions)
 	output := renderer.Render()
 	ovHTML = strings.ReplaceAll(string(output), util.Caret, util.FrontEndCaret)
@@ -48,7 +48,7 @@ func (lute *Lute) HTML2VditorDOM(sHTML string) (vHTML string) {
 		return
 	}
 
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	renderer := render.NewVditorRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.HTML2VditorDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -67,7 +67,7 @@ func (lute *Lute) VditorDOM2HTML(vhtml string) (sHTML string) {
 
 // Md2VditorDOM 将 markdown 转换为 Vditor DOM，用于从源码模式切换至所见即所得模式。
 func (lute *Lute) Md2VditorDOM(markdown string) (vHTML string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parseEditing("", []byte(markdown))
 	renderer := render.NewVditorRenderer(tree, lute.RenderOptions)
 	for nodeType, rendererFunc := range lute.Md2VditorDOMRendererFuncs {
 		renderer.ExtRendererFuncs[nodeType] = rendererFunc
@@ -87,7 +87,7 @@ func (lute *Lute) VditorDOM2Md(htmlStr string) (markdown string) {
 
 // RenderEChartsJSON 用于渲染 ECharts JSON 格式数据。
 func (lute *Lute) RenderEChartsJSON(markdown string) (json string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parse("", []byte(markdown))
 	renderer := render.NewEChartsJSONRenderer(tree, lute.RenderOptions)
 	output := renderer.Render()
 	json = string(output)
@@ -96,7 +96,7 @@ func (lute *Lute) RenderEChartsJSON(markdown string) (json string) {
 
 // RenderKityMinderJSON 用于渲染 KityMinder JSON 格式数据。
 func (lute *Lute) RenderKityMinderJSON(markdown string) (json string) {
-	tree := parse.Parse("", []byte(markdown), lute.ParseOptions)
+	tree := lute.parse("", []byte(markdown))
 	renderer := render.NewKityMinderJSONRenderer(tree, lute.RenderOptions)
 	output := renderer.Render()
 	json = string(output)
@@ -160,6 +160,8 @@ func (lute *Lute) vditorDOM2Md(htmlStr string) (markdown string) {
 		return ast.WalkContinue
 	})
 
+	lute.validateTree(tree)
+
 	// 将 AST 进行 Markdown 格式化渲染
 	options := render.NewOptions()
 	options.AutoSpace = false